@@ -1,15 +1,43 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
 	"github.com/gorilla/websocket"
 	"github.com/lmittmann/tint"
 )
 
+// go run ./client-ws
+// go run ./client-ws -mode sse -election 1 -candidate 1
+
+var (
+	mode      = flag.String("mode", "ws", "stream transport: ws or sse")
+	addr      = flag.String("addr", "localhost:8080", "server address")
+	election  = flag.Uint("election", 1, "election to watch")
+	candidate = flag.Uint("candidate", 0, "sse only: watch a single candidate")
+	token     = flag.String("token", os.Getenv("ELECTIONS_TOKEN"), "JWT with the observer role, ELECTIONS_TOKEN by default")
+)
+
+// authHeader returns the Authorization header for the token, nil if none.
+func authHeader() http.Header {
+	h := http.Header{}
+	if *token != "" {
+		h.Set("Authorization", "Bearer "+*token)
+	}
+	return h
+}
+
 func readLoop(c *websocket.Conn) {
 	for {
 		_, buff, err := c.ReadMessage()
@@ -21,21 +49,118 @@ func readLoop(c *websocket.Conn) {
 	}
 }
 
-func main() {
-	slog.SetDefault(slog.New(tint.NewHandler(os.Stdout, nil)))
-
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGTERM)
-
-	url := "ws://localhost:8080/stat-stream"
+func runWS(ctx context.Context) {
+	url := fmt.Sprintf("ws://%s/stat-stream?election_id=%d", *addr, *election)
 	wsDialer := websocket.Dialer{}
-	conn, _, err := wsDialer.Dial(url, nil)
+	conn, _, err := wsDialer.Dial(url, authHeader())
 	if err != nil {
 		slog.Error("error dialing", "err", err)
 		return
 	}
 
 	go readLoop(conn)
-	<-ch
+	<-ctx.Done()
 	conn.Close()
 }
+
+// runSSE reconnects after errors and resumes from the last received event.
+func runSSE(ctx context.Context) {
+	url := fmt.Sprintf("http://%s/stat-events?election_id=%d", *addr, *election)
+	if *candidate != 0 {
+		url = fmt.Sprintf("%s&candidate_id=%d", url, *candidate)
+	}
+
+	lastEventId := ""
+	retry := 3 * time.Second
+	for {
+		var err error
+		lastEventId, retry, err = readEvents(ctx, url, lastEventId, retry)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("event stream interrupted, reconnecting", "err", err, "retry", retry)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+func readEvents(ctx context.Context, url, lastEventId string, retry time.Duration) (string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return lastEventId, retry, err
+	}
+	req.Header = authHeader()
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventId != "" {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return lastEventId, retry, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return lastEventId, retry, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var id, event string
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				lastEventId = id
+				slog.Info("got event", "id", id, "event", event, "content", strings.Join(data, "\n"))
+			}
+			id, event, data = lastEventId, "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment, the server uses them as heartbeats
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			var ms int
+			if _, err := fmt.Sscan(value, &ms); err == nil {
+				retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return lastEventId, retry, err
+	}
+	return lastEventId, retry, fmt.Errorf("stream closed by server")
+}
+
+func main() {
+	flag.Parse()
+	slog.SetDefault(slog.New(correlation.NewHandler(tint.NewHandler(os.Stdout, nil))))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	switch *mode {
+	case "ws":
+		runWS(ctx)
+	case "sse":
+		runSSE(ctx)
+	default:
+		slog.Error("unknown mode", "mode", *mode)
+	}
+}
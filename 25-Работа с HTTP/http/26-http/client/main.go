@@ -1,87 +1,84 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"net/url"
 	"os"
 	"time"
 
-	"github.com/OtusGolang/webinars_practical_part/26-http/handler"
-	"github.com/OtusGolang/webinars_practical_part/26-http/middleware"
+	"github.com/OtusGolang/webinars_practical_part/26-http/electionsclient"
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
 	"github.com/lmittmann/tint"
 )
 
+// go run ./client -addr http://localhost:8080 -election 1 -candidate 1
+
+var (
+	addr      = flag.String("addr", "http://0.0.0.0:8080", "server base url")
+	election  = flag.Uint("election", 1, "election to vote in")
+	candidate = flag.Uint("candidate", 1, "candidate to vote for")
+	passport  = flag.String("passport", "test", "voter passport")
+	token     = flag.String("token", os.Getenv("ELECTIONS_TOKEN"), "JWT with the voter role, ELECTIONS_TOKEN by default")
+)
+
 func main() {
-	slog.SetDefault(slog.New(tint.NewHandler(os.Stdout, nil)))
+	flag.Parse()
+	slog.SetDefault(slog.New(correlation.NewHandler(tint.NewHandler(os.Stdout, nil))))
 
-	var tr http.RoundTripper
-	tr = &http.Transport{
+	tr := &http.Transport{
 		MaxIdleConns:    100,
 		IdleConnTimeout: 90 * time.Second,
 	}
-	tr = middleware.NewLoggingRoundTripper(tr)
 
-	client := http.Client{
-		Transport: tr,
+	opts := []electionsclient.Option{
+		electionsclient.WithElection(uint32(*election)),
+		electionsclient.WithTimeout(5 * time.Second),
+		electionsclient.WithTransport(tr),
+		electionsclient.WithRoundTrippers(electionsclient.Logging()),
 	}
-
-	// post vote
-	voteReq := &handler.VoteRequest{
-		Passport:    "test",
-		CandidateId: 1,
+	if *token != "" {
+		opts = append(opts, electionsclient.WithToken(*token))
 	}
-
-	jsonBody, err := json.Marshal(voteReq)
+	client, err := electionsclient.New(*addr, opts...)
 	if err != nil {
-		slog.Error("error marshalling vote request", "err", err)
+		slog.Error("error creating client", "err", err)
 		return
 	}
 
-	reqVote, err := http.NewRequest(
-		http.MethodPost,
-		"http://0.0.0.0:8080/vote",
-		bytes.NewBuffer(jsonBody),
-	)
-	if err != nil {
-		slog.Error("error creating request", "err", err)
-		return
-	}
+	// both calls share one request id, see the X-Request-ID header
+	ctx := correlation.NewContext(context.Background(), correlation.New())
 
-	// respVote, err := http.DefaultClient.Do(reqVote)
-	respVote, err := client.Do(reqVote)
-	if err != nil {
-		slog.Error("error sending request", "err", err)
-		return
+	// post vote
+	err = client.SubmitVote(ctx, electionsclient.Vote{
+		Passport:    *passport,
+		CandidateId: uint32(*candidate),
+	})
+	switch {
+	case err == nil:
+		slog.Info("vote accepted")
+	case errors.Is(err, electionsclient.ErrAlreadyVoted):
+		slog.Warn("already voted", "err", err)
+	case errors.Is(err, electionsclient.ErrValidation):
+		p, _ := problem.As(err)
+		slog.Warn("vote rejected", "violations", p.Violations)
+	default:
+		slog.Error("vote failed", "err", err)
 	}
 
-	slog.Info("responce from vote", "resp", respVote)
-
-	// get stat for candidate with id  1
-	reqArgs := url.Values{}
-	reqArgs.Add("candidate_id", "1")
-
-	reqUrl, _ := url.Parse("http://0.0.0.0:8080/stat")
-	reqUrl.RawQuery = reqArgs.Encode()
-
-	// with context
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
-	reqStat, _ := http.NewRequestWithContext(ctx, http.MethodGet, reqUrl.String(), nil)
-	reqStat.Header.Add("User-Agent", `Mozilla/5.0 Gecko/20100101 Firefox/39.0`)
-
-	// respStat, err := http.DefaultClient.Do(req)
-	respStat, err := client.Do(reqStat)
+	// get stat for the candidate
+	stat, err := client.CandidateStat(ctx, uint32(*candidate))
 	if err != nil {
-		slog.Error("error sending request", "err", err)
+		slog.Error("unable to get stat", "err", err)
 		return
 	}
-
-	slog.Info("responce from stat", "resp", respStat)
+	slog.Info("responce from stat", "candidate_id", stat.CandidateId, "stat", stat.Stat)
 }
 
 type Stat struct {
@@ -90,12 +87,13 @@ type Stat struct {
 }
 
 func (s *Stat) String() string {
-	return ""
+	return fmt.Sprintf("candidate %d: %d", s.CandidateId, s.Statistic)
 }
 
+// PrepareStat decodes a candidate stat response made without the SDK.
 func PrepareStat(res *http.Response) (*Stat, error) {
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("state return no 200 code: %d", res.StatusCode)
+		return nil, problem.FromResponse(res)
 	}
 
 	body, err := io.ReadAll(res.Body)
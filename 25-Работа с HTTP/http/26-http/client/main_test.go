@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
@@ -12,14 +13,21 @@ func TestPrepareStat(t *testing.T) {
 		name      string
 		code      int
 		body      string
-		expError  bool
+		expError  error
 		expStruct *Stat
 	}{
-		{"bad_request", http.StatusBadRequest, "", true, nil},
+		{"bad_request", http.StatusBadRequest, "", problem.ErrInvalidRequest, nil},
+		{
+			"problem",
+			http.StatusNotFound,
+			`{"type":"urn:elections:problem:not_found","title":"Not Found","status":404,"code":"not_found","request_id":"7"}`,
+			problem.ErrNotFound,
+			nil,
+		},
 		{"ok",
 			http.StatusOK,
-			`{"data":{"candidate_id":1,"stat":1,"time":"2021-12-06T19:33:30.972789465+03:00"},"error":{"message":""}}`,
-			false,
+			`{"data":{"candidate_id":1,"stat":1,"time":"2021-12-06T19:33:30.972789465+03:00"}}`,
+			nil,
 			&Stat{
 				CandidateId: 1,
 				Statistic:   1,
@@ -29,13 +37,16 @@ func TestPrepareStat(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			resRec := httptest.NewRecorder()
+			if c.code != http.StatusOK {
+				resRec.Header().Set("Content-Type", problem.ContentType)
+			}
 			_, err := resRec.WriteString(c.body)
 			require.NoError(t, err)
 			resRec.Code = c.code
 
 			res, err := PrepareStat(resRec.Result())
-			if c.expError {
-				require.Error(t, err)
+			if c.expError != nil {
+				require.ErrorIs(t, err, c.expError)
 			} else {
 				require.NoError(t, err)
 				require.Equal(t, c.expStruct.CandidateId, res.CandidateId)
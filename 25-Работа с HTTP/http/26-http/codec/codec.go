@@ -0,0 +1,178 @@
+// Package codec picks how HTTP bodies are encoded: requests are read with
+// the codec of their Content-Type, responses are written with the codec
+// the Accept header prefers. JSON, XML and protobuf come with the package,
+// more codecs can be added to a Registry.
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedMediaType means no codec reads the Content-Type, or
+	// the codec can't read the value, servers answer 415.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrNotAcceptable means no codec writes a type allowed by Accept, or
+	// the ones that do can't write the value, servers answer 406.
+	ErrNotAcceptable = errors.New("not acceptable")
+)
+
+// Codec encodes and decodes values of one media type.
+type Codec interface {
+	// ContentType is the Content-Type of encoded values, like
+	// "application/json; charset=utf-8".
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// Supporter is implemented by codecs handling only some types.
+type Supporter interface {
+	CanMarshal(v any) bool
+	CanUnmarshal(v any) bool
+}
+
+// Registry holds codecs in the order of preference, the first one is used
+// when a request doesn't say anything. It must be set up before use.
+type Registry struct {
+	codecs []Codec
+}
+
+func NewRegistry(codecs ...Codec) *Registry {
+	r := &Registry{}
+	for _, c := range codecs {
+		r.Register(c)
+	}
+	return r
+}
+
+// Default returns a registry with JSON, XML and Protobuf, JSON first.
+func Default() *Registry {
+	return NewRegistry(JSON, XML, Protobuf)
+}
+
+// Register adds the codec, replacing the one of the same media type.
+func (r *Registry) Register(c Codec) {
+	for i, old := range r.codecs {
+		if mediaType(old) == mediaType(c) {
+			r.codecs[i] = c
+			return
+		}
+	}
+	r.codecs = append(r.codecs, c)
+}
+
+// ForContentType returns the codec to read v from a body with the
+// Content-Type header, the first codec if the header is empty.
+func (r *Registry) ForContentType(header string, v any) (Codec, error) {
+	if header == "" && len(r.codecs) > 0 {
+		return readable(r.codecs[0], v)
+	}
+	t, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedMediaType, err)
+	}
+	for _, c := range r.codecs {
+		if mediaType(c) == t {
+			return readable(c, v)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, t)
+}
+
+func readable(c Codec, v any) (Codec, error) {
+	if s, ok := c.(Supporter); ok && v != nil && !s.CanUnmarshal(v) {
+		return nil, fmt.Errorf("%w: %s for %T", ErrUnsupportedMediaType, mediaType(c), v)
+	}
+	return c, nil
+}
+
+// ForAccept returns the codec to write v for the Accept header. Media
+// ranges with a higher q win, then the more specific ones and then the
+// registry order. An empty header accepts anything, a nil v skips the
+// Supporter check.
+func (r *Registry) ForAccept(header string, v any) (Codec, error) {
+	if strings.TrimSpace(header) == "" {
+		header = "*/*"
+	}
+	type candidate struct {
+		codec       Codec
+		q           float64
+		specificity int
+		order       int
+	}
+	var candidates []candidate
+	for order, c := range r.codecs {
+		if s, ok := c.(Supporter); ok && v != nil && !s.CanMarshal(v) {
+			continue
+		}
+		q, specificity := quality(header, mediaType(c))
+		if q > 0 {
+			candidates = append(candidates, candidate{c, q, specificity, order})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotAcceptable, header)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.q != b.q {
+			return a.q > b.q
+		}
+		if a.specificity != b.specificity {
+			return a.specificity > b.specificity
+		}
+		return a.order < b.order
+	})
+	return candidates[0].codec, nil
+}
+
+// quality returns the q of the most specific media range of the Accept
+// header matching the media type, 0 if none does.
+func quality(header, t string) (float64, int) {
+	bestQ, best := 0.0, -1
+	for _, part := range strings.Split(header, ",") {
+		mr, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		specificity := matches(mr, t)
+		if specificity <= best {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		bestQ, best = q, specificity
+	}
+	return bestQ, best
+}
+
+// matches returns -1 if the media range doesn't match t, otherwise 0 for
+// "*/*", 1 for "type/*" and 2 for an exact match.
+func matches(mediaRange, t string) int {
+	if mediaRange == t {
+		return 2
+	}
+	if mediaRange == "*/*" {
+		return 0
+	}
+	mainType, sub, _ := strings.Cut(mediaRange, "/")
+	if sub == "*" && strings.HasPrefix(t, mainType+"/") {
+		return 1
+	}
+	return -1
+}
+
+func mediaType(c Codec) string {
+	t, _, _ := mime.ParseMediaType(c.ContentType())
+	return t
+}
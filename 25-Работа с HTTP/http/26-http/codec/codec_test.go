@@ -0,0 +1,120 @@
+package codec_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/codec"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type note struct {
+	Text string `json:"text" xml:"text"`
+}
+
+func (n *note) MarshalProto() proto.Message {
+	if n.Text == "" {
+		return nil
+	}
+	return wrapperspb.String(n.Text)
+}
+
+func (n *note) NewProto() proto.Message { return &wrapperspb.StringValue{} }
+
+func (n *note) UnmarshalProto(m proto.Message) error {
+	n.Text = m.(*wrapperspb.StringValue).GetValue()
+	return nil
+}
+
+func TestRegistry_ForAccept(t *testing.T) {
+	r := codec.Default()
+	cases := []struct {
+		name   string
+		accept string
+		v      any
+		want   codec.Codec
+	}{
+		{"empty", "", &note{Text: "a"}, codec.JSON},
+		{"any", "*/*", &note{Text: "a"}, codec.JSON},
+		{"exact", "application/xml", &note{Text: "a"}, codec.XML},
+		{"params", "application/x-protobuf; proto=note", &note{Text: "a"}, codec.Protobuf},
+		{"quality", "application/json;q=0.5, application/xml", &note{Text: "a"}, codec.XML},
+		{"specific_wins", "application/*;q=0.2, application/xml;q=0.9, */*;q=0.1", &note{Text: "a"}, codec.XML},
+		{"wildcard_order", "text/html, application/*", &note{Text: "a"}, codec.JSON},
+		{"unsupported_skipped", "application/x-protobuf, application/xml;q=0.5", &note{}, codec.XML},
+		{"nil_value", "application/x-protobuf", nil, codec.Protobuf},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := r.ForAccept(c.accept, c.v)
+			require.NoError(t, err)
+			require.Equal(t, c.want, got)
+		})
+	}
+
+	for _, accept := range []string{"text/html", "application/json;q=0", "application/x-protobuf"} {
+		_, err := r.ForAccept(accept, &note{})
+		require.True(t, errors.Is(err, codec.ErrNotAcceptable), accept)
+	}
+}
+
+func TestRegistry_ForContentType(t *testing.T) {
+	r := codec.Default()
+	c, err := r.ForContentType("", &note{})
+	require.NoError(t, err)
+	require.Equal(t, codec.JSON, c)
+
+	c, err = r.ForContentType("application/xml; charset=utf-8", &note{})
+	require.NoError(t, err)
+	require.Equal(t, codec.XML, c)
+
+	for _, contentType := range []string{"text/plain", "application/x-www-form-urlencoded", "not a type;"} {
+		_, err = r.ForContentType(contentType, &note{})
+		require.True(t, errors.Is(err, codec.ErrUnsupportedMediaType), contentType)
+	}
+	_, err = r.ForContentType("application/x-protobuf", &struct{}{})
+	require.True(t, errors.Is(err, codec.ErrUnsupportedMediaType))
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := codec.NewRegistry(codec.XML)
+	r.Register(codec.JSON)
+	c, err := r.ForAccept("", nil)
+	require.NoError(t, err)
+	require.Equal(t, codec.XML, c, "registry order decides for */*")
+
+	yaml := fakeCodec{"application/yaml"}
+	r.Register(yaml)
+	c, err = r.ForContentType("application/yaml", nil)
+	require.NoError(t, err)
+	require.Equal(t, yaml, c)
+
+	replaced := fakeCodec{"application/xml; charset=latin1"}
+	r.Register(replaced)
+	c, err = r.ForAccept("application/xml", nil)
+	require.NoError(t, err)
+	require.Equal(t, replaced, c)
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	for _, c := range []codec.Codec{codec.JSON, codec.XML, codec.Protobuf, codec.NDJSON} {
+		t.Run(c.ContentType(), func(t *testing.T) {
+			data, err := c.Marshal(&note{Text: "hello"})
+			require.NoError(t, err)
+			got := &note{}
+			require.NoError(t, c.Unmarshal(data, got))
+			require.Equal(t, "hello", got.Text)
+		})
+	}
+
+	_, err := codec.Protobuf.Marshal(&note{})
+	require.True(t, errors.Is(err, codec.ErrNotAcceptable))
+}
+
+type fakeCodec struct{ contentType string }
+
+func (c fakeCodec) ContentType() string                { return c.contentType }
+func (c fakeCodec) Marshal(v any) ([]byte, error)      { return nil, nil }
+func (c fakeCodec) Unmarshal(data []byte, v any) error { return nil }
@@ -0,0 +1,104 @@
+package codec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	JSON     Codec = jsonCodec{}
+	XML      Codec = xmlCodec{}
+	Protobuf Codec = protobufCodec{}
+	// NDJSON handles one line of a newline delimited JSON stream, it is
+	// not in Default as it fits streams only.
+	NDJSON Codec = ndjsonCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                { return "application/json; charset=utf-8" }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type ndjsonCodec struct{}
+
+func (ndjsonCodec) ContentType() string { return "application/x-ndjson" }
+
+func (ndjsonCodec) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return append(data, '\n'), err
+}
+
+func (ndjsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string                { return "application/xml; charset=utf-8" }
+func (xmlCodec) Marshal(v any) ([]byte, error)      { return xml.Marshal(v) }
+func (xmlCodec) Unmarshal(data []byte, v any) error { return xml.Unmarshal(data, v) }
+
+// ProtoMarshaler is implemented by types sent as a protobuf message, nil
+// means the value has no protobuf form.
+type ProtoMarshaler interface {
+	MarshalProto() proto.Message
+}
+
+// ProtoUnmarshaler is implemented by types read from a protobuf message,
+// the body is decoded into NewProto and then passed to UnmarshalProto.
+type ProtoUnmarshaler interface {
+	NewProto() proto.Message
+	UnmarshalProto(m proto.Message) error
+}
+
+// protobufCodec handles proto.Message values and the types implementing
+// ProtoMarshaler or ProtoUnmarshaler.
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return "application/x-protobuf" }
+
+func (protobufCodec) CanMarshal(v any) bool {
+	switch v := v.(type) {
+	case proto.Message:
+		return true
+	case ProtoMarshaler:
+		return v.MarshalProto() != nil
+	}
+	return false
+}
+
+func (protobufCodec) CanUnmarshal(v any) bool {
+	switch v.(type) {
+	case proto.Message, ProtoUnmarshaler:
+		return true
+	}
+	return false
+}
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case proto.Message:
+		return proto.Marshal(v)
+	case ProtoMarshaler:
+		if m := v.MarshalProto(); m != nil {
+			return proto.Marshal(m)
+		}
+	}
+	return nil, fmt.Errorf("%w: %T has no protobuf form", ErrNotAcceptable, v)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	switch v := v.(type) {
+	case proto.Message:
+		return proto.Unmarshal(data, v)
+	case ProtoUnmarshaler:
+		m := v.NewProto()
+		if err := proto.Unmarshal(data, m); err != nil {
+			return err
+		}
+		return v.UnmarshalProto(m)
+	}
+	return fmt.Errorf("%w: %T has no protobuf form", ErrUnsupportedMediaType, v)
+}
@@ -0,0 +1,300 @@
+// Package electionsclient is a Go client of the elections HTTP API.
+//
+//	c, err := electionsclient.New("http://localhost:8080",
+//		electionsclient.WithElection(1),
+//		electionsclient.WithToken(token),
+//		electionsclient.WithRoundTrippers(
+//			electionsclient.Retry(middleware.RetryPolicy{OnEvent: middleware.LogEvents(nil)}),
+//			electionsclient.CircuitBreaker(middleware.BreakerPolicy{OnEvent: middleware.LogEvents(nil)}),
+//			electionsclient.Logging(),
+//		),
+//	)
+//	err = c.SubmitVote(ctx, electionsclient.Vote{Passport: "a", CandidateId: 1})
+//	if errors.Is(err, electionsclient.ErrAlreadyVoted) { ... }
+//
+// Failed calls return a *problem.Problem, check it with errors.Is against
+// the Err* values or take the details with problem.As.
+package electionsclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/middleware"
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Errors returned by the API, compare with errors.Is.
+var (
+	ErrValidation          = problem.ErrValidation
+	ErrUnauthorized        = problem.ErrUnauthorized
+	ErrForbidden           = problem.ErrForbidden
+	ErrNotFound            = problem.ErrNotFound
+	ErrAlreadyVoted        = problem.ErrAlreadyVoted
+	ErrCandidateNotAllowed = problem.ErrCandidateNotAllowed
+	ErrElectionNotStarted  = problem.ErrElectionNotStarted
+	ErrElectionClosed      = problem.ErrElectionClosed
+	ErrRateLimited         = problem.ErrRateLimited
+	ErrUnavailable         = problem.ErrUnavailable
+)
+
+// ErrNoElection is returned when neither the call nor the client has an
+// election id.
+var ErrNoElection = errors.New("electionsclient: election id is not set")
+
+type Vote struct {
+	// ElectionId falls back to the client election if zero.
+	ElectionId  uint32    `json:"election_id,omitempty"`
+	Passport    string    `json:"passport,omitempty"`
+	CandidateId uint32    `json:"candidate_id,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	Time        time.Time `json:"time,omitempty"`
+}
+
+type Stats struct {
+	ElectionId uint32            `json:"election_id"`
+	Status     string            `json:"status"`
+	Final      bool              `json:"final"`
+	Records    map[uint32]uint32 `json:"records"`
+	Candidates []CandidateStat   `json:"candidates"`
+	Time       time.Time         `json:"time"`
+	// EventId is set on stats received from StreamStats.
+	EventId string `json:"-"`
+}
+
+type CandidateStat struct {
+	CandidateId uint32    `json:"candidate_id"`
+	Name        string    `json:"name"`
+	Party       string    `json:"party"`
+	Stat        uint32    `json:"stat"`
+	Time        time.Time `json:"time"`
+}
+
+// Middleware wraps the transport of the client, see WithRoundTrippers.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// Logging prints every request and response, see middleware.LoggingRoundTripper.
+func Logging() Middleware {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return middleware.NewLoggingRoundTripper(rt)
+	}
+}
+
+// Retry resends failed idempotent requests, see middleware.RetryRoundTripper.
+// Put it before CircuitBreaker, so every attempt is seen by the breaker.
+func Retry(policy middleware.RetryPolicy) Middleware {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return middleware.NewRetryRoundTripper(rt, policy)
+	}
+}
+
+// CircuitBreaker fails fast while the server keeps failing, see
+// middleware.BreakerRoundTripper.
+func CircuitBreaker(policy middleware.BreakerPolicy) Middleware {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return middleware.NewBreakerRoundTripper(rt, policy)
+	}
+}
+
+// Hedge sends a copy of slow idempotent requests, see
+// middleware.HedgeRoundTripper.
+func Hedge(policy middleware.HedgePolicy) Middleware {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return middleware.NewHedgeRoundTripper(rt, policy)
+	}
+}
+
+type Option func(*Client)
+
+// WithTimeout limits every call except StreamStats, the default is 10s.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.timeout = d }
+}
+
+// WithTransport replaces http.DefaultTransport as the innermost transport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.transport = rt }
+}
+
+// WithRoundTrippers adds transport middlewares, the first one is the
+// outermost and sees the request first.
+func WithRoundTrippers(mws ...Middleware) Option {
+	return func(c *Client) { c.mws = append(c.mws, mws...) }
+}
+
+// WithElection sets the election used when a call doesn't name one.
+func WithElection(id uint32) Option {
+	return func(c *Client) { c.electionId = id }
+}
+
+// WithToken authenticates every call with the JWT.
+func WithToken(token string) Option {
+	return func(c *Client) { c.authorization = "Bearer " + token }
+}
+
+// WithAPIKey authenticates every call with the API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.authorization = "ApiKey " + key }
+}
+
+type Client struct {
+	base       *url.URL
+	http       *http.Client
+	timeout    time.Duration
+	transport  http.RoundTripper
+	mws        []Middleware
+	electionId uint32
+	// authorization is the Authorization header value, empty if none
+	authorization string
+}
+
+func New(baseURL string, opts ...Option) (*Client, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("electionsclient: bad base url: %w", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("electionsclient: bad base url %q: scheme must be http or https", baseURL)
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+
+	c := &Client{
+		base:      base,
+		timeout:   defaultTimeout,
+		transport: http.DefaultTransport,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	rt := c.transport
+	for i := len(c.mws) - 1; i >= 0; i-- {
+		rt = c.mws[i](rt)
+	}
+	// no client timeout, it would cut streams, calls use context deadlines
+	c.http = &http.Client{Transport: rt}
+	return c, nil
+}
+
+// ForElection returns a client sharing the connection pool that works
+// with another election.
+func (c *Client) ForElection(id uint32) *Client {
+	cp := *c
+	cp.electionId = id
+	return &cp
+}
+
+func (c *Client) SubmitVote(ctx context.Context, v Vote) error {
+	if v.ElectionId == 0 {
+		v.ElectionId = c.electionId
+	}
+	if v.ElectionId == 0 {
+		return ErrNoElection
+	}
+	return c.do(ctx, http.MethodPost, "/vote", nil, v, nil)
+}
+
+// Stats returns stats of every candidate on the ballot.
+func (c *Client) Stats(ctx context.Context) (*Stats, error) {
+	if c.electionId == 0 {
+		return nil, ErrNoElection
+	}
+	stats := &Stats{}
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/elections/%d/stats", c.electionId), nil, nil, stats)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (c *Client) CandidateStat(ctx context.Context, candidateId uint32) (*CandidateStat, error) {
+	if c.electionId == 0 {
+		return nil, ErrNoElection
+	}
+	stat := &CandidateStat{}
+	path := fmt.Sprintf("/elections/%d/stats/%d", c.electionId, candidateId)
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, stat); err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+func (c *Client) url(path string, query url.Values) string {
+	u := *c.base
+	u.Path += path
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// do sends body as JSON and decodes the data field of the response into
+// out, error responses are returned as *problem.Problem.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("electionsclient: cannot marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, query), reqBody)
+	if err != nil {
+		return fmt.Errorf("electionsclient: %w", err)
+	}
+	req.Header.Set("Accept", "application/json, "+problem.ContentType)
+	c.authorize(req)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("electionsclient: %s %s: %w", method, path, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return problem.FromResponse(res)
+	}
+	if out == nil {
+		// drain, so the connection goes back to the pool
+		io.Copy(io.Discard, res.Body)
+		return nil
+	}
+
+	envelope := struct {
+		Data any `json:"data"`
+	}{Data: out}
+	if err := json.NewDecoder(res.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("electionsclient: cannot decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *Client) authorize(req *http.Request) {
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+}
+
+func formatUint(v uint32) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
@@ -0,0 +1,223 @@
+package electionsclient_test
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/electionsclient"
+	"github.com/OtusGolang/webinars_practical_part/26-http/handler"
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/OtusGolang/webinars_practical_part/26-http/store"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer runs the real service with an open election 1, a scheduled
+// election 2 and election 3 that closes after closeIn.
+func newTestServer(t *testing.T, closeIn time.Duration) *httptest.Server {
+	return serve(t, newTestService(t, closeIn))
+}
+
+func newTestService(t *testing.T, closeIn time.Duration) *handler.Service {
+	candidateRegistry := candidates.NewRegistry()
+	for _, c := range []candidates.Candidate{
+		{Id: 1, Name: "Alice", Party: "Green", Active: true},
+		{Id: 2, Name: "Bob", Active: true},
+	} {
+		_, err := candidateRegistry.Create(c)
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	electionRegistry := elections.NewRegistry()
+	for _, e := range []elections.Election{
+		{Id: 1, Title: "open", Candidates: []uint32{1, 2}, OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour)},
+		{Id: 2, Title: "scheduled", Candidates: []uint32{1, 2}, OpensAt: now.Add(time.Hour), ClosesAt: now.Add(2 * time.Hour)},
+		{Id: 3, Title: "closing", Candidates: []uint32{1, 2}, OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(closeIn)},
+	} {
+		_, err := electionRegistry.Create(e)
+		require.NoError(t, err)
+	}
+
+	return handler.NewService(store.NewMemory(), candidateRegistry, electionRegistry)
+}
+
+func serve(t *testing.T, service *handler.Service) *httptest.Server {
+	ts := httptest.NewServer(service.Routes())
+	t.Cleanup(func() {
+		service.CloseStreams(context.Background())
+		ts.Close()
+	})
+	return ts
+}
+
+func TestClient_Votes(t *testing.T) {
+	ts := newTestServer(t, time.Hour)
+	c, err := electionsclient.New(ts.URL+"/", electionsclient.WithElection(1))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, c.SubmitVote(ctx, electionsclient.Vote{Passport: "a", CandidateId: 1}))
+	require.NoError(t, c.SubmitVote(ctx, electionsclient.Vote{Passport: "b", CandidateId: 1}))
+	require.NoError(t, c.SubmitVote(ctx, electionsclient.Vote{Passport: "c", CandidateId: 2}))
+
+	err = c.SubmitVote(ctx, electionsclient.Vote{Passport: "a", CandidateId: 2})
+	require.ErrorIs(t, err, electionsclient.ErrAlreadyVoted)
+
+	err = c.SubmitVote(ctx, electionsclient.Vote{CandidateId: 2})
+	require.ErrorIs(t, err, electionsclient.ErrValidation)
+	p, ok := problem.As(err)
+	require.True(t, ok)
+	require.Equal(t, []problem.Violation{{Field: "passport", Reason: "is required"}}, p.Violations)
+
+	err = c.SubmitVote(ctx, electionsclient.Vote{ElectionId: 2, Passport: "a", CandidateId: 2})
+	require.ErrorIs(t, err, electionsclient.ErrElectionNotStarted)
+
+	stats, err := c.Stats(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), stats.ElectionId)
+	require.Equal(t, "open", stats.Status)
+	require.Len(t, stats.Candidates, 2)
+	require.Equal(t, "Alice", stats.Candidates[0].Name)
+	require.Equal(t, uint32(2), stats.Candidates[0].Stat)
+	require.Equal(t, uint32(1), stats.Candidates[1].Stat)
+
+	stat, err := c.CandidateStat(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), stat.CandidateId)
+	require.Equal(t, uint32(1), stat.Stat)
+
+	_, err = c.ForElection(9).Stats(ctx)
+	require.ErrorIs(t, err, electionsclient.ErrNotFound)
+
+	noElection, err := electionsclient.New(ts.URL)
+	require.NoError(t, err)
+	_, err = noElection.Stats(ctx)
+	require.ErrorIs(t, err, electionsclient.ErrNoElection)
+}
+
+func TestClient_Auth(t *testing.T) {
+	service := newTestService(t, time.Hour)
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	require.NoError(t, err)
+	service.Auth = auth.NewAuthenticator(secret)
+	ts := serve(t, service)
+	ctx := context.Background()
+
+	anonymous, err := electionsclient.New(ts.URL, electionsclient.WithElection(1))
+	require.NoError(t, err)
+	err = anonymous.SubmitVote(ctx, electionsclient.Vote{Passport: "a", CandidateId: 1})
+	require.ErrorIs(t, err, electionsclient.ErrUnauthorized)
+
+	token, err := service.Auth.Sign(auth.Principal{Subject: "alice", Roles: []auth.Role{auth.RoleVoter}}, time.Hour)
+	require.NoError(t, err)
+	voter, err := electionsclient.New(ts.URL, electionsclient.WithElection(1), electionsclient.WithToken(token))
+	require.NoError(t, err)
+	require.NoError(t, voter.SubmitVote(ctx, electionsclient.Vote{Passport: "a", CandidateId: 1}))
+	_, err = voter.Stats(ctx)
+	require.ErrorIs(t, err, electionsclient.ErrForbidden)
+
+	key := "observer-key"
+	require.NoError(t, service.Auth.AddAPIKey(key, auth.Principal{Subject: "board", Roles: []auth.Role{auth.RoleObserver}}))
+	observer, err := electionsclient.New(ts.URL, electionsclient.WithElection(1), electionsclient.WithAPIKey(key))
+	require.NoError(t, err)
+	stats, err := observer.Stats(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), stats.Candidates[0].Stat)
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	_, err = voter.StreamStats(streamCtx)
+	require.ErrorIs(t, err, electionsclient.ErrForbidden)
+	updates, err := observer.StreamStats(streamCtx)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), (<-updates).Candidates[0].Stat)
+}
+
+func TestClient_Transport(t *testing.T) {
+	ts := newTestServer(t, time.Hour)
+
+	var order []string
+	trace := func(name string) electionsclient.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(r)
+			})
+		}
+	}
+	var slow atomic.Bool
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if slow.Load() {
+			<-r.Context().Done()
+			return nil, r.Context().Err()
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	c, err := electionsclient.New(ts.URL,
+		electionsclient.WithElection(1),
+		electionsclient.WithTimeout(50*time.Millisecond),
+		electionsclient.WithTransport(transport),
+		electionsclient.WithRoundTrippers(trace("outer"), electionsclient.Logging(), trace("inner")),
+	)
+	require.NoError(t, err)
+
+	_, err = c.Stats(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"outer", "inner"}, order)
+
+	slow.Store(true)
+	_, err = c.Stats(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = electionsclient.New("localhost:8080")
+	require.Error(t, err)
+}
+
+func TestClient_StreamStats(t *testing.T) {
+	ts := newTestServer(t, 300*time.Millisecond)
+	c, err := electionsclient.New(ts.URL, electionsclient.WithElection(3))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = c.ForElection(9).StreamStats(ctx)
+	require.ErrorIs(t, err, electionsclient.ErrNotFound)
+
+	ch, err := c.StreamStats(ctx)
+	require.NoError(t, err)
+
+	first := <-ch
+	require.Equal(t, uint32(3), first.ElectionId)
+	require.NotEmpty(t, first.EventId)
+	require.Equal(t, uint32(0), first.Candidates[0].Stat)
+
+	require.NoError(t, c.SubmitVote(ctx, electionsclient.Vote{Passport: "a", CandidateId: 1}))
+	second := <-ch
+	require.NotEqual(t, first.EventId, second.EventId)
+	require.Equal(t, uint32(1), second.Candidates[0].Stat)
+	require.False(t, second.Final)
+
+	// the election closes, the final stats end the stream
+	last := <-ch
+	require.True(t, last.Final)
+	require.Equal(t, "closed", last.Status)
+	_, ok := <-ch
+	require.False(t, ok)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
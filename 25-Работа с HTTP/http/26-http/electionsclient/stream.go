@@ -0,0 +1,158 @@
+package electionsclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+)
+
+const defaultRetry = 3 * time.Second
+
+// StreamStats follows the stats of the client election over Server-Sent
+// Events. The first connection is made before returning, so a wrong
+// election is reported right away. After that the stream reconnects on its
+// own and resumes with the last event id. The channel is closed when ctx
+// is done or after the final stats of a closed election.
+func (c *Client) StreamStats(ctx context.Context) (<-chan Stats, error) {
+	if c.electionId == 0 {
+		return nil, ErrNoElection
+	}
+
+	s := &stream{
+		client: c,
+		url:    c.url("/stat-events", url.Values{"election_id": {formatUint(c.electionId)}}),
+		retry:  defaultRetry,
+		out:    make(chan Stats, 1),
+	}
+	body, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.run(ctx, body)
+	return s.out, nil
+}
+
+type stream struct {
+	client      *Client
+	url         string
+	lastEventId string
+	retry       time.Duration
+	out         chan Stats
+}
+
+func (s *stream) connect(ctx context.Context) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("electionsclient: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	s.client.authorize(req)
+	if s.lastEventId != "" {
+		req.Header.Set("Last-Event-ID", s.lastEventId)
+	}
+
+	res, err := s.client.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("electionsclient: stats stream: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, problem.FromResponse(res)
+	}
+	return res.Body, nil
+}
+
+func (s *stream) run(ctx context.Context, body io.ReadCloser) {
+	defer close(s.out)
+
+	for {
+		final, err := s.read(ctx, body)
+		body.Close()
+		if final || ctx.Err() != nil {
+			return
+		}
+		slog.Warn("stats stream interrupted, reconnecting", "err", err, "retry", s.retry)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.retry):
+			}
+			body, err = s.connect(ctx)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("stats stream reconnect failed", "err", err, "retry", s.retry)
+		}
+	}
+}
+
+// read delivers events until the stream breaks, it reports whether the
+// final stats were delivered.
+func (s *stream) read(ctx context.Context, body io.Reader) (bool, error) {
+	var id, event string
+	var data []string
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 && (event == "" || event == "stats") {
+				s.lastEventId = id
+				stats := Stats{}
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &stats); err != nil {
+					return false, fmt.Errorf("electionsclient: bad stats event %s: %w", id, err)
+				}
+				stats.EventId = id
+
+				select {
+				case s.out <- stats:
+				case <-ctx.Done():
+					return false, ctx.Err()
+				}
+				if stats.Final {
+					return true, nil
+				}
+			}
+			id, event, data = s.lastEventId, "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment, the server uses them as heartbeats
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, err
+	}
+	return false, io.ErrUnexpectedEOF
+}
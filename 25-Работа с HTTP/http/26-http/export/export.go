@@ -0,0 +1,254 @@
+// Package export builds reports of an election from the stored votes:
+// totals per candidate and a time series of votes in fixed buckets, written
+// as CSV or JSON. Totals and series are built in one pass over
+// store.VoteStore.Votes, so they are consistent with each other even while
+// votes keep arriving.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/store"
+)
+
+var ErrInvalidBucket = errors.New("invalid bucket")
+
+// Bucket is the width of a series point, it is written like "1m", "1h" or
+// "1d". Points start at multiples of the width since the zero time, in UTC.
+type Bucket time.Duration
+
+// ParseBucket reads a time.Duration with a "d" unit for days on top.
+func ParseBucket(s string) (Bucket, error) {
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		if n, err = strconv.Atoi(days); err == nil {
+			d = time.Duration(n) * 24 * time.Hour
+		}
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d < time.Second {
+		return 0, fmt.Errorf("%w: %q, expect a duration of at least 1s like 1m, 1h or 1d", ErrInvalidBucket, s)
+	}
+	return Bucket(d), nil
+}
+
+func (b Bucket) String() string {
+	d := time.Duration(b)
+	if d%(24*time.Hour) == 0 {
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	}
+	// "1h0m0s" reads better as "1h"
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+func (b Bucket) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *Bucket) UnmarshalText(text []byte) error {
+	parsed, err := ParseBucket(string(text))
+	*b = parsed
+	return err
+}
+
+// Report is a snapshot of the votes of an election at Time.
+type Report struct {
+	ElectionId uint32    `json:"election_id" xml:"election_id"`
+	Bucket     Bucket    `json:"bucket" xml:"bucket"`
+	Time       time.Time `json:"time" xml:"time"`
+	Totals     []Total   `json:"totals" xml:"totals>total"`
+	// Series lists the points of buckets with votes, ordered by start and
+	// candidate.
+	Series []Point `json:"series" xml:"series>point"`
+}
+
+type Total struct {
+	CandidateId uint32 `json:"candidate_id" xml:"candidate_id"`
+	Name        string `json:"name,omitempty" xml:"name,omitempty"`
+	Votes       uint32 `json:"votes" xml:"votes"`
+}
+
+// Point is the change of the votes of a candidate within the bucket
+// starting at Start. Votes goes below zero when more voters revoted away
+// from the candidate than for them, Total is the count at the bucket end.
+type Point struct {
+	Start       time.Time `json:"start" xml:"start"`
+	CandidateId uint32    `json:"candidate_id" xml:"candidate_id"`
+	Votes       int64     `json:"votes" xml:"votes"`
+	Total       uint32    `json:"total" xml:"total"`
+}
+
+// Options select the votes of a report.
+type Options struct {
+	ElectionId uint32
+	Bucket     Bucket
+	// Candidates are listed in the totals even without votes, usually the
+	// ballot of the election.
+	Candidates []uint32
+	// Now is the time of the snapshot, time.Now if nil.
+	Now func() time.Time
+}
+
+// Build reads the votes of the election from the store, the snapshot
+// is the one of store.VoteStore.Votes.
+func Build(ctx context.Context, s store.VoteStore, opts Options) (*Report, error) {
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	b := NewBuilder(opts.ElectionId, opts.Bucket)
+	b.Candidates(opts.Candidates...)
+	snapshot := now().UTC()
+	err := s.Votes(ctx, func(v store.Vote) error {
+		b.Add(v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Report(snapshot), nil
+}
+
+// Builder aggregates votes of one election, votes of other elections are
+// skipped. Votes must be added in the order they were stored, as revotes
+// withdraw earlier votes.
+type Builder struct {
+	electionId uint32
+	bucket     Bucket
+	totals     map[uint32]int64
+	series     map[point]int64
+}
+
+type point struct {
+	start       int64 // unix nanoseconds
+	candidateId uint32
+}
+
+func NewBuilder(electionId uint32, bucket Bucket) *Builder {
+	if bucket <= 0 {
+		bucket = Bucket(time.Hour)
+	}
+	return &Builder{
+		electionId: electionId,
+		bucket:     bucket,
+		totals:     make(map[uint32]int64),
+		series:     make(map[point]int64),
+	}
+}
+
+// Candidates lists the candidates in the totals even without votes.
+func (b *Builder) Candidates(ids ...uint32) {
+	for _, id := range ids {
+		if _, ok := b.totals[id]; !ok {
+			b.totals[id] = 0
+		}
+	}
+}
+
+func (b *Builder) Add(v store.Vote) {
+	if v.ElectionId != b.electionId {
+		return
+	}
+	start := v.Time.UTC().Truncate(time.Duration(b.bucket)).UnixNano()
+	b.totals[v.CandidateId]++
+	b.series[point{start, v.CandidateId}]++
+	// same as the stores: a revote never takes the count below zero
+	if v.Replaces != 0 && b.totals[v.Replaces] > 0 {
+		b.totals[v.Replaces]--
+		b.series[point{start, v.Replaces}]--
+	}
+}
+
+// Report returns the report of the votes added so far.
+func (b *Builder) Report(now time.Time) *Report {
+	r := &Report{
+		ElectionId: b.electionId,
+		Bucket:     b.bucket,
+		Time:       now,
+		Totals:     make([]Total, 0, len(b.totals)),
+		Series:     make([]Point, 0, len(b.series)),
+	}
+	for id, votes := range b.totals {
+		r.Totals = append(r.Totals, Total{CandidateId: id, Votes: uint32(votes)})
+	}
+	sort.Slice(r.Totals, func(i, j int) bool { return r.Totals[i].CandidateId < r.Totals[j].CandidateId })
+
+	keys := make([]point, 0, len(b.series))
+	for k := range b.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].start != keys[j].start {
+			return keys[i].start < keys[j].start
+		}
+		return keys[i].candidateId < keys[j].candidateId
+	})
+	running := make(map[uint32]int64)
+	for _, k := range keys {
+		running[k.candidateId] += b.series[k]
+		r.Series = append(r.Series, Point{
+			Start:       time.Unix(0, k.start).UTC(),
+			CandidateId: k.candidateId,
+			Votes:       b.series[k],
+			Total:       uint32(running[k.candidateId]),
+		})
+	}
+	return r
+}
+
+// View is the table of a report written as CSV.
+type View string
+
+const (
+	ViewTotals View = "totals"
+	ViewSeries View = "series"
+)
+
+// WriteCSV writes the totals or the series of the report as CSV with a
+// header row, times are RFC 3339 in UTC.
+func WriteCSV(w io.Writer, r *Report, view View) error {
+	cw := csv.NewWriter(w)
+	switch view {
+	case ViewTotals:
+		cw.Write([]string{"election_id", "candidate_id", "name", "votes"})
+		for _, t := range r.Totals {
+			cw.Write([]string{
+				formatUint(r.ElectionId), formatUint(t.CandidateId), t.Name, formatUint(t.Votes),
+			})
+		}
+	case ViewSeries:
+		cw.Write([]string{"election_id", "bucket_start", "bucket", "candidate_id", "votes", "total"})
+		for _, p := range r.Series {
+			cw.Write([]string{
+				formatUint(r.ElectionId), p.Start.Format(time.RFC3339), r.Bucket.String(),
+				formatUint(p.CandidateId), strconv.FormatInt(p.Votes, 10), formatUint(p.Total),
+			})
+		}
+	default:
+		return fmt.Errorf("unknown view %q, expect %s or %s", view, ViewTotals, ViewSeries)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatUint(v uint32) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
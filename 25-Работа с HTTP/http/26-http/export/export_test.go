@@ -0,0 +1,106 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/export"
+	"github.com/OtusGolang/webinars_practical_part/26-http/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBucket(t *testing.T) {
+	for raw, want := range map[string]time.Duration{
+		"1m":    time.Minute,
+		"1h":    time.Hour,
+		"1d":    24 * time.Hour,
+		"7d":    7 * 24 * time.Hour,
+		"15m":   15 * time.Minute,
+		"1h30m": 90 * time.Minute,
+	} {
+		b, err := export.ParseBucket(raw)
+		require.NoError(t, err, raw)
+		require.Equal(t, want, time.Duration(b), raw)
+		require.Equal(t, raw, b.String())
+	}
+	for _, raw := range []string{"", "0s", "500ms", "-1h", "d", "1w"} {
+		_, err := export.ParseBucket(raw)
+		require.True(t, errors.Is(err, export.ErrInvalidBucket), raw)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemory()
+	at := func(clock string) time.Time {
+		t, _ := time.Parse(time.RFC3339, "2024-09-08T"+clock+"Z")
+		return t
+	}
+	for _, v := range []store.Vote{
+		{ElectionId: 1, Passport: "a", CandidateId: 1, Time: at("08:10:00")},
+		{ElectionId: 1, Passport: "b", CandidateId: 2, Time: at("08:50:00")},
+		{ElectionId: 2, Passport: "a", CandidateId: 1, Time: at("08:55:00")},
+		{ElectionId: 1, Passport: "c", CandidateId: 1, Time: at("09:05:00")},
+		// a revote from 1 to 2
+		{ElectionId: 1, Passport: "a", CandidateId: 2, Time: at("09:30:00"), Replaces: 1},
+	} {
+		require.NoError(t, s.Add(ctx, v))
+	}
+
+	now := at("10:00:00")
+	r, err := export.Build(ctx, s, export.Options{
+		ElectionId: 1,
+		Bucket:     export.Bucket(time.Hour),
+		Candidates: []uint32{1, 2, 3},
+		Now:        func() time.Time { return now },
+	})
+	require.NoError(t, err)
+	require.Equal(t, now, r.Time)
+	require.Equal(t, []export.Total{
+		{CandidateId: 1, Votes: 1},
+		{CandidateId: 2, Votes: 2},
+		{CandidateId: 3, Votes: 0},
+	}, r.Totals)
+	require.Equal(t, []export.Point{
+		{Start: at("08:00:00"), CandidateId: 1, Votes: 1, Total: 1},
+		{Start: at("08:00:00"), CandidateId: 2, Votes: 1, Total: 1},
+		{Start: at("09:00:00"), CandidateId: 1, Votes: 0, Total: 1},
+		{Start: at("09:00:00"), CandidateId: 2, Votes: 1, Total: 2},
+	}, r.Series)
+
+	stats, err := s.Stats(ctx, 1)
+	require.NoError(t, err)
+	for _, total := range r.Totals[:2] {
+		require.Equal(t, stats[total.CandidateId], total.Votes, "totals match the store")
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteCSV(&buf, r, export.ViewSeries))
+	require.Equal(t, `election_id,bucket_start,bucket,candidate_id,votes,total
+1,2024-09-08T08:00:00Z,1h,1,1,1
+1,2024-09-08T08:00:00Z,1h,2,1,1
+1,2024-09-08T09:00:00Z,1h,1,0,1
+1,2024-09-08T09:00:00Z,1h,2,1,2
+`, buf.String())
+
+	r.Totals[0].Name = "Alice, Jr."
+	buf.Reset()
+	require.NoError(t, export.WriteCSV(&buf, r, export.ViewTotals))
+	require.Equal(t, `election_id,candidate_id,name,votes
+1,1,"Alice, Jr.",1
+1,2,,2
+1,3,,0
+`, buf.String())
+	require.Error(t, export.WriteCSV(&buf, r, "pie"))
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	var decoded export.Report
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, export.Bucket(time.Hour), decoded.Bucket)
+	require.Contains(t, string(data), `"bucket":"1h"`)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+)
+
+// GET /audit/head
+// The receipt of the last audit record, observers publish it so proofs
+// can be checked against it.
+func (s *Service) AuditHead(w http.ResponseWriter, r *http.Request) {
+	if !s.auditOn(w, r) {
+		return
+	}
+	head, ok := s.Voting.Audit.Head()
+	if !ok {
+		writeProblem(w, r, problem.New(http.StatusNotFound, problem.CodeNotFound, "audit log is empty"))
+		return
+	}
+	s.writeResponse(w, r, http.StatusOK, &Response{Data: head})
+}
+
+// GET /audit/proof?index=41&hash=9f86d08...
+// The audit.Proof that the record of the receipt is in the log, see
+// 27-grpc/audit/verify to check it.
+func (s *Service) AuditProof(w http.ResponseWriter, r *http.Request) {
+	if !s.auditOn(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	var violations []problem.Violation
+	index, err := strconv.ParseUint(query.Get("index"), 10, 64)
+	if err != nil {
+		violations = append(violations, problem.Violation{Field: "index", Reason: fmt.Sprintf("expect int, got: %s", query.Get("index"))})
+	}
+	hash := query.Get("hash")
+	if hash == "" {
+		violations = append(violations, problem.Violation{Field: "hash", Reason: "is required"})
+	}
+	if len(violations) > 0 {
+		writeProblem(w, r, problem.Validation(violations...))
+		return
+	}
+
+	proof, err := s.Voting.Audit.Prove(audit.Receipt{Index: index, Hash: hash})
+	switch {
+	case errors.Is(err, audit.ErrNotFound):
+		writeProblem(w, r, problem.New(http.StatusNotFound, problem.CodeNotFound, err.Error()))
+	case errors.Is(err, audit.ErrMismatch):
+		writeProblem(w, r, problem.New(http.StatusConflict, problem.CodeConflict, err.Error()))
+	case err != nil:
+		writeProblem(w, r, problem.New(http.StatusInternalServerError, problem.CodeInternal, err.Error()))
+	default:
+		s.writeResponse(w, r, http.StatusOK, &Response{Data: proof})
+	}
+}
+
+// auditOn writes 404 if Audit is off.
+func (s *Service) auditOn(w http.ResponseWriter, r *http.Request) bool {
+	if s.Voting.Audit == nil {
+		writeProblem(w, r, problem.New(http.StatusNotFound, problem.CodeNotFound, "audit log is off"))
+		return false
+	}
+	return true
+}
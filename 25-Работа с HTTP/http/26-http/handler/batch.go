@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/codec"
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/receipts"
+)
+
+const (
+	// maxBatchLine bounds one vote of a batch.
+	maxBatchLine = 64 << 10
+	// maxAtomicBatch bounds the votes an atomic batch keeps in memory
+	// until the body ends, other batches are applied line by line.
+	maxAtomicBatch = 10000
+	// batchFlush is how many results are buffered before they are sent.
+	batchFlush = 64
+)
+
+// batchCodecs negotiate the body of POST /votes:batch, only NDJSON streams.
+var batchCodecs = codec.NewRegistry(codec.NDJSON)
+
+// BatchResult is the result of one line of a batch, lines are counted from
+// 1 and empty lines are skipped. Rejected lines carry the problem code,
+// accepted lines the receipt as sent by SubmitVote.
+type BatchResult struct {
+	Line    int               `json:"line"`
+	Status  int               `json:"status"`
+	Code    problem.Code      `json:"code,omitempty"`
+	Detail  string            `json:"detail,omitempty"`
+	Receipt *receipts.Receipt `json:"receipt,omitempty"`
+}
+
+// BatchSummary is sent after the results of all lines.
+type BatchSummary struct {
+	Accepted int  `json:"accepted"`
+	Rejected int  `json:"rejected"`
+	Atomic   bool `json:"atomic,omitempty"`
+}
+
+// batchLine is a vote of an atomic batch waiting for the body to end.
+type batchLine struct {
+	line    int
+	req     *VoteRequest
+	p       *problem.Problem
+	receipt *receipts.Receipt
+}
+
+// POST /votes:batch
+// POST /votes:batch?atomic=true
+// The body is NDJSON with a VoteRequest per line, the response is NDJSON
+// with a BatchResult per line and a {"summary": BatchSummary} line at the
+// end. Lines are applied as they are read and results are sent while the
+// body is still uploading, a line over BatchLimit is rejected on its own.
+// An atomic batch is applied once the body ends, either every vote or
+// none, and must be for a single election.
+func (s *Service) SubmitVotes(w http.ResponseWriter, r *http.Request) {
+	if _, err := batchCodecs.ForContentType(r.Header.Get("Content-Type"), nil); err != nil {
+		writeProblem(w, r, problem.New(http.StatusUnsupportedMediaType, problem.CodeUnsupportedMedia, err.Error()))
+		return
+	}
+	if _, err := batchCodecs.ForAccept(r.Header.Get("Accept"), nil); err != nil {
+		writeProblem(w, r, problem.New(http.StatusNotAcceptable, problem.CodeNotAcceptable, err.Error()))
+		return
+	}
+	atomic := false
+	if raw := r.URL.Query().Get("atomic"); raw != "" {
+		var err error
+		if atomic, err = strconv.ParseBool(raw); err != nil {
+			writeProblem(w, r, problem.Validation(problem.Violation{Field: "atomic", Reason: "must be a boolean"}))
+			return
+		}
+	}
+
+	// HTTP/1 stops reading the body once the response starts, unless full
+	// duplex is on; HTTP/2 is always full duplex
+	if err := http.NewResponseController(w).EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(r.Context(), "unable to enable full duplex", "err", err)
+	}
+	w.Header().Set("Content-Type", codec.NDJSON.ContentType())
+	w.WriteHeader(http.StatusOK)
+
+	out := &batchWriter{w: w, ctx: r.Context()}
+	var pending []batchLine
+	line := 0
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxBatchLine)
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		req := &VoteRequest{}
+		var p *problem.Problem
+		if err := json.Unmarshal(data, req); err != nil {
+			p = problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, err.Error())
+		} else if s.BatchLimit != nil {
+			p = s.BatchLimit(r, req)
+		}
+
+		if atomic {
+			if len(pending) == maxAtomicBatch {
+				p = problem.New(http.StatusRequestEntityTooLarge, problem.CodeInvalidRequest,
+					fmt.Sprintf("atomic batch is limited to %d votes", maxAtomicBatch))
+				pending = append(pending, batchLine{line: line, p: p})
+				break
+			}
+			pending = append(pending, batchLine{line: line, req: req, p: p})
+			continue
+		}
+		var receipt *receipts.Receipt
+		if p != nil {
+			s.countVote(req.CandidateId, p.Code)
+		} else {
+			receipt, p = s.submit(r.Context(), req)
+		}
+		out.result(line, p, receipt)
+	}
+	if err := scanner.Err(); err != nil {
+		if !errors.Is(err, bufio.ErrTooLong) {
+			// the client is gone or the body broke, nothing to answer
+			slog.WarnContext(r.Context(), "unable to read vote batch", "err", err, "line", line+1)
+			return
+		}
+		p := problem.New(http.StatusRequestEntityTooLarge, problem.CodeInvalidRequest,
+			fmt.Sprintf("line exceeds %d bytes, the rest of the batch is skipped", maxBatchLine))
+		if atomic {
+			pending = append(pending, batchLine{line: line + 1, p: p})
+		} else {
+			out.result(line+1, p, nil)
+		}
+	}
+
+	if atomic {
+		s.submitAll(r.Context(), pending)
+		for _, l := range pending {
+			out.result(l.line, l.p, l.receipt)
+		}
+	}
+	slog.InfoContext(r.Context(), "vote batch done", "atomic", atomic, "accepted", out.summary.Accepted, "rejected", out.summary.Rejected)
+	out.summary.Atomic = atomic
+	out.write(struct {
+		Summary BatchSummary `json:"summary"`
+	}{out.summary})
+	out.flush()
+}
+
+// submitAll applies the votes of an atomic batch, either all or none, and
+// sets the problem of every rejected line. If a line is rejected the
+// others are rejected with batch_aborted.
+func (s *Service) submitAll(ctx context.Context, lines []batchLine) {
+	if len(lines) == 0 {
+		return
+	}
+	votes := make([]elections.Vote, 0, len(lines))
+	unread := false
+	for _, l := range lines {
+		if l.p != nil {
+			unread = true
+			continue
+		}
+		votes = append(votes, l.req.vote())
+	}
+
+	var issued []*receipts.Receipt
+	var errs []error
+	if unread {
+		// lines that couldn't be read abort the batch, the others are
+		// only checked
+		errs = make([]error, 0, len(votes))
+		for _, v := range votes {
+			errs = append(errs, s.Voting.Check(v))
+		}
+	} else {
+		issued, errs = s.Voting.SubmitAll(ctx, votes)
+	}
+
+	// a failed apply sets the same error for every line, log it once
+	var prevErr error
+	var prev *problem.Problem
+	next := 0
+	for i := range lines {
+		l := &lines[i]
+		if l.p == nil {
+			var err error
+			if errs != nil {
+				err = errs[next]
+			}
+			if err == nil && unread {
+				err = elections.ErrAborted
+			}
+			switch {
+			case err == nil:
+				if issued != nil {
+					l.receipt = issued[next]
+				}
+			case err == prevErr:
+				l.p = prev
+			default:
+				l.p = s.submitProblem(ctx, err)
+				prevErr, prev = err, l.p
+			}
+			next++
+		}
+		var reason problem.Code
+		if l.p != nil {
+			reason = l.p.Code
+		}
+		s.countVote(l.candidateId(), reason)
+	}
+}
+
+func (l batchLine) candidateId() uint32 {
+	if l.req == nil {
+		return 0
+	}
+	return l.req.CandidateId
+}
+
+// batchWriter sends the batch results, flushing every batchFlush of them.
+type batchWriter struct {
+	w       http.ResponseWriter
+	ctx     context.Context
+	summary BatchSummary
+	pending int
+}
+
+// result sends the result of the line, p is nil for an accepted vote.
+func (b *batchWriter) result(line int, p *problem.Problem, receipt *receipts.Receipt) {
+	res := BatchResult{Line: line, Status: http.StatusOK, Receipt: receipt}
+	if p != nil {
+		res.Receipt = nil
+		res.Status, res.Code, res.Detail = p.Status, p.Code, p.Detail
+		b.summary.Rejected++
+	} else {
+		b.summary.Accepted++
+	}
+	b.write(res)
+	if b.pending++; b.pending == batchFlush {
+		b.flush()
+	}
+}
+
+func (b *batchWriter) write(v any) {
+	data, err := codec.NDJSON.Marshal(v)
+	if err == nil {
+		_, err = b.w.Write(data)
+	}
+	if err != nil {
+		slog.ErrorContext(b.ctx, "batch result write error", "err", err)
+	}
+}
+
+func (b *batchWriter) flush() {
+	b.pending = 0
+	if err := http.NewResponseController(b.w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.ErrorContext(b.ctx, "batch result flush error", "err", err)
+	}
+}
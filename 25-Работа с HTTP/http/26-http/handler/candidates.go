@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+)
+
+// GET /candidates
+func (s *Service) ListCandidates(w http.ResponseWriter, r *http.Request) {
+	resp := &Response{Data: s.Candidates.List()}
+	s.writeResponse(w, r, http.StatusOK, resp)
+}
+
+// POST /candidates
+func (s *Service) CreateCandidate(w http.ResponseWriter, r *http.Request) {
+	resp := &Response{}
+	c := candidates.Candidate{}
+	if p := s.decode(w, r, &c); p != nil {
+		writeProblem(w, r, p)
+		return
+	}
+
+	created, err := s.Candidates.Create(c)
+	if err != nil {
+		writeCandidateError(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "candidate created", "id", created.Id, "name", created.Name)
+	resp.Data = created
+	s.writeResponse(w, r, http.StatusCreated, resp)
+}
+
+// GET /candidates/{id}
+func (s *Service) GetCandidate(w http.ResponseWriter, r *http.Request) {
+	id, ok := candidateIdFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := s.Candidates.Get(id)
+	if err != nil {
+		writeCandidateError(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, &Response{Data: c})
+}
+
+// PUT /candidates/{id}
+func (s *Service) UpdateCandidate(w http.ResponseWriter, r *http.Request) {
+	resp := &Response{}
+	id, ok := candidateIdFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	c := candidates.Candidate{}
+	if p := s.decode(w, r, &c); p != nil {
+		writeProblem(w, r, p)
+		return
+	}
+	c.Id = id
+
+	updated, err := s.Candidates.Update(c)
+	if err != nil {
+		writeCandidateError(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "candidate updated", "id", updated.Id, "active", updated.Active)
+	resp.Data = updated
+	s.writeResponse(w, r, http.StatusOK, resp)
+}
+
+// DELETE /candidates/{id}
+func (s *Service) DeleteCandidate(w http.ResponseWriter, r *http.Request) {
+	id, ok := candidateIdFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.Candidates.Delete(id); err != nil {
+		writeCandidateError(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "candidate deleted", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func candidateIdFromPath(w http.ResponseWriter, r *http.Request) (uint32, bool) {
+	raw := r.PathValue("id")
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || id == 0 {
+		writeProblem(w, r, problem.Validation(problem.Violation{Field: "id", Reason: fmt.Sprintf("expect positive int, got: %s", raw)}))
+		return 0, false
+	}
+	return uint32(id), true
+}
+
+func writeCandidateError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, candidates.ErrNotFound):
+		writeProblem(w, r, problem.New(http.StatusNotFound, problem.CodeNotFound, err.Error()))
+	case errors.Is(err, candidates.ErrExists):
+		writeProblem(w, r, problem.New(http.StatusConflict, problem.CodeConflict, err.Error()))
+	case errors.Is(err, candidates.ErrInvalid):
+		writeProblem(w, r, problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, err.Error()))
+	default:
+		writeProblem(w, r, problem.New(http.StatusInternalServerError, problem.CodeInternal, err.Error()))
+	}
+}
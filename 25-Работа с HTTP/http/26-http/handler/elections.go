@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+)
+
+// ElectionResponse is an election together with its status at the time
+// of the request.
+type ElectionResponse struct {
+	elections.Election
+	Status elections.Status `json:"status" xml:"status"`
+}
+
+// GET /elections
+func (s *Service) ListElections(w http.ResponseWriter, r *http.Request) {
+	now := s.Voting.Now()
+	list := s.Elections.List()
+	data := make([]ElectionResponse, 0, len(list))
+	for _, e := range list {
+		data = append(data, ElectionResponse{Election: e, Status: e.Status(now)})
+	}
+	s.writeResponse(w, r, http.StatusOK, &Response{Data: data})
+}
+
+// POST /elections
+func (s *Service) CreateElection(w http.ResponseWriter, r *http.Request) {
+	resp := &Response{}
+	e := elections.Election{}
+	if p := s.decode(w, r, &e); p != nil {
+		writeProblem(w, r, p)
+		return
+	}
+	if err := s.checkBallot(e); err != nil {
+		writeElectionError(w, r, err)
+		return
+	}
+
+	created, err := s.Elections.Create(e)
+	if err != nil {
+		writeElectionError(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "election created", "id", created.Id, "title", created.Title)
+	resp.Data = ElectionResponse{Election: created, Status: created.Status(s.Voting.Now())}
+	s.writeResponse(w, r, http.StatusCreated, resp)
+}
+
+// GET /elections/{id}
+func (s *Service) GetElection(w http.ResponseWriter, r *http.Request) {
+	id, ok := electionIdFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := s.Elections.Get(id)
+	if err != nil {
+		writeElectionError(w, r, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, &Response{Data: ElectionResponse{Election: e, Status: e.Status(s.Voting.Now())}})
+}
+
+// PUT /elections/{id}, only a scheduled election can be changed.
+func (s *Service) UpdateElection(w http.ResponseWriter, r *http.Request) {
+	resp := &Response{}
+	id, ok := electionIdFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	e := elections.Election{}
+	if p := s.decode(w, r, &e); p != nil {
+		writeProblem(w, r, p)
+		return
+	}
+	e.Id = id
+	if err := s.checkBallot(e); err != nil {
+		writeElectionError(w, r, err)
+		return
+	}
+
+	updated, err := s.Elections.Update(e)
+	if err != nil {
+		writeElectionError(w, r, err)
+		return
+	}
+	s.rescheduleFinal(updated)
+
+	slog.InfoContext(r.Context(), "election updated", "id", updated.Id)
+	resp.Data = ElectionResponse{Election: updated, Status: updated.Status(s.Voting.Now())}
+	s.writeResponse(w, r, http.StatusOK, resp)
+}
+
+// GET /elections/{id}/stats, same as /stat?election_id={id}
+// GET /elections/{id}/stats/{candidate}, same as /stat?election_id={id}&candidate_id={candidate}
+func (s *Service) GetElectionStats(w http.ResponseWriter, r *http.Request) {
+	id, ok := electionIdFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := s.Elections.Get(id)
+	if err != nil {
+		writeElectionError(w, r, err)
+		return
+	}
+	s.writeStats(w, r, e)
+}
+
+// checkBallot makes sure every candidate on the ballot is registered.
+func (s *Service) checkBallot(e elections.Election) error {
+	for _, id := range e.Candidates {
+		if _, err := s.Candidates.Get(id); err != nil {
+			return fmt.Errorf("%w: %w", elections.ErrInvalid, err)
+		}
+	}
+	return nil
+}
+
+func electionIdFromPath(w http.ResponseWriter, r *http.Request) (uint32, bool) {
+	raw := r.PathValue("id")
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || id == 0 {
+		writeProblem(w, r, problem.Validation(problem.Violation{Field: "id", Reason: fmt.Sprintf("expect positive int, got: %s", raw)}))
+		return 0, false
+	}
+	return uint32(id), true
+}
+
+func writeElectionError(w http.ResponseWriter, r *http.Request, err error) {
+	writeProblem(w, r, electionProblem(err))
+}
+
+// reasonStatuses are the HTTP statuses of elections.Reason, the reason is
+// sent as the problem code.
+var reasonStatuses = map[elections.Reason]int{
+	elections.ReasonValidation:          http.StatusBadRequest,
+	elections.ReasonCandidateNotAllowed: http.StatusBadRequest,
+	elections.ReasonInvalid:             http.StatusBadRequest,
+	elections.ReasonNotFound:            http.StatusNotFound,
+	elections.ReasonNotStarted:          http.StatusForbidden,
+	elections.ReasonClosed:              http.StatusForbidden,
+	elections.ReasonAlreadyVoted:        http.StatusConflict,
+	elections.ReasonConflict:            http.StatusConflict,
+	elections.ReasonAborted:             http.StatusFailedDependency,
+	elections.ReasonInternal:            http.StatusInternalServerError,
+}
+
+// electionProblem converts errors of elections.Voting and Registry to
+// problems, the violations of a vote are listed.
+func electionProblem(err error) *problem.Problem {
+	var verr *elections.ValidationError
+	if errors.As(err, &verr) {
+		violations := make([]problem.Violation, len(verr.Violations))
+		for i, v := range verr.Violations {
+			violations[i] = problem.Violation{Field: v.Field, Reason: v.Reason}
+		}
+		return problem.Validation(violations...)
+	}
+	reason := elections.ReasonOf(err)
+	return problem.New(reasonStatuses[reason], problem.Code(reason), err.Error())
+}
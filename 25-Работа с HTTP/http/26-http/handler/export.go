@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/export"
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+)
+
+// GET /elections/{id}/export?bucket=1h
+// GET /elections/{id}/export?bucket=1d&format=csv&view=totals
+// Totals per candidate and a time series of the votes, see export.Report.
+// The bucket is 1h by default. With format=csv the view, series by default,
+// is written as CSV, format=json forces JSON, without a format the report
+// is negotiated like other responses.
+func (s *Service) ExportElection(w http.ResponseWriter, r *http.Request) {
+	id, ok := electionIdFromPath(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	var violations []problem.Violation
+	bucket := export.Bucket(time.Hour)
+	if raw := query.Get("bucket"); raw != "" {
+		var err error
+		if bucket, err = export.ParseBucket(raw); err != nil {
+			violations = append(violations, problem.Violation{Field: "bucket", Reason: err.Error()})
+		}
+	}
+	format := query.Get("format")
+	if format != "" && format != "csv" && format != "json" {
+		violations = append(violations, problem.Violation{Field: "format", Reason: "expect csv or json"})
+	}
+	view := export.View(query.Get("view"))
+	switch view {
+	case "":
+		view = export.ViewSeries
+	case export.ViewSeries, export.ViewTotals:
+	default:
+		violations = append(violations, problem.Violation{Field: "view", Reason: fmt.Sprintf("expect %s or %s", export.ViewSeries, export.ViewTotals)})
+	}
+	if len(violations) > 0 {
+		writeProblem(w, r, problem.Validation(violations...))
+		return
+	}
+
+	e, err := s.Elections.Get(id)
+	if err != nil {
+		writeElectionError(w, r, err)
+		return
+	}
+	report, err := export.Build(r.Context(), s.Store, export.Options{
+		ElectionId: e.Id,
+		Bucket:     bucket,
+		Candidates: e.Candidates,
+		Now:        s.Voting.Now,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "unable to export votes", "election_id", e.Id, "err", err)
+		writeProblem(w, r, problem.New(http.StatusInternalServerError, problem.CodeInternal, "unable to export votes"))
+		return
+	}
+	for i, t := range report.Totals {
+		if c, err := s.Candidates.Get(t.CandidateId); err == nil {
+			report.Totals[i].Name = c.Name
+		}
+	}
+
+	if format != "csv" {
+		if format == "json" {
+			// the format wins over Accept
+			r = r.Clone(r.Context())
+			r.Header.Set("Accept", "application/json")
+		}
+		s.writeResponse(w, r, http.StatusOK, &Response{Data: report})
+		return
+	}
+	var buf bytes.Buffer
+	if err := export.WriteCSV(&buf, report, view); err != nil {
+		slog.ErrorContext(r.Context(), "unable to write export", "election_id", e.Id, "err", err)
+		writeProblem(w, r, problem.New(http.StatusInternalServerError, problem.CodeInternal, "unable to write export"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="election-%d-%s.csv"`, e.Id, view))
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		slog.ErrorContext(r.Context(), "export write error", "err", err)
+	}
+}
@@ -1,209 +1,515 @@
 package handler
 
 import (
-	"encoding/json"
+	"context"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
-)
-
-const defaultInterval = 2 * time.Second
-
-var (
-	upgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024}
+	"github.com/OtusGolang/webinars_practical_part/26-http/codec"
+	"github.com/OtusGolang/webinars_practical_part/26-http/middleware"
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/OtusGolang/webinars_practical_part/26-http/router"
+	"github.com/OtusGolang/webinars_practical_part/26-http/store"
+	"github.com/OtusGolang/webinars_practical_part/26-http/wshub"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	electionspb "github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/metrics"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/receipts"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// Response wraps successful results, errors are sent as problem.Problem.
+// In protobuf the data is sent without the wrapper.
 type Response struct {
-	Data  interface{} `json:"data"`
-	Error struct {
-		Message string `json:"message"`
-	} `json:"error"`
+	XMLName xml.Name    `json:"-" xml:"response"`
+	Data    interface{} `json:"data" xml:"data"`
 }
 
+// MarshalProto returns the protobuf form of the data, if it has one.
+func (r *Response) MarshalProto() proto.Message {
+	if m, ok := r.Data.(codec.ProtoMarshaler); ok {
+		return m.MarshalProto()
+	}
+	return nil
+}
+
+// VoteRequest is read from JSON, XML or the protobuf Vote message of
+// 27-grpc/api/elections/v1.
 type VoteRequest struct {
-	Passport    string    `json:"passport,omitempty"`
-	CandidateId uint32    `json:"candidate_id,omitempty"`
-	Note        string    `json:"note,omitempty"`
-	Time        time.Time `json:"time,omitempty"`
+	XMLName     xml.Name  `json:"-" xml:"vote"`
+	ElectionId  uint32    `json:"election_id,omitempty" xml:"election_id,omitempty"`
+	Passport    string    `json:"passport,omitempty" xml:"passport,omitempty"`
+	CandidateId uint32    `json:"candidate_id,omitempty" xml:"candidate_id,omitempty"`
+	Note        string    `json:"note,omitempty" xml:"note,omitempty"`
+	Time        time.Time `json:"time,omitempty" xml:"time,omitempty"`
+}
+
+func (req *VoteRequest) NewProto() proto.Message {
+	return &electionspb.Vote{}
+}
+
+func (req *VoteRequest) UnmarshalProto(m proto.Message) error {
+	vote := m.(*electionspb.Vote)
+	req.ElectionId = vote.GetElectionId()
+	req.Passport = vote.GetPassport()
+	req.CandidateId = vote.GetCandidateId()
+	req.Note = vote.GetNote()
+	if vote.GetTime() != nil {
+		req.Time = vote.GetTime().AsTime()
+	}
+	return nil
+}
+
+// vote returns the vote for elections.Voting.
+func (req *VoteRequest) vote() elections.Vote {
+	return elections.Vote{
+		ElectionId:  req.ElectionId,
+		Passport:    req.Passport,
+		CandidateId: elections.CandidateID(req.CandidateId),
+		Note:        req.Note,
+		Time:        req.Time,
+	}
 }
 
+// StatResponse is sent as JSON, XML or the protobuf Stats message of
+// 27-grpc/api/elections/v1. XML has no maps, the records are only
+// listed per candidate there.
 type StatResponse struct {
-	Records map[uint32]uint32 `json:"records,omitempty"`
-	Time    time.Time         `json:"time,omitempty"`
+	ElectionId uint32           `json:"election_id,omitempty" xml:"election_id,omitempty"`
+	Status     elections.Status `json:"status,omitempty" xml:"status,omitempty"`
+	// Final is set once the election is closed and the stats can't change.
+	Final      bool                    `json:"final,omitempty" xml:"final,omitempty"`
+	Records    map[uint32]uint32       `json:"records,omitempty" xml:"-"`
+	Candidates []StatCandidateResponse `json:"candidates,omitempty" xml:"candidates>candidate,omitempty"`
+	Time       time.Time               `json:"time,omitempty" xml:"time,omitempty"`
+}
+
+func (resp *StatResponse) MarshalProto() proto.Message {
+	return &electionspb.Stats{
+		ElectionId: resp.ElectionId,
+		Final:      resp.Final,
+		Records:    resp.Records,
+		Time:       timestamppb.New(resp.Time),
+	}
 }
 
 type StatCandidateResponse struct {
-	CandidateId uint32    `json:"candidate_id,omitempty"`
-	Stat        uint32    `json:"stat"`
-	Time        time.Time `json:"time,omitempty"`
+	CandidateId uint32    `json:"candidate_id,omitempty" xml:"candidate_id,omitempty"`
+	Name        string    `json:"name,omitempty" xml:"name,omitempty"`
+	Party       string    `json:"party,omitempty" xml:"party,omitempty"`
+	Stat        uint32    `json:"stat" xml:"stat"`
+	Time        time.Time `json:"time,omitempty" xml:"time,omitempty"`
 }
 
 type Service struct {
-	sync.RWMutex
-	Stats    map[uint32]uint32
-	Interval time.Duration
+	Store      store.VoteStore
+	Candidates *candidates.Registry
+	Elections  *elections.Registry
+	// Voting applies the rules to votes and counts them in Store, its
+	// Audit, Receipts and Now are set before serving. The audit record and
+	// receipt of a vote are sent to the voter.
+	Voting *elections.Voting
+	// StatCoalesce is passed to the stats hub of every election, see
+	// wshub.Hub.Coalesce.
+	StatCoalesce time.Duration
+	// Auth turns on role checks in Routes, see Routes for the roles.
+	Auth *auth.Authenticator
+	// VoteMiddlewares wrap POST /vote in Routes, like rate limits. They run
+	// after the auth check.
+	VoteMiddlewares []router.Middleware
+	// BatchMiddlewares wrap POST /votes:batch in Routes, they run once per
+	// batch after the auth check and must not read the body.
+	BatchMiddlewares []router.Middleware
+	// BatchLimit limits every line of POST /votes:batch, like the passport
+	// limit of VoteMiddlewares, see middleware.LimitEach. A line over the
+	// limit is rejected with its problem and the batch goes on, nil turns
+	// it off.
+	BatchLimit func(r *http.Request, v *VoteRequest) *problem.Problem
+	// Metrics counts votes and stats streams, nil turns it off.
+	Metrics *metrics.Metrics
+	// Codecs read request bodies by Content-Type and write responses by
+	// Accept, see codec.Default.
+	Codecs *codec.Registry
+
+	hubsLock   sync.Mutex
+	hubs       map[uint32]*electionHub
+	hubsClosed bool
+}
+
+// electionHub pushes stats of one election, final fires a last broadcast
+// when the election closes so subscribers get the final stats.
+type electionHub struct {
+	*wshub.Hub
+	final *time.Timer
+}
+
+func NewService(voteStore store.VoteStore, candidateRegistry *candidates.Registry, electionRegistry *elections.Registry) *Service {
+	s := &Service{
+		Codecs:     codec.Default(),
+		Store:      voteStore,
+		Candidates: candidateRegistry,
+		Elections:  electionRegistry,
+		Voting:     elections.NewVoting(candidateRegistry, electionRegistry, storeTally{voteStore}),
+		hubs:       make(map[uint32]*electionHub),
+	}
+	s.Voting.Notify = func(e elections.Election) { s.hub(e).Notify() }
+	return s
 }
 
-func NewService() *Service {
-	return &Service{
-		Stats:    make(map[uint32]uint32),
-		Interval: defaultInterval,
+// LoadVoters restores already voted passports from the store, so
+// a restart doesn't let anyone vote twice. Votes of elections that are
+// no longer configured are skipped.
+func (s *Service) LoadVoters(ctx context.Context) error {
+	skipped := 0
+	err := s.Store.Votes(ctx, func(v store.Vote) error {
+		err := s.Elections.Restore(v.ElectionId, v.Passport, v.CandidateId)
+		if errors.Is(err, elections.ErrNotFound) {
+			skipped++
+			return nil
+		}
+		return err
+	})
+	if skipped > 0 {
+		slog.WarnContext(ctx, "votes of unknown elections skipped", "votes", skipped)
 	}
+	return err
 }
 
 func (s *Service) SubmitVote(w http.ResponseWriter, r *http.Request) {
-	resp := &Response{}
-	if r.Method != http.MethodPost {
-		resp.Error.Message = fmt.Sprintf("method %s not not supported on uri %s", r.Method, r.URL.Path)
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		WriteResponse(w, resp)
+	req := &VoteRequest{}
+	if p := s.decode(w, r, req); p != nil {
+		s.countVote(0, p.Code)
+		writeProblem(w, r, p)
 		return
 	}
-	buf := make([]byte, r.ContentLength)
-	_, err := r.Body.Read(buf)
-	if err != nil && err != io.EOF {
-		resp.Error.Message = err.Error()
-		w.WriteHeader(http.StatusBadRequest)
-		WriteResponse(w, resp)
+	receipt, p := s.submit(r.Context(), req)
+	if p != nil {
+		writeProblem(w, r, p)
 		return
 	}
-
-	req := &VoteRequest{}
-	err = json.Unmarshal(buf, req)
-	if err != nil {
-		resp.Error.Message = err.Error()
-		w.WriteHeader(http.StatusBadRequest)
-		WriteResponse(w, resp)
+	if receipt == nil {
+		w.WriteHeader(http.StatusOK)
 		return
 	}
+	s.writeResponse(w, r, http.StatusOK, &Response{Data: receipt})
+}
 
-	// validate field
-	if req.Passport == "" || req.CandidateId == 0 {
-		slog.Warn("invalid arguments, skip vote")
-		resp.Error.Message = "passport or candidate_id wrong"
-		w.WriteHeader(http.StatusBadRequest)
-		WriteResponse(w, resp)
-		return
+// submit checks and stores the vote, it is counted in Metrics either way.
+// The receipt is nil if neither Audit nor Receipts of Voting is set.
+func (s *Service) submit(ctx context.Context, req *VoteRequest) (*receipts.Receipt, *problem.Problem) {
+	slog.InfoContext(ctx, "new vote receive", "election_id", req.ElectionId, "passport", req.Passport, "candidate_id", req.CandidateId, "time", req.Time)
+
+	receipt, err := s.Voting.Submit(ctx, req.vote())
+	if err != nil {
+		p := s.submitProblem(ctx, err)
+		s.countVote(req.CandidateId, p.Code)
+		return nil, p
 	}
 
-	slog.Info("new vote receive", "passport", req.Passport, "candidate_id", req.CandidateId, "time", req.Time)
+	slog.InfoContext(ctx, "vote accepted")
+	s.countVote(req.CandidateId, "")
+	return receipt, nil
+}
 
-	s.Lock()
-	s.Stats[req.CandidateId]++
-	s.Unlock()
+// submitProblem logs why the vote was rejected, errors of the store are
+// not sent to the voter.
+func (s *Service) submitProblem(ctx context.Context, err error) *problem.Problem {
+	if elections.ReasonOf(err) == elections.ReasonInternal {
+		slog.ErrorContext(ctx, "unable to store vote", "err", err)
+		return problem.New(http.StatusInternalServerError, problem.CodeInternal, "unable to store vote")
+	}
+	slog.WarnContext(ctx, "vote is not accepted, skip vote", "err", err)
+	return electionProblem(err)
+}
 
-	slog.Info("vote accepted")
-	w.WriteHeader(http.StatusOK)
+// countVote records the vote in Metrics, an empty reason means accepted.
+// The candidate of a vote rejected before the candidate was checked is not
+// trusted and recorded as unknown.
+func (s *Service) countVote(candidateId uint32, reason problem.Code) {
+	if s.Metrics == nil {
+		return
+	}
+	switch reason {
+	case problem.CodeInvalidRequest, problem.CodeUnsupportedMedia, problem.CodeValidation, problem.CodeCandidateNotAllowed:
+		candidateId = 0
+	}
+	s.Metrics.Vote(candidateId, string(reason))
 }
 
+// curl 0.0.0.0:8080/stat?election_id=1
+// curl 0.0.0.0:8080/stat?election_id=1&candidate_id=1
 func (s *Service) GetStats(w http.ResponseWriter, r *http.Request) {
-	resp := &Response{}
-	if r.Method != http.MethodGet {
-		resp.Error.Message = fmt.Sprintf("method %s not not supported on uri %s", r.Method, r.URL.Path)
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		WriteResponse(w, resp)
+	election, ok := s.electionFromQuery(w, r)
+	if !ok {
 		return
 	}
-	args := r.URL.Query()
-	id := args.Get("candidate_id")
+	s.writeStats(w, r, election)
+}
+
+func (s *Service) writeStats(w http.ResponseWriter, r *http.Request, election elections.Election) {
+	resp := &Response{}
+	// the candidate comes either from the path or from the query
+	id := r.PathValue("candidate")
+	if id == "" {
+		id = r.URL.Query().Get("candidate_id")
+	}
 	if len(id) > 0 {
 		candidateId, err := strconv.Atoi(id)
 		if err != nil {
-			resp.Error.Message = fmt.Sprintf("cant parse candidate_id, expect int, got: %s ", id)
-			w.WriteHeader(http.StatusBadRequest)
-			WriteResponse(w, resp)
+			writeProblem(w, r, problem.Validation(problem.Violation{Field: "candidate_id", Reason: fmt.Sprintf("expect int, got: %s", id)}))
 			return
 		}
 
-		s.Lock()
-		stat, ok := s.Stats[uint32(candidateId)]
-		s.Unlock()
-		slog.Info("candidate found", "found", ok)
-		if !ok {
-			resp.Error.Message = fmt.Sprintf("candidate with id %d doasn't found", candidateId)
-			w.WriteHeader(http.StatusBadRequest)
-			WriteResponse(w, resp)
+		stat, ok, err := s.Store.CandidateStat(r.Context(), election.Id, uint32(candidateId))
+		if err != nil {
+			slog.ErrorContext(r.Context(), "unable to get candidate stat", "err", err)
+			writeProblem(w, r, problem.New(http.StatusInternalServerError, problem.CodeInternal, "unable to get stats"))
+			return
+		}
+		onBallot := election.HasCandidate(uint32(candidateId))
+		slog.InfoContext(r.Context(), "candidate found", "found", ok || onBallot)
+		if !ok && !onBallot {
+			writeProblem(w, r, problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, fmt.Sprintf("candidate with id %d doasn't found in election %d", candidateId, election.Id)))
 			return
 		}
 
+		candidate, _ := s.Candidates.Get(uint32(candidateId))
 		resp.Data = &StatCandidateResponse{
 			CandidateId: uint32(candidateId),
+			Name:        candidate.Name,
+			Party:       candidate.Party,
 			Stat:        stat,
-			Time:        time.Now(),
+			Time:        s.Voting.Now(),
 		}
 
-		w.WriteHeader(http.StatusOK)
-		WriteResponse(w, resp)
+		s.writeResponse(w, r, http.StatusOK, resp)
 		return
 	}
 
-	s.Lock()
-	stats := s.Stats
-	s.Unlock()
-
-	resp.Data = &StatResponse{
-		Records: stats,
-		Time:    time.Now(),
+	stat, err := s.statSnapshot(r.Context(), election.Id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "unable to get stats", "err", err)
+		writeProblem(w, r, problem.New(http.StatusInternalServerError, problem.CodeInternal, "unable to get stats"))
+		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	WriteResponse(w, resp)
+	resp.Data = stat
+
+	s.writeResponse(w, r, http.StatusOK, resp)
 }
 
 // websocket handler
+//
+// curl 0.0.0.0:8080/stat-stream?election_id=1
 func (s *Service) StatStream(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		http.Error(w, err.Error(), 500)
+	election, ok := s.electionFromQuery(w, r)
+	if !ok {
 		return
 	}
-
-	slog.Info("web socket connected", "remote_addr", conn.RemoteAddr())
-	go s.writeLoop(conn)
+	s.hub(election).ServeWS(w, r)
 }
 
-func (s *Service) writeLoop(c *websocket.Conn) {
-	defer func() {
-		slog.Info("web socket closed")
-	}()
-	for {
-		s.Lock()
-		r := s.Stats
-		s.Unlock()
-		stat := &StatResponse{
-			Records: r,
-			Time:    time.Now(),
-		}
+// CloseStreams disconnects stats subscribers of every election, see
+// wshub.Hub.Close.
+func (s *Service) CloseStreams(ctx context.Context) error {
+	s.hubsLock.Lock()
+	s.hubsClosed = true
+	hubs := make([]*electionHub, 0, len(s.hubs))
+	for _, h := range s.hubs {
+		hubs = append(hubs, h)
+	}
+	s.hubsLock.Unlock()
 
-		msg, err := json.Marshal(stat)
-		if err != nil {
-			c.Close()
-			break
+	var errs []error
+	for _, h := range hubs {
+		if h.final != nil {
+			h.final.Stop()
 		}
+		errs = append(errs, h.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
 
-		err = c.WriteMessage(websocket.TextMessage, msg)
-		if err != nil {
-			c.Close()
-			break
-		}
-		time.Sleep(s.Interval)
+// hub returns the stats hub of the election, creating it on first use.
+func (s *Service) hub(election elections.Election) *wshub.Hub {
+	s.hubsLock.Lock()
+	defer s.hubsLock.Unlock()
+
+	if h, ok := s.hubs[election.Id]; ok {
+		return h.Hub
+	}
+
+	id := election.Id
+	h := &electionHub{Hub: wshub.NewHub(func(ctx context.Context) (any, error) {
+		return s.statSnapshot(ctx, id)
+	})}
+	h.Coalesce = s.StatCoalesce
+	if s.Metrics != nil {
+		h.Gauge = s.Metrics.StatsStreams(metrics.TransportHTTP)
+	}
+	if s.hubsClosed {
+		// late subscribers after shutdown get ErrClosed from the hub
+		h.Close(context.Background())
+		return h.Hub
+	}
+	s.scheduleFinal(h, election)
+	s.hubs[id] = h
+	return h.Hub
+}
+
+// rescheduleFinal moves the final broadcast after the election window
+// has changed.
+func (s *Service) rescheduleFinal(election elections.Election) {
+	s.hubsLock.Lock()
+	defer s.hubsLock.Unlock()
+
+	if h, ok := s.hubs[election.Id]; ok && !s.hubsClosed {
+		s.scheduleFinal(h, election)
+	}
+}
+
+func (s *Service) scheduleFinal(h *electionHub, election elections.Election) {
+	if h.final != nil {
+		h.final.Stop()
+		h.final = nil
+	}
+	if wait := election.ClosesAt.Sub(s.Voting.Now()); wait > 0 {
+		h.final = time.AfterFunc(wait, h.Notify)
+	}
+}
+
+func (s *Service) statSnapshot(ctx context.Context, electionId uint32) (*StatResponse, error) {
+	stats, err := s.Voting.Stats(ctx, electionId)
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[uint32]uint32, len(stats.Records))
+	for k, v := range stats.Records {
+		records[uint32(k)] = v
+	}
+	return s.statResponse(stats.Election, stats.Time, records), nil
+}
+
+// statResponse puts candidate names next to the counts. Candidates on the
+// ballot without votes are listed with zero.
+func (s *Service) statResponse(election elections.Election, now time.Time, stats map[uint32]uint32) *StatResponse {
+	status := election.Status(now)
+	resp := &StatResponse{
+		ElectionId: election.Id,
+		Status:     status,
+		Final:      status == elections.StatusClosed,
+		Records:    stats,
+		Candidates: make([]StatCandidateResponse, 0, len(election.Candidates)),
+		Time:       now,
 	}
+
+	for _, id := range election.Candidates {
+		c, _ := s.Candidates.Get(id)
+		resp.Candidates = append(resp.Candidates, StatCandidateResponse{
+			CandidateId: id,
+			Name:        c.Name,
+			Party:       c.Party,
+			Stat:        stats[id],
+		})
+	}
+	sort.Slice(resp.Candidates, func(i, j int) bool {
+		return resp.Candidates[i].CandidateId < resp.Candidates[j].CandidateId
+	})
+	return resp
 }
 
-func WriteResponse(w http.ResponseWriter, resp *Response) {
-	resBuf, err := json.Marshal(resp)
+// electionFromQuery reads the required election_id argument and writes
+// the error response if it is missing or unknown.
+func (s *Service) electionFromQuery(w http.ResponseWriter, r *http.Request) (elections.Election, bool) {
+	id := r.URL.Query().Get("election_id")
+	electionId, err := strconv.ParseUint(id, 10, 32)
+	if err != nil || electionId == 0 {
+		writeProblem(w, r, problem.Validation(problem.Violation{Field: "election_id", Reason: fmt.Sprintf("expect positive int, got: %s", id)}))
+		return elections.Election{}, false
+	}
+
+	election, err := s.Elections.Get(uint32(electionId))
 	if err != nil {
-		slog.Error("responce marshal error", "err", err)
+		writeElectionError(w, r, err)
+		return elections.Election{}, false
+	}
+	return election, true
+}
+
+// NotFound is the router response for unknown paths.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, problem.New(http.StatusNotFound, problem.CodeNotFound, fmt.Sprintf("uri %s not found", r.URL.Path)))
+}
+
+// MethodNotAllowed is the router response for known paths requested with
+// an unsupported method, the router sets the Allow header.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, problem.New(http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed,
+		fmt.Sprintf("method %s not supported on uri %s", r.Method, r.URL.Path)))
+}
+
+// writeProblem sends p tagged with the id of the request it answers.
+func writeProblem(w http.ResponseWriter, r *http.Request, p *problem.Problem) {
+	p.RequestId = middleware.RequestId(r.Context())
+	problem.Write(w, r, p)
+}
+
+// maxBody bounds a request body read by decode.
+const maxBody = 1 << 20
+
+// decode reads the body into v with the codec of the Content-Type, JSON
+// if there is none. A body over maxBody gets a 413 problem.
+func (s *Service) decode(w http.ResponseWriter, r *http.Request, v any) *problem.Problem {
+	c, err := s.Codecs.ForContentType(r.Header.Get("Content-Type"), v)
+	if err != nil {
+		return problem.New(http.StatusUnsupportedMediaType, problem.CodeUnsupportedMedia, err.Error())
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return problem.New(http.StatusRequestEntityTooLarge, problem.CodeInvalidRequest,
+			fmt.Sprintf("body exceeds %d bytes", tooLarge.Limit))
+	}
+	if err == nil {
+		err = c.Unmarshal(body, v)
+	}
+	if err != nil {
+		return problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+// writeResponse sends resp with the status in the encoding preferred by
+// the Accept header. Headers are set before the status is written, after
+// that they are ignored.
+func (s *Service) writeResponse(w http.ResponseWriter, r *http.Request, status int, resp *Response) {
+	c, err := s.Codecs.ForAccept(r.Header.Get("Accept"), resp)
+	if err != nil {
+		writeProblem(w, r, problem.New(http.StatusNotAcceptable, problem.CodeNotAcceptable, err.Error()))
+		return
+	}
+	resBuf, err := c.Marshal(resp)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "responce marshal error", "err", err)
+		writeProblem(w, r, problem.New(http.StatusInternalServerError, problem.CodeInternal, "unable to marshal response"))
+		return
 	}
+	w.Header().Set("Content-Type", c.ContentType())
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
 	_, err = w.Write(resBuf)
 	if err != nil {
-		slog.Error("responce marshal error", "err", err)
+		slog.ErrorContext(r.Context(), "responce write error", "err", err)
 	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 }
@@ -1,16 +1,39 @@
 package handler
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/OtusGolang/webinars_practical_part/26-http/export"
+	"github.com/OtusGolang/webinars_practical_part/26-http/middleware"
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/OtusGolang/webinars_practical_part/26-http/store"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/electionstest"
+	electionspb "github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/metrics"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/ratelimit"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/receipts"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestService_SubmitVote(t *testing.T) {
-	service := NewService()
+	service := newTestService(t)
 
 	cases := []struct {
 		name         string
@@ -21,10 +44,73 @@ func TestService_SubmitVote(t *testing.T) {
 	}{
 		{"bad_request", http.MethodPost, "http://test.test/vote", nil, http.StatusBadRequest},
 		{
-			"ok",
+			"no_election",
 			http.MethodPost,
 			"http://test.test/vote",
 			bytes.NewBufferString(`{"candidate_id": 1, "passport": "test"}`),
+			http.StatusBadRequest,
+		},
+		{
+			"unknown_election",
+			http.MethodPost,
+			"http://test.test/vote",
+			bytes.NewBufferString(`{"election_id": 9, "candidate_id": 1, "passport": "test"}`),
+			http.StatusNotFound,
+		},
+		{
+			"not_started",
+			http.MethodPost,
+			"http://test.test/vote",
+			bytes.NewBufferString(`{"election_id": 2, "candidate_id": 1, "passport": "test"}`),
+			http.StatusForbidden,
+		},
+		{
+			"finished",
+			http.MethodPost,
+			"http://test.test/vote",
+			bytes.NewBufferString(`{"election_id": 3, "candidate_id": 1, "passport": "test"}`),
+			http.StatusForbidden,
+		},
+		{
+			"not_on_ballot",
+			http.MethodPost,
+			"http://test.test/vote",
+			bytes.NewBufferString(`{"election_id": 4, "candidate_id": 1, "passport": "test"}`),
+			http.StatusBadRequest,
+		},
+		{
+			"ok",
+			http.MethodPost,
+			"http://test.test/vote",
+			bytes.NewBufferString(`{"election_id": 1, "candidate_id": 1, "passport": "test"}`),
+			http.StatusOK,
+		},
+		{
+			"unknown_candidate",
+			http.MethodPost,
+			"http://test.test/vote",
+			bytes.NewBufferString(`{"election_id": 1, "candidate_id": 7, "passport": "test2"}`),
+			http.StatusBadRequest,
+		},
+		{
+			"inactive_candidate",
+			http.MethodPost,
+			"http://test.test/vote",
+			bytes.NewBufferString(`{"election_id": 1, "candidate_id": 3, "passport": "test2"}`),
+			http.StatusBadRequest,
+		},
+		{
+			"already_voted",
+			http.MethodPost,
+			"http://test.test/vote",
+			bytes.NewBufferString(`{"election_id": 1, "candidate_id": 2, "passport": " TEST "}`),
+			http.StatusConflict,
+		},
+		{
+			"other_election",
+			http.MethodPost,
+			"http://test.test/vote",
+			bytes.NewBufferString(`{"election_id": 4, "candidate_id": 2, "passport": "test"}`),
 			http.StatusOK,
 		},
 	}
@@ -39,3 +125,935 @@ func TestService_SubmitVote(t *testing.T) {
 		})
 	}
 }
+
+func TestService_SubmitVoteProblem(t *testing.T) {
+	service := newTestService(t)
+	h := middleware.NewLogger(http.HandlerFunc(service.SubmitVote))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(`{"passport": "a"}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	err := problem.FromResponse(w.Result())
+	require.ErrorIs(t, err, problem.ErrValidation)
+	p, _ := problem.As(err)
+	require.Equal(t, []problem.Violation{
+		{Field: "election_id", Reason: "is required"},
+		{Field: "candidate_id", Reason: "is required"},
+	}, p.Violations)
+	require.NotEmpty(t, p.RequestId)
+	require.Equal(t, w.Header().Get("X-Request-Id"), p.RequestId)
+
+	w = httptest.NewRecorder()
+	body := `{"election_id": 2, "candidate_id": 1, "passport": "a"}`
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(body)))
+	require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrElectionNotStarted)
+}
+
+func TestService_SubmitVoteTooLarge(t *testing.T) {
+	service := newTestService(t)
+	body := `{"election_id": 1, "candidate_id": 1, "passport": "a", "note": "` + strings.Repeat("n", maxBody) + `"}`
+	w := httptest.NewRecorder()
+	service.SubmitVote(w, httptest.NewRequest(http.MethodPost, "/vote", strings.NewReader(body)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	p, ok := problem.As(problem.FromResponse(w.Result()))
+	require.True(t, ok)
+	require.Equal(t, problem.CodeInvalidRequest, p.Code)
+}
+
+func newTestService(t *testing.T) *Service {
+	registry := candidates.NewRegistry()
+	for _, c := range []candidates.Candidate{
+		{Id: 1, Name: "Alice", Party: "Green", Active: true},
+		{Id: 2, Name: "Bob", Active: true},
+		{Id: 3, Name: "Carol"},
+	} {
+		_, err := registry.Create(c)
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	electionRegistry := elections.NewRegistry()
+	for _, e := range []elections.Election{
+		{Id: 1, Title: "open", Candidates: []uint32{1, 2, 3}, OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour)},
+		{Id: 2, Title: "scheduled", Candidates: []uint32{1, 2}, OpensAt: now.Add(time.Hour), ClosesAt: now.Add(2 * time.Hour)},
+		{Id: 3, Title: "closed", Candidates: []uint32{1, 2}, OpensAt: now.Add(-2 * time.Hour), ClosesAt: now.Add(-time.Hour)},
+		{Id: 4, Title: "second round", Candidates: []uint32{2}, OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour)},
+	} {
+		_, err := electionRegistry.Create(e)
+		require.NoError(t, err)
+	}
+	return NewService(store.NewMemory(), registry, electionRegistry)
+}
+
+func TestService_GetStats(t *testing.T) {
+	service := newTestService(t)
+	for _, body := range []string{
+		`{"election_id": 1, "candidate_id": 1, "passport": "a"}`,
+		`{"election_id": 1, "candidate_id": 1, "passport": "b"}`,
+		`{"election_id": 1, "candidate_id": 2, "passport": "c"}`,
+	} {
+		w := httptest.NewRecorder()
+		service.SubmitVote(w, httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	service.GetStats(w, httptest.NewRequest(http.MethodGet, "/stat", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	service.GetStats(w, httptest.NewRequest(http.MethodGet, "/stat?election_id=1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data StatResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []StatCandidateResponse{
+		{CandidateId: 1, Name: "Alice", Party: "Green", Stat: 2},
+		{CandidateId: 2, Name: "Bob", Stat: 1},
+		{CandidateId: 3, Name: "Carol", Stat: 0},
+	}, resp.Data.Candidates)
+	require.Equal(t, elections.StatusOpen, resp.Data.Status)
+	require.False(t, resp.Data.Final)
+
+	w = httptest.NewRecorder()
+	service.GetStats(w, httptest.NewRequest(http.MethodGet, "/stat?election_id=4", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	resp.Data = StatResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []StatCandidateResponse{{CandidateId: 2, Name: "Bob", Stat: 0}}, resp.Data.Candidates)
+}
+
+func TestService_ElectionClose(t *testing.T) {
+	service := newTestService(t)
+	// the hub of the election reads the clock from its own goroutine
+	var now atomic.Pointer[time.Time]
+	start := time.Now()
+	now.Store(&start)
+	service.Voting.Now = func() time.Time { return *now.Load() }
+
+	w := httptest.NewRecorder()
+	body := `{"election_id": 1, "candidate_id": 1, "passport": "a"}`
+	service.SubmitVote(w, httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	later := start.Add(time.Hour)
+	now.Store(&later)
+	w = httptest.NewRecorder()
+	body = `{"election_id": 1, "candidate_id": 2, "passport": "b"}`
+	service.SubmitVote(w, httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(body)))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	service.GetStats(w, httptest.NewRequest(http.MethodGet, "/stat?election_id=1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data StatResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, elections.StatusClosed, resp.Data.Status)
+	require.True(t, resp.Data.Final)
+	require.Equal(t, map[uint32]uint32{1: 1}, resp.Data.Records)
+}
+
+func TestService_Elections(t *testing.T) {
+	service := newTestService(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /elections", service.ListElections)
+	mux.HandleFunc("POST /elections", service.CreateElection)
+	mux.HandleFunc("GET /elections/{id}", service.GetElection)
+	mux.HandleFunc("PUT /elections/{id}", service.UpdateElection)
+	mux.HandleFunc("GET /elections/{id}/stats", service.GetElectionStats)
+
+	window := func(from, to time.Duration) string {
+		now := time.Now()
+		return fmt.Sprintf(`"opens_at": %q, "closes_at": %q`,
+			now.Add(from).Format(time.RFC3339), now.Add(to).Format(time.RFC3339))
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		responseCode int
+	}{
+		{"create", http.MethodPost, "/elections", `{"title": "council", "candidates": [1, 2], ` + window(time.Hour, 2*time.Hour) + `}`, http.StatusCreated},
+		{"create_exists", http.MethodPost, "/elections", `{"id": 1, "title": "council", "candidates": [1], ` + window(time.Hour, 2*time.Hour) + `}`, http.StatusConflict},
+		{"create_unknown_candidate", http.MethodPost, "/elections", `{"title": "council", "candidates": [9], ` + window(time.Hour, 2*time.Hour) + `}`, http.StatusBadRequest},
+		{"create_bad_window", http.MethodPost, "/elections", `{"title": "council", "candidates": [1], ` + window(time.Hour, -time.Hour) + `}`, http.StatusBadRequest},
+		{"get", http.MethodGet, "/elections/5", "", http.StatusOK},
+		{"get_unknown", http.MethodGet, "/elections/9", "", http.StatusNotFound},
+		{"update_scheduled", http.MethodPut, "/elections/5", `{"title": "council", "candidates": [1], ` + window(time.Hour, 3*time.Hour) + `}`, http.StatusOK},
+		{"update_open", http.MethodPut, "/elections/1", `{"title": "open", "candidates": [1], ` + window(-time.Hour, time.Hour) + `}`, http.StatusConflict},
+		{"stats", http.MethodGet, "/elections/1/stats", "", http.StatusOK},
+		{"stats_bad_id", http.MethodGet, "/elections/abc/stats", "", http.StatusBadRequest},
+		{"list", http.MethodGet, "/elections", "", http.StatusOK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(c.method, c.target, bytes.NewBufferString(c.body)))
+			require.Equal(t, c.responseCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestService_Candidates(t *testing.T) {
+	service := newTestService(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /candidates", service.ListCandidates)
+	mux.HandleFunc("POST /candidates", service.CreateCandidate)
+	mux.HandleFunc("GET /candidates/{id}", service.GetCandidate)
+	mux.HandleFunc("PUT /candidates/{id}", service.UpdateCandidate)
+	mux.HandleFunc("DELETE /candidates/{id}", service.DeleteCandidate)
+
+	cases := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		responseCode int
+	}{
+		{"create", http.MethodPost, "/candidates", `{"name": "Dave", "active": true}`, http.StatusCreated},
+		{"create_exists", http.MethodPost, "/candidates", `{"id": 1, "name": "Dave"}`, http.StatusConflict},
+		{"create_no_name", http.MethodPost, "/candidates", `{"party": "Green"}`, http.StatusBadRequest},
+		{"get", http.MethodGet, "/candidates/4", "", http.StatusOK},
+		{"get_bad_id", http.MethodGet, "/candidates/abc", "", http.StatusBadRequest},
+		{"update", http.MethodPut, "/candidates/4", `{"name": "Dave", "active": false}`, http.StatusOK},
+		{"update_unknown", http.MethodPut, "/candidates/9", `{"name": "Dave"}`, http.StatusNotFound},
+		{"delete", http.MethodDelete, "/candidates/4", "", http.StatusNoContent},
+		{"get_deleted", http.MethodGet, "/candidates/4", "", http.StatusNotFound},
+		{"list", http.MethodGet, "/candidates", "", http.StatusOK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(c.method, c.target, bytes.NewBufferString(c.body)))
+			require.Equal(t, c.responseCode, w.Code)
+		})
+	}
+}
+
+func TestService_RoutesAuth(t *testing.T) {
+	service := newTestService(t)
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	require.NoError(t, err)
+	service.Auth = auth.NewAuthenticator(secret)
+	routes := service.Routes()
+
+	token := func(roles ...auth.Role) string {
+		tok, err := service.Auth.Sign(auth.Principal{Subject: "alice", Roles: roles}, time.Hour)
+		require.NoError(t, err)
+		return "Bearer " + tok
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		target        string
+		body          string
+		authorization string
+		exp           *problem.Problem
+	}{
+		{"vote_anonymous", http.MethodPost, "/vote", `{"election_id": 1, "candidate_id": 1, "passport": "a"}`, "", problem.ErrUnauthorized},
+		{"vote_observer", http.MethodPost, "/vote", `{"election_id": 1, "candidate_id": 1, "passport": "a"}`, token(auth.RoleObserver), problem.ErrForbidden},
+		{"vote_voter", http.MethodPost, "/vote", `{"election_id": 1, "candidate_id": 1, "passport": "a"}`, token(auth.RoleVoter), nil},
+		{"vote_bad_token", http.MethodPost, "/vote", `{"election_id": 1, "candidate_id": 1, "passport": "b"}`, "Bearer abc", problem.ErrUnauthorized},
+		{"stats_voter", http.MethodGet, "/elections/1/stats", "", token(auth.RoleVoter), problem.ErrForbidden},
+		{"stats_observer", http.MethodGet, "/elections/1/stats", "", token(auth.RoleObserver), nil},
+		{"stats_admin", http.MethodGet, "/stat?election_id=1", "", token(auth.RoleAdmin), nil},
+		{"list_public", http.MethodGet, "/candidates", "", "", nil},
+		{"create_observer", http.MethodPost, "/candidates", `{"name": "Dave"}`, token(auth.RoleObserver), problem.ErrForbidden},
+		{"create_admin", http.MethodPost, "/candidates", `{"name": "Dave"}`, token(auth.RoleAdmin), nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.target, bytes.NewBufferString(c.body))
+			if c.authorization != "" {
+				req.Header.Set("Authorization", c.authorization)
+			}
+			w := httptest.NewRecorder()
+			routes.ServeHTTP(w, req)
+
+			if c.exp == nil {
+				require.Less(t, w.Code, 300, w.Body.String())
+				return
+			}
+			require.ErrorIs(t, problem.FromResponse(w.Result()), c.exp)
+			if c.exp == problem.ErrUnauthorized {
+				require.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestService_StatEvents(t *testing.T) {
+	service := newTestService(t)
+	ts := httptest.NewServer(http.HandlerFunc(service.StatEvents))
+	defer ts.Close()
+	defer service.CloseStreams(context.Background())
+
+	res, err := http.Get(ts.URL + "?election_id=1&candidate_id=2")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	readEvent := func(events *bufio.Reader) map[string]string {
+		fields := map[string]string{}
+		for {
+			line, err := events.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				if _, ok := fields["data"]; ok {
+					return fields
+				}
+				continue
+			}
+			name, value, _ := strings.Cut(line, ": ")
+			fields[name] = value
+		}
+	}
+
+	events := bufio.NewReader(res.Body)
+	first := readEvent(events)
+	require.Equal(t, "candidate", first["event"])
+	require.JSONEq(t, `{"candidate_id":2,"name":"Bob","stat":0}`, withoutTime(t, first["data"]))
+
+	vote := func(body string) {
+		w := httptest.NewRecorder()
+		service.SubmitVote(w, httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+		time.Sleep(50 * time.Millisecond)
+	}
+	// a vote for another candidate doesn't change the filtered stream
+	vote(`{"election_id": 1, "candidate_id": 1, "passport": "a"}`)
+	vote(`{"election_id": 1, "candidate_id": 2, "passport": "b"}`)
+
+	second := readEvent(events)
+	require.NotEqual(t, first["id"], second["id"])
+	require.JSONEq(t, `{"candidate_id":2,"name":"Bob","stat":1}`, withoutTime(t, second["data"]))
+
+	// a client resuming at the current stats only gets the changes of its
+	// candidate
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"?election_id=1&candidate_id=2", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", second["id"])
+	resumed, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resumed.Body.Close()
+
+	vote(`{"election_id": 1, "candidate_id": 1, "passport": "c"}`)
+	vote(`{"election_id": 1, "candidate_id": 2, "passport": "d"}`)
+	third := readEvent(bufio.NewReader(resumed.Body))
+	require.JSONEq(t, `{"candidate_id":2,"name":"Bob","stat":2}`, withoutTime(t, third["data"]))
+}
+
+func TestService_Metrics(t *testing.T) {
+	service := newTestService(t)
+	service.Metrics = metrics.New()
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		service.Metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return w.Body.String()
+	}
+
+	for _, body := range []string{
+		`{"election_id": 1, "candidate_id": 1, "passport": "a"}`,
+		`{"election_id": 1, "candidate_id": 2, "passport": "a"}`,
+		`{"election_id": 3, "candidate_id": 1, "passport": "b"}`,
+		`{"election_id": 1, "candidate_id": 777, "passport": "c"}`,
+		`{"passport": "d"}`,
+	} {
+		service.SubmitVote(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(body)))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(service.StatEvents))
+	defer ts.Close()
+	res, err := http.Get(ts.URL + "?election_id=1")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body := scrape()
+	for _, exp := range []string{
+		`elections_votes_total{candidate_id="1",reason="",result="accepted"} 1`,
+		`elections_votes_total{candidate_id="2",reason="already_voted",result="rejected"} 1`,
+		`elections_votes_total{candidate_id="1",reason="election_closed",result="rejected"} 1`,
+		`elections_votes_total{candidate_id="unknown",reason="candidate_not_allowed",result="rejected"} 1`,
+		`elections_votes_total{candidate_id="unknown",reason="validation_failed",result="rejected"} 1`,
+		`elections_stats_streams_active{transport="http"} 1`,
+	} {
+		require.Contains(t, body, exp)
+	}
+
+	require.NoError(t, service.CloseStreams(context.Background()))
+	require.Contains(t, scrape(), `elections_stats_streams_active{transport="http"} 0`)
+}
+
+func withoutTime(t *testing.T, data string) string {
+	m := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(data), &m))
+	delete(m, "time")
+	buf, err := json.Marshal(m)
+	require.NoError(t, err)
+	return string(buf)
+}
+
+func TestService_Codecs(t *testing.T) {
+	service := newTestService(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /vote", service.SubmitVote)
+	mux.HandleFunc("GET /stat", service.GetStats)
+	mux.HandleFunc("POST /candidates", service.CreateCandidate)
+	mux.HandleFunc("GET /elections/{id}", service.GetElection)
+	do := func(method, target, contentType, accept string, body []byte) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, bytes.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	w := do(http.MethodPost, "/vote", "application/xml", "application/xml",
+		[]byte(`<vote><election_id>1</election_id><candidate_id>1</candidate_id><passport>a</passport></vote>`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	vote, err := proto.Marshal(&electionspb.Vote{ElectionId: 1, CandidateId: 2, Passport: "b"})
+	require.NoError(t, err)
+	w = do(http.MethodPost, "/vote", "application/x-protobuf", "", vote)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = do(http.MethodGet, "/stat?election_id=1", "", "application/x-protobuf", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/x-protobuf", w.Header().Get("Content-Type"))
+	stats := &electionspb.Stats{}
+	require.NoError(t, proto.Unmarshal(w.Body.Bytes(), stats))
+	require.Equal(t, uint32(1), stats.ElectionId)
+	require.Equal(t, map[uint32]uint32{1: 1, 2: 1}, stats.Records)
+
+	w = do(http.MethodGet, "/stat?election_id=1", "", "application/xml", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Equal(t, "Accept", w.Header().Get("Vary"))
+	var xmlStats struct {
+		Candidates []StatCandidateResponse `xml:"data>candidates>candidate"`
+	}
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &xmlStats))
+	require.Len(t, xmlStats.Candidates, 3)
+	require.Equal(t, uint32(1), xmlStats.Candidates[0].Stat)
+
+	w = do(http.MethodGet, "/elections/1", "", "application/xml", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "<title>")
+
+	w = do(http.MethodPost, "/vote", "text/plain", "", []byte(`{"election_id": 1, "candidate_id": 1, "passport": "c"}`))
+	require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	require.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrUnsupportedMedia)
+
+	w = do(http.MethodPost, "/candidates", "application/x-protobuf", "", []byte{})
+	require.Equal(t, http.StatusUnsupportedMediaType, w.Code, "candidates have no protobuf form")
+
+	w = do(http.MethodGet, "/elections/1", "", "application/x-protobuf", nil)
+	require.Equal(t, http.StatusNotAcceptable, w.Code, "elections have no protobuf form")
+	require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrNotAcceptable)
+
+	w = do(http.MethodGet, "/stat?election_id=1", "", "text/html", nil)
+	require.Equal(t, http.StatusNotAcceptable, w.Code)
+}
+
+func TestService_SubmitVotes(t *testing.T) {
+	batch := func(t *testing.T, service *Service, query, body string) ([]BatchResult, BatchSummary) {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/votes:batch"+query, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-ndjson")
+		w := httptest.NewRecorder()
+		service.SubmitVotes(w, r)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		var results []BatchResult
+		var summary BatchSummary
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var line struct {
+				BatchResult
+				Summary *BatchSummary `json:"summary"`
+			}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			if line.Summary != nil {
+				summary = *line.Summary
+				continue
+			}
+			results = append(results, line.BatchResult)
+		}
+		return results, summary
+	}
+	codes := func(results []BatchResult) []problem.Code {
+		var codes []problem.Code
+		for _, r := range results {
+			codes = append(codes, r.Code)
+		}
+		return codes
+	}
+	stats := func(service *Service) map[uint32]uint32 {
+		stats, err := service.Store.Stats(context.Background(), 1)
+		require.NoError(t, err)
+		return stats
+	}
+
+	t.Run("lines", func(t *testing.T) {
+		service := newTestService(t)
+		results, summary := batch(t, service, "", strings.Join([]string{
+			`{"election_id": 1, "candidate_id": 1, "passport": "a"}`,
+			`not json`,
+			``,
+			`{"election_id": 1, "candidate_id": 2, "passport": "a"}`,
+			`{"election_id": 1, "candidate_id": 3, "passport": "b"}`,
+			`{"election_id": 2, "candidate_id": 1, "passport": "b"}`,
+			`{"election_id": 1, "candidate_id": 2, "passport": "c"}`,
+		}, "\n"))
+		require.Equal(t, []problem.Code{
+			"", problem.CodeInvalidRequest, problem.CodeAlreadyVoted,
+			problem.CodeCandidateNotAllowed, problem.CodeElectionNotStarted, "",
+		}, codes(results))
+		require.Equal(t, []int{1, 2, 4, 5, 6, 7}, []int{results[0].Line, results[1].Line, results[2].Line, results[3].Line, results[4].Line, results[5].Line})
+		require.Equal(t, http.StatusConflict, results[2].Status)
+		require.Equal(t, BatchSummary{Accepted: 2, Rejected: 4}, summary)
+		require.Equal(t, map[uint32]uint32{1: 1, 2: 1}, stats(service))
+	})
+
+	t.Run("atomic_aborted", func(t *testing.T) {
+		service := newTestService(t)
+		results, summary := batch(t, service, "?atomic=true", strings.Join([]string{
+			`{"election_id": 1, "candidate_id": 1, "passport": "a"}`,
+			`{"election_id": 1, "candidate_id": 2, "passport": "A"}`,
+			`{"election_id": 4, "candidate_id": 2, "passport": "b"}`,
+			`{"election_id": 1, "candidate_id": 2, "passport": "c"}`,
+		}, "\n"))
+		require.Equal(t, []problem.Code{
+			problem.CodeBatchAborted, problem.CodeBatchAborted, problem.CodeValidation, problem.CodeBatchAborted,
+		}, codes(results))
+		require.Equal(t, http.StatusFailedDependency, results[0].Status)
+		require.Equal(t, BatchSummary{Rejected: 4, Atomic: true}, summary)
+
+		// passports are checked once every vote is admitted
+		results, summary = batch(t, service, "?atomic=true", strings.Join([]string{
+			`{"election_id": 1, "candidate_id": 1, "passport": "a"}`,
+			`{"election_id": 1, "candidate_id": 2, "passport": "A"}`,
+		}, "\n"))
+		require.Equal(t, []problem.Code{problem.CodeBatchAborted, problem.CodeAlreadyVoted}, codes(results))
+		require.Equal(t, BatchSummary{Rejected: 2, Atomic: true}, summary)
+		require.Empty(t, stats(service))
+
+		// nothing of the aborted batch is remembered
+		results, summary = batch(t, service, "?atomic=1", `{"election_id": 1, "candidate_id": 1, "passport": "a"}`)
+		require.Equal(t, []problem.Code{""}, codes(results))
+		require.Equal(t, BatchSummary{Accepted: 1, Atomic: true}, summary)
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		service := newTestService(t)
+		_, err := service.Elections.Create(elections.Election{
+			Id: 6, Title: "revote", Candidates: []uint32{1, 2}, AllowRevote: true,
+			OpensAt: time.Now().Add(-time.Hour), ClosesAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		_, summary := batch(t, service, "?atomic=true", strings.Join([]string{
+			`{"election_id": 6, "candidate_id": 1, "passport": "a"}`,
+			`{"election_id": 6, "candidate_id": 2, "passport": "b"}`,
+			`{"election_id": 6, "candidate_id": 2, "passport": "a"}`,
+		}, "\n"))
+		require.Equal(t, BatchSummary{Accepted: 3, Atomic: true}, summary)
+		stats, err := service.Store.Stats(context.Background(), 6)
+		require.NoError(t, err)
+		require.Equal(t, map[uint32]uint32{1: 0, 2: 2}, stats)
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		service := newTestService(t)
+		limiter, err := ratelimit.NewMemory(ratelimit.TokenBucket, ratelimit.PerMinute(1))
+		require.NoError(t, err)
+		service.BatchLimit = middleware.LimitEach(limiter, func(v *VoteRequest) string { return "passport:" + v.Passport })
+		results, summary := batch(t, service, "", strings.Join([]string{
+			`{"election_id": 1, "candidate_id": 1, "passport": "a"}`,
+			`{"election_id": 1, "candidate_id": 2, "passport": "a"}`,
+			`{"election_id": 1, "candidate_id": 2, "passport": "b"}`,
+		}, "\n"))
+		require.Equal(t, []problem.Code{"", problem.CodeRateLimited, ""}, codes(results))
+		require.Equal(t, http.StatusTooManyRequests, results[1].Status)
+		require.Equal(t, BatchSummary{Accepted: 2, Rejected: 1}, summary)
+
+		// in an atomic batch a limited line aborts the others
+		results, _ = batch(t, service, "?atomic=true", strings.Join([]string{
+			`{"election_id": 1, "candidate_id": 1, "passport": "c"}`,
+			`{"election_id": 1, "candidate_id": 1, "passport": "b"}`,
+		}, "\n"))
+		require.Equal(t, []problem.Code{problem.CodeBatchAborted, problem.CodeRateLimited}, codes(results))
+		require.Equal(t, map[uint32]uint32{1: 1, 2: 1}, stats(service))
+	})
+
+	t.Run("negotiation", func(t *testing.T) {
+		service := newTestService(t)
+		r := httptest.NewRequest(http.MethodPost, "/votes:batch", strings.NewReader(`{}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		service.SubmitVotes(w, r)
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		r = httptest.NewRequest(http.MethodPost, "/votes:batch", strings.NewReader(`{}`))
+		r.Header.Set("Accept", "application/xml")
+		w = httptest.NewRecorder()
+		service.SubmitVotes(w, r)
+		require.Equal(t, http.StatusNotAcceptable, w.Code)
+
+		r = httptest.NewRequest(http.MethodPost, "/votes:batch?atomic=maybe", strings.NewReader(`{}`))
+		w = httptest.NewRecorder()
+		service.SubmitVotes(w, r)
+		require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrValidation)
+	})
+}
+
+// TestService_SubmitVotesStreaming reads results while the body is still
+// uploading, over HTTP/1 that needs full duplex.
+func TestService_SubmitVotesStreaming(t *testing.T) {
+	service := newTestService(t)
+	srv := httptest.NewServer(service.Routes())
+	defer srv.Close()
+
+	body, upload := io.Pipe()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/votes:batch", body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	send := func(from, to int) {
+		for i := from; i < to; i++ {
+			_, err := fmt.Fprintf(upload, `{"election_id": 1, "candidate_id": 1, "passport": "p%d"}`+"\n", i)
+			require.NoError(t, err)
+		}
+	}
+	go send(0, batchFlush)
+	client := &http.Client{Timeout: 10 * time.Second}
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	results := bufio.NewScanner(res.Body)
+	require.True(t, results.Scan(), "first results come before the body ends")
+	require.JSONEq(t, `{"line": 1, "status": 200}`, results.Text())
+
+	send(batchFlush, batchFlush+10)
+	require.NoError(t, upload.Close())
+	lines := 1
+	for results.Scan() {
+		lines++
+	}
+	require.Equal(t, batchFlush+10+1, lines, "results and the summary")
+}
+
+func TestService_ExportElection(t *testing.T) {
+	service := newTestService(t)
+	for _, body := range []string{
+		`{"election_id": 1, "candidate_id": 1, "passport": "a", "time": "2024-09-08T08:10:00Z"}`,
+		`{"election_id": 1, "candidate_id": 2, "passport": "b", "time": "2024-09-08T09:20:00Z"}`,
+		`{"election_id": 1, "candidate_id": 1, "passport": "c", "time": "2024-09-08T09:40:00Z"}`,
+	} {
+		w := httptest.NewRecorder()
+		service.SubmitVote(w, httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	routes := service.Routes()
+	get := func(target, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("/elections/1/export?bucket=1h", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data export.Report `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []export.Total{
+		{CandidateId: 1, Name: "Alice", Votes: 2},
+		{CandidateId: 2, Name: "Bob", Votes: 1},
+		{CandidateId: 3, Name: "Carol", Votes: 0},
+	}, resp.Data.Totals)
+	require.Len(t, resp.Data.Series, 3)
+
+	w = get("/elections/1/export?bucket=1d&format=csv&view=totals", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="election-1-totals.csv"`, w.Header().Get("Content-Disposition"))
+	require.Equal(t, "election_id,candidate_id,name,votes\n1,1,Alice,2\n1,2,Bob,1\n1,3,Carol,0\n", w.Body.String())
+
+	w = get("/elections/1/export?format=csv", "")
+	require.Equal(t, `election_id,bucket_start,bucket,candidate_id,votes,total
+1,2024-09-08T08:00:00Z,1h,1,1,1
+1,2024-09-08T09:00:00Z,1h,1,1,2
+1,2024-09-08T09:00:00Z,1h,2,1,1
+`, w.Body.String())
+
+	w = get("/elections/1/export?format=json", "application/xml")
+	require.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	w = get("/elections/1/export", "application/xml")
+	require.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = get("/elections/1/export?bucket=1w&format=pdf&view=pie", "")
+	err := problem.FromResponse(w.Result())
+	require.ErrorIs(t, err, problem.ErrValidation)
+	p, _ := problem.As(err)
+	require.Len(t, p.Violations, 3)
+	w = get("/elections/9/export", "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestService_Audit(t *testing.T) {
+	service := newTestService(t)
+	routes := service.Routes()
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		if strings.HasSuffix(target, ":batch") {
+			r.Header.Set("Content-Type", "application/x-ndjson")
+		}
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, r)
+		return w
+	}
+
+	w := do(http.MethodGet, "/audit/head", "")
+	require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrNotFound, "audit is off")
+	w = do(http.MethodPost, "/vote", `{"election_id": 1, "candidate_id": 1, "passport": "a"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Body.String(), "no receipt while audit is off")
+
+	service.Voting.Audit = audit.NewMemory([]byte("test-key"))
+	w = do(http.MethodGet, "/audit/head", "")
+	require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrNotFound, "audit log is empty")
+
+	w = do(http.MethodPost, "/vote", `{"election_id": 1, "candidate_id": 2, "passport": "b"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data receipts.Receipt `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Empty(t, resp.Data.Signature, "receipts are not signed")
+	require.NotNil(t, resp.Data.Audit)
+	receipt := *resp.Data.Audit
+	require.Equal(t, uint64(0), receipt.Index)
+
+	// rejected votes get no record
+	w = do(http.MethodPost, "/vote", `{"election_id": 1, "candidate_id": 1, "passport": "b"}`)
+	require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrAlreadyVoted)
+
+	w = do(http.MethodPost, "/votes:batch", `{"election_id": 1, "candidate_id": 1, "passport": "c"}
+{"election_id": 1, "candidate_id": 1, "passport": "c"}
+`)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	var accepted, rejected BatchResult
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &accepted))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &rejected))
+	require.Equal(t, uint64(1), accepted.Receipt.Audit.Index)
+	require.Nil(t, rejected.Receipt)
+
+	w = do(http.MethodGet, "/audit/head", "")
+	var head struct {
+		Data audit.Receipt `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &head))
+	require.Equal(t, *accepted.Receipt.Audit, head.Data)
+
+	w = do(http.MethodGet, fmt.Sprintf("/audit/proof?index=%d&hash=%s", receipt.Index, receipt.Hash), "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var proof struct {
+		Data audit.Proof `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &proof))
+	require.NoError(t, proof.Data.Verify(receipt))
+	require.Equal(t, head.Data, proof.Data.Head)
+	require.Equal(t, uint32(2), proof.Data.Record.CandidateId)
+	require.NotContains(t, w.Body.String(), `"b"`, "the passport is not published")
+
+	w = do(http.MethodGet, fmt.Sprintf("/audit/proof?index=0&hash=%s", head.Data.Hash), "")
+	require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrConflict)
+	w = do(http.MethodGet, "/audit/proof?index=7&hash=00", "")
+	require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrNotFound)
+	w = do(http.MethodGet, "/audit/proof?index=x", "")
+	err := problem.FromResponse(w.Result())
+	require.ErrorIs(t, err, problem.ErrValidation)
+	p, _ := problem.As(err)
+	require.Len(t, p.Violations, 2)
+}
+
+func TestService_Receipts(t *testing.T) {
+	service := newTestService(t)
+	routes := service.Routes()
+	vote := func(passport string) receipts.Receipt {
+		r := httptest.NewRequest(http.MethodPost, "/vote", strings.NewReader(
+			fmt.Sprintf(`{"election_id": 1, "candidate_id": 2, "passport": %q, "time": "2024-09-08T08:10:00Z"}`, passport)))
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Data receipts.Receipt `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+	keys := func() []receipts.Key {
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/receipts/keys", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NotContains(t, w.Body.String(), "private_key")
+		var resp struct {
+			Data []receipts.Key `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/receipts/keys", nil))
+	require.ErrorIs(t, problem.FromResponse(w.Result()), problem.ErrNotFound)
+
+	k1, err := receipts.GenerateKey("k1")
+	require.NoError(t, err)
+	service.Voting.Receipts, err = receipts.NewKeyring([]receipts.Key{k1})
+	require.NoError(t, err)
+	first := vote("a")
+	require.Equal(t, "k1", first.KeyId)
+	require.Equal(t, uint32(1), first.ElectionId)
+	require.Equal(t, uint32(2), first.CandidateId)
+	require.Equal(t, "2024-09-08T08:10:00Z", first.Time.Format(time.RFC3339))
+	require.NotEmpty(t, first.Nonce)
+	require.Nil(t, first.Audit)
+
+	// rotation: k1 is retired, k2 signs
+	k2, err := receipts.GenerateKey("k2")
+	require.NoError(t, err)
+	service.Voting.Receipts, err = receipts.NewKeyring([]receipts.Key{{Id: k1.Id, PublicKey: k1.PublicKey}, k2})
+	require.NoError(t, err)
+	service.Voting.Audit = audit.NewMemory([]byte("test-key"))
+	second := vote("b")
+	require.Equal(t, "k2", second.KeyId)
+	require.NotNil(t, second.Audit)
+
+	published := keys()
+	require.Equal(t, []receipts.Key{
+		{Id: "k1", PublicKey: k1.PublicKey},
+		{Id: "k2", PublicKey: k2.PublicKey, Signing: true},
+	}, published)
+	offline, err := receipts.NewKeyring(published)
+	require.NoError(t, err)
+	require.NoError(t, offline.Verify(&first))
+	require.NoError(t, offline.Verify(&second))
+	second.CandidateId = 1
+	require.ErrorIs(t, offline.Verify(&second), receipts.ErrBadSignature)
+}
+
+// suiteClient votes through the routes of the service, see electionstest.
+type suiteClient struct {
+	routes http.Handler
+}
+
+func (c suiteClient) do(t *testing.T, method, target string, body any) *httptest.ResponseRecorder {
+	var r io.Reader
+	switch b := body.(type) {
+	case string:
+		r = strings.NewReader(b)
+	case nil:
+	default:
+		data, err := json.Marshal(b)
+		require.NoError(t, err)
+		r = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, target, r)
+	if strings.HasPrefix(target, "/votes:batch") {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	w := httptest.NewRecorder()
+	c.routes.ServeHTTP(w, req)
+	return w
+}
+
+func suiteVote(v elections.Vote) *VoteRequest {
+	return &VoteRequest{ElectionId: v.ElectionId, Passport: v.Passport, CandidateId: uint32(v.CandidateId), Note: v.Note}
+}
+
+// reason returns the problem code of a failed response.
+func (c suiteClient) reason(t *testing.T, w *httptest.ResponseRecorder) elections.Reason {
+	if w.Code == http.StatusOK {
+		return elections.ReasonNone
+	}
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p), w.Body.String())
+	return elections.Reason(p.Code)
+}
+
+func (c suiteClient) Submit(t *testing.T, vote elections.Vote) elections.Reason {
+	return c.reason(t, c.do(t, http.MethodPost, "/vote", suiteVote(vote)))
+}
+
+func (c suiteClient) SubmitAll(t *testing.T, votes []elections.Vote) []elections.Reason {
+	var body strings.Builder
+	for _, v := range votes {
+		data, err := json.Marshal(suiteVote(v))
+		require.NoError(t, err)
+		body.Write(data)
+		body.WriteString("\n")
+	}
+	w := c.do(t, http.MethodPost, "/votes:batch?atomic=true", body.String())
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	reasons := make([]elections.Reason, 0, len(votes))
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var res BatchResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &res))
+		if res.Line == 0 {
+			break // the summary
+		}
+		reasons = append(reasons, elections.Reason(res.Code))
+	}
+	return reasons
+}
+
+func (c suiteClient) Stats(t *testing.T, electionId uint32) (map[elections.CandidateID]uint32, bool, elections.Reason) {
+	w := c.do(t, http.MethodGet, fmt.Sprintf("/stat?election_id=%d", electionId), nil)
+	if reason := c.reason(t, w); reason != elections.ReasonNone {
+		return nil, false, reason
+	}
+	var resp struct {
+		Data StatResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	records := make(map[elections.CandidateID]uint32, len(resp.Data.Records))
+	for k, v := range resp.Data.Records {
+		records[elections.CandidateID(k)] = v
+	}
+	return records, resp.Data.Final, elections.ReasonNone
+}
+
+func TestService_Conformance(t *testing.T) {
+	electionstest.Run(t, func(t *testing.T, c *candidates.Registry, r *elections.Registry) electionstest.Client {
+		return suiteClient{routes: NewService(store.NewMemory(), c, r).Routes()}
+	})
+}
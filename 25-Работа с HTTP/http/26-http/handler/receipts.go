@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+)
+
+// GET /receipts/keys
+// The public keys receipts are signed with, retired ones included, to
+// check receipts offline, see 27-grpc/receipts/verify-receipt.
+func (s *Service) ReceiptKeys(w http.ResponseWriter, r *http.Request) {
+	if s.Voting.Receipts == nil {
+		writeProblem(w, r, problem.New(http.StatusNotFound, problem.CodeNotFound, "receipts are not signed"))
+		return
+	}
+	s.writeResponse(w, r, http.StatusOK, &Response{Data: s.Voting.Receipts.PublicKeys()})
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/middleware"
+	"github.com/OtusGolang/webinars_practical_part/26-http/router"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+)
+
+// Routes returns the router with every endpoint of the service. With Auth
+// set votes need the voter role, stats the observer role, the audit log
+// either of them and changes of candidates and elections the admin role,
+// reading them stays public.
+func (s *Service) Routes() *router.Router {
+	r := router.New()
+	r.NotFound = http.HandlerFunc(NotFound)
+	r.MethodNotAllowed = http.HandlerFunc(MethodNotAllowed)
+
+	voter := s.allow(auth.RoleVoter)
+	observer := s.allow(auth.RoleObserver)
+	admin := s.allow(auth.RoleAdmin)
+
+	r.HandleFunc("POST /vote", s.SubmitVote, append(voter, s.VoteMiddlewares...)...)
+	// VoteMiddlewares read the whole body, a batch streams it and limits
+	// every line with BatchLimit
+	r.HandleFunc("POST /votes:batch", s.SubmitVotes, append(voter, s.BatchMiddlewares...)...)
+	// also serves /stat/, the router ignores the trailing slash
+	r.HandleFunc("GET /stat", s.GetStats, observer...)
+	// websocket handler
+	r.HandleFunc("GET /stat-stream", s.StatStream, observer...)
+	// server-sent events for clients behind proxies without websocket support
+	r.HandleFunc("GET /stat-events", s.StatEvents, observer...)
+	r.Route("/candidates", func(g *router.Group) {
+		g.HandleFunc("GET /", s.ListCandidates)
+		g.HandleFunc("POST /", s.CreateCandidate, admin...)
+		g.HandleFunc("GET /{id:uint}", s.GetCandidate)
+		g.HandleFunc("PUT /{id:uint}", s.UpdateCandidate, admin...)
+		g.HandleFunc("DELETE /{id:uint}", s.DeleteCandidate, admin...)
+	})
+	r.Route("/elections", func(g *router.Group) {
+		g.HandleFunc("GET /", s.ListElections)
+		g.HandleFunc("POST /", s.CreateElection, admin...)
+		g.HandleFunc("GET /{id:uint}", s.GetElection)
+		g.HandleFunc("PUT /{id:uint}", s.UpdateElection, admin...)
+		g.HandleFunc("GET /{id:uint}/stats", s.GetElectionStats, observer...)
+		g.HandleFunc("GET /{id:uint}/stats/{candidate:uint}", s.GetElectionStats, observer...)
+		g.HandleFunc("GET /{id:uint}/export", s.ExportElection, observer...)
+	})
+	// voters check their receipts, observers the whole log
+	r.Route("/audit", func(g *router.Group) {
+		voterOrObserver := s.allow(auth.RoleVoter, auth.RoleObserver)
+		g.HandleFunc("GET /head", s.AuditHead, voterOrObserver...)
+		g.HandleFunc("GET /proof", s.AuditProof, voterOrObserver...)
+	})
+	// public, receipts are checked offline
+	r.HandleFunc("GET /receipts/keys", s.ReceiptKeys)
+	return r
+}
+
+// allow returns the middleware checking the roles, none if Auth is off.
+func (s *Service) allow(roles ...auth.Role) []router.Middleware {
+	if s.Auth == nil {
+		return nil
+	}
+	return []router.Middleware{middleware.Authorize(s.Auth, roles...)}
+}
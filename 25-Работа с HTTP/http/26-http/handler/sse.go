@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+)
+
+const (
+	sseHeartbeat = 15 * time.Second
+	sseRetry     = 3 * time.Second
+)
+
+// StatEvents streams stats as Server-Sent Events for clients that can't use
+// the websocket stream. Every event is a full snapshot, so a client resuming
+// with Last-Event-ID only gets the current state if it missed something.
+// With &candidate_id=N only that candidate is sent and only when it changes.
+//
+// curl -N 0.0.0.0:8080/stat-events?election_id=1
+// curl -N 0.0.0.0:8080/stat-events?election_id=1&candidate_id=1
+func (s *Service) StatEvents(w http.ResponseWriter, r *http.Request) {
+	election, ok := s.electionFromQuery(w, r)
+	if !ok {
+		return
+	}
+	hub := s.hub(election)
+
+	var candidateId uint32
+	if id := r.URL.Query().Get("candidate_id"); id != "" {
+		parsed, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			writeProblem(w, r, problem.Validation(problem.Violation{Field: "candidate_id", Reason: fmt.Sprintf("expect int, got: %s", id)}))
+			return
+		}
+		candidateId = uint32(parsed)
+	}
+
+	rc := http.NewResponseController(w)
+	// the stream outlives any server write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(r.Context(), "unable to reset write deadline", "err", err)
+	}
+
+	sub, err := hub.Subscribe()
+	if err != nil {
+		writeProblem(w, r, problem.New(http.StatusServiceUnavailable, problem.CodeUnavailable, err.Error()))
+		return
+	}
+	defer sub.Close()
+
+	current, err := hub.Current(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "unable to build stats snapshot", "err", err)
+		writeProblem(w, r, problem.New(http.StatusInternalServerError, problem.CodeInternal, "unable to get stats"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+
+	slog.InfoContext(r.Context(), "stats event stream connected", "remote_addr", r.RemoteAddr, "election_id", election.Id, "candidate_id", candidateId)
+	defer slog.InfoContext(r.Context(), "stats event stream closed", "remote_addr", r.RemoteAddr)
+
+	var lastSent *StatCandidateResponse
+	send := func(id string, stat *StatResponse) error {
+		if candidateId == 0 {
+			return writeEvent(w, id, "stats", stat)
+		}
+		cs := candidateStat(stat, candidateId)
+		if lastSent != nil && lastSent.Stat == cs.Stat {
+			return nil
+		}
+		lastSent = &cs
+		return writeEvent(w, id, "candidate", cs)
+	}
+
+	if r.Header.Get("Last-Event-ID") != current.Id {
+		if err := send(current.Id, current.Value.(*StatResponse)); err != nil {
+			return
+		}
+	} else if candidateId != 0 {
+		// the client has the current stat, only changes to it are sent
+		cs := candidateStat(current.Value.(*StatResponse), candidateId)
+		lastSent = &cs
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case e, ok := <-sub.C:
+			if !ok {
+				// hub is shutting down or we were too slow
+				return
+			}
+			if err := send(e.Id, e.Value.(*StatResponse)); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func candidateStat(stat *StatResponse, candidateId uint32) StatCandidateResponse {
+	for _, c := range stat.Candidates {
+		if c.CandidateId == candidateId {
+			c.Time = stat.Time
+			return c
+		}
+	}
+	return StatCandidateResponse{CandidateId: candidateId, Time: stat.Time}
+}
+
+func writeEvent(w http.ResponseWriter, id, event string, data any) error {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, event, buf)
+	return err
+}
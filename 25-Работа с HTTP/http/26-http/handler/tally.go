@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/store"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+)
+
+// storeTally counts the votes of elections.Voting in a store.VoteStore.
+// Votes are added with store.AddAll, so they are kept all or none only
+// by a store.Batcher.
+type storeTally struct {
+	store store.VoteStore
+}
+
+func (t storeTally) Add(ctx context.Context, votes ...elections.Vote) error {
+	batch := make([]store.Vote, len(votes))
+	for i, v := range votes {
+		batch[i] = store.Vote{
+			ElectionId:  v.ElectionId,
+			Passport:    v.Passport,
+			CandidateId: uint32(v.CandidateId),
+			Note:        v.Note,
+			Time:        v.Time,
+			Replaces:    uint32(v.Replaces),
+		}
+	}
+	if len(batch) == 1 {
+		return t.store.Add(ctx, batch[0])
+	}
+	return store.AddAll(ctx, t.store, batch)
+}
+
+func (t storeTally) Count(ctx context.Context, electionId uint32) (map[elections.CandidateID]uint32, error) {
+	stats, err := t.store.Stats(ctx, electionId)
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[elections.CandidateID]uint32, len(stats))
+	for k, v := range stats {
+		records[elections.CandidateID(k)] = v
+	}
+	return records, nil
+}
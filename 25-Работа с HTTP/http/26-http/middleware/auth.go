@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+)
+
+// Authorize lets through requests whose Authorization header carries a
+// principal with one of the roles, any principal if roles are empty. The
+// principal is put into the request context, see auth.FromContext.
+// Missing or bad credentials get 401, a missing role gets 403.
+func Authorize(a *auth.Authenticator, roles ...auth.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := a.Authenticate(r.Header.Get("Authorization"))
+			if err == nil && !p.HasRole(roles...) {
+				err = auth.ErrForbidden
+			}
+			if err != nil {
+				writeAuthProblem(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), p)))
+		})
+	}
+}
+
+func writeAuthProblem(w http.ResponseWriter, r *http.Request, err error) {
+	var p *problem.Problem
+	if errors.Is(err, auth.ErrForbidden) {
+		p = problem.New(http.StatusForbidden, problem.CodeForbidden, "the caller has no role allowed here")
+	} else {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="elections"`)
+		p = problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, err.Error())
+	}
+	p.RequestId = RequestId(r.Context())
+	problem.Write(w, r, p)
+}
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerFailures    = 5
+	defaultBreakerOpenTimeout = 30 * time.Second
+	defaultBreakerProbes      = 1
+)
+
+// ErrCircuitOpen is returned without sending the request while the
+// breaker of the host is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// BreakerPolicy configures BreakerRoundTripper, zero fields take defaults.
+type BreakerPolicy struct {
+	// FailureThreshold is the number of failures in a row that opens the
+	// breaker, 5 by default.
+	FailureThreshold int
+	// OpenTimeout is how long requests are rejected before probing the
+	// host again, 30s by default.
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of requests let through in half-open
+	// state, all of them have to succeed to close the breaker. 1 by default.
+	HalfOpenProbes int
+	// IsFailure decides if the outcome counts as a failure, by default
+	// transport errors and 5xx statuses.
+	IsFailure func(res *http.Response, err error) bool
+	OnEvent   EventHook
+}
+
+// BreakerRoundTripper stops sending requests to a host that keeps failing,
+// so the host gets time to recover and callers fail fast.
+type BreakerRoundTripper struct {
+	rt     http.RoundTripper
+	policy BreakerPolicy
+	now    func() time.Time
+
+	lock  sync.Mutex
+	hosts map[string]*breaker
+}
+
+type breaker struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// probes in flight and succeeded in half-open state
+	probing   int
+	succeeded int
+}
+
+func NewBreakerRoundTripper(rt http.RoundTripper, policy BreakerPolicy) *BreakerRoundTripper {
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = defaultBreakerFailures
+	}
+	if policy.OpenTimeout <= 0 {
+		policy.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	if policy.HalfOpenProbes <= 0 {
+		policy.HalfOpenProbes = defaultBreakerProbes
+	}
+	if policy.IsFailure == nil {
+		policy.IsFailure = DefaultIsFailure
+	}
+	return &BreakerRoundTripper{
+		rt:     rt,
+		policy: policy,
+		now:    time.Now,
+		hosts:  make(map[string]*breaker),
+	}
+}
+
+func DefaultIsFailure(res *http.Response, err error) bool {
+	return err != nil || res.StatusCode >= http.StatusInternalServerError
+}
+
+// State returns the breaker state of the host, as in URL.Host.
+func (b *BreakerRoundTripper) State(host string) BreakerState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	br, ok := b.hosts[host]
+	if !ok {
+		return BreakerClosed
+	}
+	if br.state == BreakerOpen && b.now().Sub(br.openedAt) >= b.policy.OpenTimeout {
+		return BreakerHalfOpen
+	}
+	return br.state
+}
+
+func (b *BreakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	probe, err := b.allow(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := b.rt.RoundTrip(req)
+	if req.Context().Err() != nil {
+		// a canceled caller says nothing about the host, neither a failure
+		// nor a success
+		b.cancel(req, probe)
+		return res, err
+	}
+	b.record(req, probe, b.policy.IsFailure(res, err), res, err)
+	return res, err
+}
+
+func (b *BreakerRoundTripper) allow(req *http.Request) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	br, ok := b.hosts[req.URL.Host]
+	if !ok {
+		br = &breaker{state: BreakerClosed}
+		b.hosts[req.URL.Host] = br
+	}
+
+	if br.state == BreakerOpen {
+		if wait := b.policy.OpenTimeout - b.now().Sub(br.openedAt); wait > 0 {
+			emit(b.policy.OnEvent, Event{Kind: EventBreakerReject, Delay: wait}, req)
+			return false, fmt.Errorf("%w for %s", ErrCircuitOpen, req.URL.Host)
+		}
+		br.state = BreakerHalfOpen
+		br.probing, br.succeeded = 0, 0
+		emit(b.policy.OnEvent, Event{Kind: EventBreakerHalfOpen}, req)
+	}
+
+	if br.state == BreakerHalfOpen {
+		if br.probing+br.succeeded >= b.policy.HalfOpenProbes {
+			emit(b.policy.OnEvent, Event{Kind: EventBreakerReject, Reason: "probe in flight"}, req)
+			return false, fmt.Errorf("%w for %s", ErrCircuitOpen, req.URL.Host)
+		}
+		br.probing++
+		return true, nil
+	}
+	return false, nil
+}
+
+// cancel forgets a canceled request, a canceled probe frees its slot for
+// another one.
+func (b *BreakerRoundTripper) cancel(req *http.Request, probe bool) {
+	if !probe {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.hosts[req.URL.Host].probing--
+}
+
+func (b *BreakerRoundTripper) record(req *http.Request, probe, failed bool, res *http.Response, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	br := b.hosts[req.URL.Host]
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+
+	if probe {
+		br.probing--
+		if br.state != BreakerHalfOpen {
+			// another probe has already decided
+			return
+		}
+		if failed {
+			b.open(br, req, status, err, "probe failed")
+			return
+		}
+		br.succeeded++
+		if br.succeeded >= b.policy.HalfOpenProbes {
+			br.state = BreakerClosed
+			br.failures = 0
+			emit(b.policy.OnEvent, Event{Kind: EventBreakerClose}, req)
+		}
+		return
+	}
+
+	if br.state != BreakerClosed {
+		return
+	}
+	if !failed {
+		br.failures = 0
+		return
+	}
+	br.failures++
+	if br.failures >= b.policy.FailureThreshold {
+		b.open(br, req, status, err, fmt.Sprintf("%d failures in a row", br.failures))
+	}
+}
+
+func (b *BreakerRoundTripper) open(br *breaker, req *http.Request, status int, err error, reason string) {
+	br.state = BreakerOpen
+	br.openedAt = b.now()
+	br.failures = 0
+	emit(b.policy.OnEvent, Event{Kind: EventBreakerOpen, Delay: b.policy.OpenTimeout, Status: status, Err: err, Reason: reason}, req)
+}
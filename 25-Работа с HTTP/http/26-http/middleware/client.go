@@ -1,10 +1,15 @@
 package middleware
 
 import (
-	"fmt"
+	"log/slog"
 	"net/http"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
 )
 
+// LoggingRoundTripper logs every request and response. Requests without an
+// X-Request-ID header get the id of their context, or a new one, so the
+// server logs can be matched with the client ones.
 type LoggingRoundTripper struct {
 	rt http.RoundTripper
 }
@@ -16,14 +21,23 @@ func NewLoggingRoundTripper(rt http.RoundTripper) *LoggingRoundTripper {
 }
 
 func (lrt *LoggingRoundTripper) RoundTrip(req *http.Request) (res *http.Response, err error) {
-	fmt.Printf("Sending request to %v\n", req.URL)
+	ctx := req.Context()
+	if req.Header.Get(correlation.Header) == "" {
+		id := correlation.Outgoing(ctx)
+		ctx = correlation.NewContext(ctx, id)
+		req = req.Clone(ctx)
+		req.Header.Set(correlation.Header, id)
+	} else if correlation.FromContext(ctx) == "" {
+		ctx = correlation.NewContext(ctx, req.Header.Get(correlation.Header))
+	}
+	slog.InfoContext(ctx, "sending request", "method", req.Method, "url", req.URL)
 
 	res, err = lrt.rt.RoundTrip(req)
 
 	if err != nil {
-		fmt.Printf("Error: %v", err)
+		slog.ErrorContext(ctx, "request failed", "err", err)
 	} else {
-		fmt.Printf("Received %v response\n", res.Status)
+		slog.InfoContext(ctx, "received response", "status", res.Status)
 	}
 
 	return
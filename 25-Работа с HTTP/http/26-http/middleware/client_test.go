@@ -0,0 +1,296 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// eventLog collects hook calls, hooks may be called from several goroutines.
+type eventLog struct {
+	lock   sync.Mutex
+	events []Event
+}
+
+func (l *eventLog) hook(e Event) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.events = append(l.events, e)
+}
+
+func (l *eventLog) kinds() []EventKind {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	kinds := make([]EventKind, 0, len(l.events))
+	for _, e := range l.events {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+// statuses answers with the given statuses in turn and counts requests.
+func statuses(calls *atomic.Int32, codes ...int) roundTripFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		n := int(calls.Add(1)) - 1
+		code := codes[min(n, len(codes)-1)]
+		if r.Body != nil && r.Body != http.NoBody {
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != "vote" {
+				return nil, errors.New("body is not replayed")
+			}
+		}
+		res := &http.Response{StatusCode: code, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: r}
+		if code == http.StatusTooManyRequests {
+			res.Header.Set("Retry-After", "0")
+		}
+		return res, nil
+	}
+}
+
+func TestRetryRoundTripper(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		key      string
+		codes    []int
+		expCode  int
+		expCalls int32
+		expKinds []EventKind
+	}{
+		{"ok", http.MethodGet, "", []int{200}, 200, 1, []EventKind{}},
+		{"recovers", http.MethodGet, "", []int{503, 429, 200}, 200, 3, []EventKind{EventRetry, EventRetry}},
+		{"gives_up", http.MethodGet, "", []int{503}, 503, 3, []EventKind{EventRetry, EventRetry, EventRetryGiveUp}},
+		{"not_retryable_status", http.MethodGet, "", []int{500}, 500, 1, []EventKind{}},
+		{"post_not_retried", http.MethodPost, "", []int{503, 200}, 503, 1, []EventKind{}},
+		{"post_with_idempotency_key", http.MethodPost, "vote-1", []int{503, 200}, 200, 2, []EventKind{EventRetry}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := &atomic.Int32{}
+			log := &eventLog{}
+			rt := NewRetryRoundTripper(statuses(calls, c.codes...), RetryPolicy{
+				BaseDelay: time.Millisecond,
+				OnEvent:   log.hook,
+			})
+
+			req := httptest.NewRequest(c.method, "http://elections.test/vote", bytes.NewBufferString("vote"))
+			req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("vote")), nil }
+			if c.key != "" {
+				req.Header.Set("Idempotency-Key", c.key)
+			}
+
+			res, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+			require.Equal(t, c.expCode, res.StatusCode)
+			require.Equal(t, c.expCalls, calls.Load())
+			require.Equal(t, c.expKinds, log.kinds())
+		})
+	}
+}
+
+func TestRetryRoundTripper_RetryAfter(t *testing.T) {
+	calls := &atomic.Int32{}
+	log := &eventLog{}
+	rt := NewRetryRoundTripper(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		res := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: http.NoBody}
+		res.Header.Set("Retry-After", "120")
+		return res, nil
+	}), RetryPolicy{MaxDelay: time.Second, OnEvent: log.hook})
+
+	res, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://elections.test/stat", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	require.Equal(t, int32(1), calls.Load())
+	require.Equal(t, []EventKind{EventRetryGiveUp}, log.kinds())
+	require.Equal(t, 2*time.Minute, log.events[0].Delay)
+
+	at := time.Date(2024, 9, 8, 12, 0, 0, 0, time.UTC)
+	wait, ok := retryAfter(&http.Response{Header: http.Header{"Retry-After": {at.Format(http.TimeFormat)}}}, at.Add(-3*time.Second))
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, wait)
+}
+
+func TestBreakerRoundTripper(t *testing.T) {
+	now := time.Now()
+	failing := atomic.Bool{}
+	failing.Store(true)
+	calls := &atomic.Int32{}
+	log := &eventLog{}
+
+	rt := NewBreakerRoundTripper(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		if failing.Load() {
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), BreakerPolicy{FailureThreshold: 3, OpenTimeout: time.Minute, OnEvent: log.hook})
+	rt.now = func() time.Time { return now }
+
+	get := func(host string) error {
+		_, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://"+host+"/stat", nil))
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		require.Error(t, get("a.test"))
+	}
+	require.Equal(t, BreakerOpen, rt.State("a.test"))
+	require.ErrorIs(t, get("a.test"), ErrCircuitOpen)
+	require.Equal(t, int32(3), calls.Load())
+
+	// breakers are per host
+	require.Equal(t, BreakerClosed, rt.State("b.test"))
+	failing.Store(false)
+	require.NoError(t, get("b.test"))
+
+	// a failed probe opens the breaker again
+	failing.Store(true)
+	now = now.Add(time.Minute)
+	require.Equal(t, BreakerHalfOpen, rt.State("a.test"))
+	require.NotErrorIs(t, get("a.test"), ErrCircuitOpen)
+	require.Equal(t, BreakerOpen, rt.State("a.test"))
+
+	failing.Store(false)
+	now = now.Add(time.Minute)
+	require.NoError(t, get("a.test"))
+	require.Equal(t, BreakerClosed, rt.State("a.test"))
+
+	require.Equal(t, []EventKind{
+		EventBreakerOpen, EventBreakerReject,
+		EventBreakerHalfOpen, EventBreakerOpen,
+		EventBreakerHalfOpen, EventBreakerClose,
+	}, log.kinds())
+}
+
+func TestBreakerRoundTripper_SingleProbe(t *testing.T) {
+	now := time.Now()
+	release := make(chan struct{})
+	rt := NewBreakerRoundTripper(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/slow" {
+			<-release
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}
+		return &http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody}, nil
+	}), BreakerPolicy{FailureThreshold: 1, OpenTimeout: time.Second})
+	rt.now = func() time.Time { return now }
+
+	_, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://a.test/fail", nil))
+	require.NoError(t, err)
+	require.Equal(t, BreakerOpen, rt.State("a.test"))
+
+	now = now.Add(time.Second)
+	done := make(chan error)
+	go func() {
+		_, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://a.test/slow", nil))
+		done <- err
+	}()
+	require.Eventually(t, func() bool {
+		_, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://a.test/slow", nil))
+		return errors.Is(err, ErrCircuitOpen)
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	require.NoError(t, <-done)
+	require.Equal(t, BreakerClosed, rt.State("a.test"))
+}
+
+func TestBreakerRoundTripper_Canceled(t *testing.T) {
+	now := time.Now()
+	rt := NewBreakerRoundTripper(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if err := r.Context().Err(); err != nil {
+			return nil, err
+		}
+		if r.URL.Path == "/fail" {
+			return &http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), BreakerPolicy{FailureThreshold: 2, OpenTimeout: time.Second, HalfOpenProbes: 2})
+	rt.now = func() time.Time { return now }
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	get := func(ctx context.Context, path string) error {
+		_, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://a.test"+path, nil).WithContext(ctx))
+		return err
+	}
+
+	// a canceled request doesn't break the failures in a row
+	require.NoError(t, get(context.Background(), "/fail"))
+	require.ErrorIs(t, get(canceled, "/"), context.Canceled)
+	require.NoError(t, get(context.Background(), "/fail"))
+	require.Equal(t, BreakerOpen, rt.State("a.test"))
+
+	// a canceled probe neither closes the breaker nor holds its slot
+	now = now.Add(time.Second)
+	require.ErrorIs(t, get(canceled, "/"), context.Canceled)
+	require.ErrorIs(t, get(canceled, "/"), context.Canceled)
+	require.Equal(t, BreakerHalfOpen, rt.State("a.test"))
+	require.NoError(t, get(context.Background(), "/"))
+	require.Equal(t, BreakerHalfOpen, rt.State("a.test"))
+	require.NoError(t, get(context.Background(), "/"))
+	require.Equal(t, BreakerClosed, rt.State("a.test"))
+}
+
+func TestHedgeRoundTripper(t *testing.T) {
+	calls := &atomic.Int32{}
+	canceled := make(chan struct{})
+	log := &eventLog{}
+	rt := NewHedgeRoundTripper(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			// the first request hangs until the hedge wins
+			<-r.Context().Done()
+			close(canceled)
+			return nil, r.Context().Err()
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("hedged"))}, nil
+	}), HedgePolicy{Delay: 10 * time.Millisecond, OnEvent: log.hook})
+
+	res, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://elections.test/stat", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hedged", string(body))
+	require.NoError(t, res.Body.Close())
+
+	<-canceled
+	require.Equal(t, int32(2), calls.Load())
+	require.Equal(t, []EventKind{EventHedge, EventHedgeWin}, log.kinds())
+	require.Equal(t, 2, log.events[1].Attempt)
+
+	// non idempotent requests are sent once
+	calls.Store(1)
+	req := httptest.NewRequest(http.MethodPost, "http://elections.test/vote", nil)
+	res, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestHedgeRoundTripper_Fast(t *testing.T) {
+	calls := &atomic.Int32{}
+	rt := NewHedgeRoundTripper(statuses(calls, http.StatusOK), HedgePolicy{Delay: time.Second})
+
+	res, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "http://elections.test/stat", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// EventKind names a decision made by a resilient RoundTripper.
+type EventKind string
+
+const (
+	EventRetry           EventKind = "retry"
+	EventRetryGiveUp     EventKind = "retry_give_up"
+	EventBreakerOpen     EventKind = "breaker_open"
+	EventBreakerHalfOpen EventKind = "breaker_half_open"
+	EventBreakerClose    EventKind = "breaker_close"
+	EventBreakerReject   EventKind = "breaker_reject"
+	EventHedge           EventKind = "hedge"
+	EventHedgeWin        EventKind = "hedge_win"
+)
+
+// Event describes a decision, only the fields relevant to Kind are set.
+type Event struct {
+	Kind   EventKind
+	Method string
+	Host   string
+	URL    string
+	// Attempt counts from 1, for hedges it is the request that won or
+	// was started.
+	Attempt int
+	Delay   time.Duration
+	Status  int
+	Err     error
+	Reason  string
+}
+
+// EventHook receives decisions of RetryRoundTripper, BreakerRoundTripper
+// and HedgeRoundTripper. It is called synchronously and must be fast.
+type EventHook func(Event)
+
+// LogEvents returns a hook writing events to the logger, the default
+// logger if nil.
+func LogEvents(logger *slog.Logger) EventHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(e Event) {
+		level := slog.LevelInfo
+		switch e.Kind {
+		case EventRetryGiveUp, EventBreakerOpen, EventBreakerReject:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("event", string(e.Kind)),
+			slog.String("method", e.Method),
+			slog.String("host", e.Host),
+		}
+		if e.Attempt > 0 {
+			attrs = append(attrs, slog.Int("attempt", e.Attempt))
+		}
+		if e.Delay > 0 {
+			attrs = append(attrs, slog.Duration("delay", e.Delay))
+		}
+		if e.Status > 0 {
+			attrs = append(attrs, slog.Int("status", e.Status))
+		}
+		if e.Err != nil {
+			attrs = append(attrs, slog.Any("err", e.Err))
+		}
+		if e.Reason != "" {
+			attrs = append(attrs, slog.String("reason", e.Reason))
+		}
+		logger.LogAttrs(context.Background(), level, "http client "+string(e.Kind), attrs...)
+	}
+}
+
+func emit(hook EventHook, e Event, req *http.Request) {
+	if hook == nil {
+		return
+	}
+	e.Method = req.Method
+	e.Host = req.URL.Host
+	e.URL = req.URL.String()
+	hook(e)
+}
+
+// IsIdempotent reports whether the request may be sent more than once:
+// the method is idempotent or the caller set an Idempotency-Key header.
+func IsIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// replayable reports whether the request body can be sent again.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// cloneRequest copies the request with a fresh body for one more attempt.
+func cloneRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	clone := req.Clone(ctx)
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+const defaultHedgeDelay = 100 * time.Millisecond
+
+// HedgePolicy configures HedgeRoundTripper, zero fields take defaults.
+type HedgePolicy struct {
+	// Delay is how long to wait for a response before sending one more
+	// copy of the request, 100ms by default. Set it around the p95 latency.
+	Delay time.Duration
+	// MaxHedges is the number of extra copies, 1 by default.
+	MaxHedges int
+	OnEvent   EventHook
+}
+
+// HedgeRoundTripper sends a copy of a slow idempotent request and returns
+// whichever response comes first, the other requests are canceled. It
+// trades extra load for lower tail latency, so it is off unless added.
+type HedgeRoundTripper struct {
+	rt     http.RoundTripper
+	policy HedgePolicy
+}
+
+func NewHedgeRoundTripper(rt http.RoundTripper, policy HedgePolicy) *HedgeRoundTripper {
+	if policy.Delay <= 0 {
+		policy.Delay = defaultHedgeDelay
+	}
+	if policy.MaxHedges <= 0 {
+		policy.MaxHedges = 1
+	}
+	return &HedgeRoundTripper{rt: rt, policy: policy}
+}
+
+type hedgeResult struct {
+	attempt int
+	res     *http.Response
+	err     error
+	cancel  context.CancelFunc
+}
+
+func (h *HedgeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !IsIdempotent(req) || !replayable(req) {
+		return h.rt.RoundTrip(req)
+	}
+
+	total := h.policy.MaxHedges + 1
+	results := make(chan hedgeResult, total)
+	cancels := make([]context.CancelFunc, 0, total)
+	send := func(attempt int) {
+		ctx, cancel := context.WithCancel(req.Context())
+		cancels = append(cancels, cancel)
+		attemptReq, err := cloneRequest(ctx, req)
+		if err != nil {
+			results <- hedgeResult{attempt: attempt, err: err, cancel: cancel}
+			return
+		}
+		go func() {
+			res, err := h.rt.RoundTrip(attemptReq)
+			results <- hedgeResult{attempt: attempt, res: res, err: err, cancel: cancel}
+		}()
+	}
+
+	send(1)
+	sent, received := 1, 0
+	timer := time.NewTimer(h.policy.Delay)
+	defer timer.Stop()
+
+	var last hedgeResult
+	for received < sent {
+		select {
+		case <-timer.C:
+			if sent < total {
+				sent++
+				emit(h.policy.OnEvent, Event{Kind: EventHedge, Attempt: sent, Delay: h.policy.Delay * time.Duration(sent-1)}, req)
+				send(sent)
+				timer.Reset(h.policy.Delay)
+			}
+
+		case r := <-results:
+			received++
+			if r.err != nil {
+				r.cancel()
+				last = r
+				if received == sent && sent < total {
+					// everything sent so far failed, don't wait for the timer
+					sent++
+					emit(h.policy.OnEvent, Event{Kind: EventHedge, Attempt: sent, Err: r.err}, req)
+					send(sent)
+				}
+				continue
+			}
+
+			if sent > 1 {
+				emit(h.policy.OnEvent, Event{Kind: EventHedgeWin, Attempt: r.attempt, Status: r.res.StatusCode}, req)
+			}
+			// cancel the losers, the winner lives until its body is closed
+			for i, cancel := range cancels {
+				if i != r.attempt-1 {
+					cancel()
+				}
+			}
+			go drainLosers(results, sent-received)
+			r.res.Body = &cancelBody{ReadCloser: r.res.Body, cancel: r.cancel}
+			return r.res, nil
+		}
+	}
+	return nil, last.err
+}
+
+// drainLosers closes responses of canceled requests that still arrive.
+func drainLosers(results <-chan hedgeResult, n int) {
+	for ; n > 0; n-- {
+		r := <-results
+		if r.res != nil {
+			r.res.Body.Close()
+		}
+		r.cancel()
+	}
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
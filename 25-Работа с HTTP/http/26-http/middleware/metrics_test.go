@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/router"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+	r := router.New()
+	r.Use(Metrics(m, r.Pattern))
+	r.HandleFunc("GET /elections/{id:uint}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	r.HandleFunc("POST /vote", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	r.HandleFunc("GET /upgrade", func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		conn.Close()
+	})
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	for _, target := range []string{"/elections/1", "/elections/2", "/nope", "/upgrade"} {
+		res, err := http.Get(srv.URL + target)
+		if err == nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+	}
+	res, err := http.Post(srv.URL+"/vote", "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	res.Body.Close()
+
+	body := scrape(t, m)
+	for _, exp := range []string{
+		`http_requests_total{code="200",method="GET",route="/elections/{id:uint}"} 2`,
+		`http_requests_total{code="404",method="GET",route="unmatched"} 1`,
+		`http_requests_total{code="500",method="POST",route="/vote"} 1`,
+		// the client retries the GET once the hijacked connection is closed
+		`http_requests_total{code="101",method="GET",route="/upgrade"}`,
+		`http_request_duration_seconds_count{method="GET",route="/elections/{id:uint}"} 2`,
+	} {
+		require.Contains(t, body, exp)
+	}
+}
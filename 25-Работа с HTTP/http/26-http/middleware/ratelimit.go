@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/codec"
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/ratelimit"
+)
+
+// maxKeyBody limits how much of the body ByJSONField and ByBody read.
+const maxKeyBody = 1 << 20
+
+// RateLimitKey returns the key a request is limited by, an empty key lets
+// the request through.
+type RateLimitKey func(r *http.Request) string
+
+// ByRemoteIP limits by the IP address of the client connection.
+func ByRemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// ByHeader limits by a request header, like an API key. The key has the
+// SHA-256 of the value, keys are stored by the limiter, in Redis too, and
+// must not hold credentials.
+func ByHeader(name string) RateLimitKey {
+	return func(r *http.Request) string {
+		if v := r.Header.Get(name); v != "" {
+			sum := sha256.Sum256([]byte(v))
+			return name + ":" + hex.EncodeToString(sum[:])
+		}
+		return ""
+	}
+}
+
+// ByJSONField limits by a top level string field of a JSON body, like the
+// passport of a vote. The body is put back for the handler.
+func ByJSONField(name string) RateLimitKey {
+	return func(r *http.Request) string {
+		if r.Body == nil || r.Body == http.NoBody {
+			return ""
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxKeyBody))
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return ""
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return ""
+		}
+		var value string
+		if err := json.Unmarshal(fields[name], &value); err != nil || value == "" {
+			return ""
+		}
+		return name + ":" + value
+	}
+}
+
+// ByBody limits by a key of the body decoded with the codec of its
+// Content-Type, so XML and protobuf votes are limited the same as JSON
+// ones. The body is put back for the handler.
+func ByBody[T any](codecs *codec.Registry, key func(*T) string) RateLimitKey {
+	return func(r *http.Request) string {
+		if r.Body == nil || r.Body == http.NoBody {
+			return ""
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxKeyBody))
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return ""
+		}
+
+		v := new(T)
+		c, err := codecs.ForContentType(r.Header.Get("Content-Type"), v)
+		if err != nil {
+			return ""
+		}
+		if err := c.Unmarshal(body, v); err != nil {
+			return ""
+		}
+		return key(v)
+	}
+}
+
+// RateLimit rejects requests over the limit with 429 and a problem
+// document. Every limited response carries the RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers, a
+// rejected one also Retry-After. If the limiter fails the request is let
+// through, a broken Redis should not stop the voting.
+func RateLimit(limiter ratelimit.Limiter, rate ratelimit.Rate, key RateLimitKey) func(http.Handler) http.Handler {
+	policy := fmt.Sprintf("%d;w=%d", rate.Limit, seconds(rate.Period))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			res, err := limiter.Allow(r.Context(), k)
+			if err != nil {
+				slog.WarnContext(r.Context(), "rate limiter failed, request is not limited", "err", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+			h.Set("RateLimit-Policy", policy)
+			if res.Allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Set("Retry-After", strconv.Itoa(seconds(res.RetryAfter)))
+			p := problem.New(http.StatusTooManyRequests, problem.CodeRateLimited,
+				"too many requests, retry in "+res.RetryAfter.Round(time.Second).String())
+			p.RequestId = RequestId(r.Context())
+			problem.Write(w, r, p)
+		})
+	}
+}
+
+// LimitEach limits the items of a request one by one, like the lines of a
+// batch RateLimit can't see without reading the whole body. Every item
+// takes one request from the quota of key(v), the request itself is left
+// to RateLimit. An item over the limit gets a 429 problem and nil
+// otherwise. If the limiter fails the item is let through, like RateLimit
+// does.
+func LimitEach[T any](limiter ratelimit.Limiter, key func(*T) string) func(r *http.Request, v *T) *problem.Problem {
+	return func(r *http.Request, v *T) *problem.Problem {
+		k := key(v)
+		if k == "" {
+			return nil
+		}
+		res, err := limiter.Allow(r.Context(), k)
+		if err != nil {
+			slog.WarnContext(r.Context(), "rate limiter failed, request is not limited", "err", err)
+			return nil
+		}
+		if !res.Allowed {
+			return problem.New(http.StatusTooManyRequests, problem.CodeRateLimited,
+				"too many requests, retry in "+res.RetryAfter.Round(time.Second).String())
+		}
+		return nil
+	}
+}
+
+// seconds rounds d up to whole seconds, as the headers use them.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
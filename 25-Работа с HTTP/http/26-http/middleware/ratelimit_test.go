@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/codec"
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	rate := ratelimit.PerMinute(2)
+	limiter, err := ratelimit.NewMemory(ratelimit.TokenBucket, rate)
+	require.NoError(t, err)
+
+	var bodies []string
+	h := RateLimit(limiter, rate, ByJSONField("passport"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	vote := func(body string) *http.Response {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(body)))
+		return w.Result()
+	}
+
+	res := vote(`{"passport": "a", "candidate_id": 1}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	require.Equal(t, "2", res.Header.Get("RateLimit-Limit"))
+	require.Equal(t, "1", res.Header.Get("RateLimit-Remaining"))
+	require.Equal(t, "2;w=60", res.Header.Get("RateLimit-Policy"))
+	require.Equal(t, `{"passport": "a", "candidate_id": 1}`, bodies[0], "handler has to get the body")
+
+	require.Equal(t, http.StatusCreated, vote(`{"passport": "a"}`).StatusCode)
+	res = vote(`{"passport": "a"}`)
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, "0", res.Header.Get("RateLimit-Remaining"))
+	require.Equal(t, "30", res.Header.Get("Retry-After"))
+	require.ErrorIs(t, problem.FromResponse(res), problem.ErrRateLimited)
+	require.Len(t, bodies, 2)
+
+	// other passports and requests without a key are not affected
+	require.Equal(t, http.StatusCreated, vote(`{"passport": "b"}`).StatusCode)
+	require.Equal(t, http.StatusCreated, vote(`not json`).StatusCode)
+	require.Equal(t, http.StatusCreated, vote(`{"passport": 1}`).StatusCode)
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("redis is down")
+}
+
+func TestRateLimit_FailOpen(t *testing.T) {
+	h := RateLimit(failingLimiter{}, ratelimit.PerSecond(1), ByRemoteIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/vote", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/vote", nil)
+	r.RemoteAddr = "10.0.0.1:5555"
+	r.Header.Set("X-Api-Key", "k1")
+	require.Equal(t, "ip:10.0.0.1", ByRemoteIP(r))
+	// the value is hashed, credentials don't end up in the limiter store
+	require.Equal(t, "X-Api-Key:6ab9f1eb8f7d3388f4f9d586f66e99fd54080df2c446f0e58668b09c08a16dd0", ByHeader("X-Api-Key")(r))
+	require.Empty(t, ByHeader("Authorization")(r))
+	require.Empty(t, ByJSONField("passport")(r))
+}
+
+func TestRateLimitKeys_ByBody(t *testing.T) {
+	type vote struct {
+		Passport string `json:"passport" xml:"passport"`
+	}
+	key := ByBody(codec.Default(), func(v *vote) string { return v.Passport })
+
+	for contentType, body := range map[string]string{
+		"":                "{\"passport\": \"p1\"}",
+		"application/xml": "<vote><passport>p1</passport></vote>",
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString(body))
+		r.Header.Set("Content-Type", contentType)
+		require.Equal(t, "p1", key(r), contentType)
+		rest, _ := io.ReadAll(r.Body)
+		require.Equal(t, body, string(rest), "body is put back")
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/vote", bytes.NewBufferString("passport=p1"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	require.Empty(t, key(r))
+}
+
+func TestLimitEach(t *testing.T) {
+	limiter, err := ratelimit.NewMemory(ratelimit.TokenBucket, ratelimit.PerMinute(2))
+	require.NoError(t, err)
+	limit := LimitEach(limiter, func(v *string) string {
+		if *v == "" {
+			return ""
+		}
+		return "passport:" + *v
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/votes:batch", nil)
+	a, b, empty := "a", "b", ""
+	require.Nil(t, limit(r, &a))
+	require.Nil(t, limit(r, &a))
+	p := limit(r, &a)
+	require.ErrorIs(t, p, problem.ErrRateLimited)
+	require.Equal(t, http.StatusTooManyRequests, p.Status)
+	require.Nil(t, limit(r, &b), "other items are not affected")
+
+	// items without a key are not limited
+	for range 3 {
+		require.Nil(t, limit(r, &empty))
+	}
+
+	require.Nil(t, LimitEach(failingLimiter{}, func(v *string) string { return *v })(r, &a))
+}
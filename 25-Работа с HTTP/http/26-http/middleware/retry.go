@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// RetryPolicy configures RetryRoundTripper, zero fields take defaults.
+type RetryPolicy struct {
+	// MaxAttempts counts the first request too, 3 by default.
+	MaxAttempts int
+	// BaseDelay is doubled on every attempt, the actual delay is a random
+	// value up to it (full jitter). 100ms by default.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff and Retry-After, a server asking to wait
+	// longer is not retried. 5s by default.
+	MaxDelay time.Duration
+	// ShouldRetry decides if the outcome of an attempt is worth a retry,
+	// by default transport errors and 429, 502, 503, 504.
+	ShouldRetry func(res *http.Response, err error) bool
+	OnEvent     EventHook
+}
+
+// RetryRoundTripper sends a failed request again with exponential backoff.
+// Only idempotent requests (see IsIdempotent) with a replayable body are
+// retried.
+type RetryRoundTripper struct {
+	rt     http.RoundTripper
+	policy RetryPolicy
+}
+
+func NewRetryRoundTripper(rt http.RoundTripper, policy RetryPolicy) *RetryRoundTripper {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultRetryAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = defaultRetryBaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaultRetryMaxDelay
+	}
+	if policy.ShouldRetry == nil {
+		policy.ShouldRetry = DefaultShouldRetry
+	}
+	return &RetryRoundTripper{rt: rt, policy: policy}
+}
+
+// DefaultShouldRetry retries transport errors and statuses telling the
+// request may succeed later.
+func DefaultShouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (r *RetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !IsIdempotent(req) || !replayable(req) {
+		return r.rt.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			var err error
+			if attemptReq, err = cloneRequest(req.Context(), req); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := r.rt.RoundTrip(attemptReq)
+		if req.Context().Err() != nil || !r.policy.ShouldRetry(res, err) {
+			return res, err
+		}
+
+		status := 0
+		if res != nil {
+			status = res.StatusCode
+		}
+		if attempt >= r.policy.MaxAttempts {
+			emit(r.policy.OnEvent, Event{Kind: EventRetryGiveUp, Attempt: attempt, Status: status, Err: err, Reason: "attempts exhausted"}, req)
+			return res, err
+		}
+
+		delay := r.backoff(attempt)
+		if after, ok := retryAfter(res, time.Now()); ok {
+			if after > r.policy.MaxDelay {
+				emit(r.policy.OnEvent, Event{Kind: EventRetryGiveUp, Attempt: attempt, Delay: after, Status: status, Reason: "retry-after exceeds max delay"}, req)
+				return res, err
+			}
+			delay = max(delay, after)
+		}
+		emit(r.policy.OnEvent, Event{Kind: EventRetry, Attempt: attempt + 1, Delay: delay, Status: status, Err: err}, req)
+
+		if res != nil {
+			// drain, so the connection goes back to the pool
+			io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns a random delay up to BaseDelay*2^(attempt-1), capped by
+// MaxDelay.
+func (r *RetryRoundTripper) backoff(attempt int) time.Duration {
+	ceiling := r.policy.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		ceiling = min(r.policy.BaseDelay<<shift, r.policy.MaxDelay)
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// retryAfter parses the Retry-After header, given in seconds or as a date.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -4,23 +4,30 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
-	"sync/atomic"
 	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
 )
 
-var requestCnt int64
+// RequestId returns the id the Logger assigned to the request, empty if
+// the request didn't go through a Logger.
+func RequestId(ctx context.Context) string {
+	return correlation.FromContext(ctx)
+}
 
+// Logger takes the request id from the X-Request-ID header or generates
+// one, puts it into the request context and sends it back in the response.
 type Logger struct {
 	handler http.Handler
 }
 
 func (l *Logger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
-	requestId := atomic.AddInt64(&requestCnt, 1)
-	ctx := context.WithValue(r.Context(), "request_id", requestId)
+	ctx := correlation.NewContext(r.Context(), correlation.Ensure(r.Header.Get(correlation.Header)))
 	rCtx := r.Clone(ctx)
+	w.Header().Set(correlation.Header, correlation.FromContext(ctx))
 	l.handler.ServeHTTP(w, rCtx)
-	slog.Info("request", "method", r.Method, "url", r.URL.Path, "duration", time.Since(start), "request_id", requestId)
+	slog.InfoContext(ctx, "request", "method", r.Method, "url", r.URL.Path, "duration", time.Since(start))
 }
 
 func NewLogger(handlerToWrap http.Handler) *Logger {
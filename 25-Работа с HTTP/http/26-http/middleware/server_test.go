@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_RequestId(t *testing.T) {
+	var seen string
+	logger := NewLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestId(r.Context())
+	}))
+
+	cases := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"generated", "", false},
+		{"from_caller", "client-42", true},
+		{"invalid", "bad id\n", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/stat", nil)
+			if c.header != "" {
+				r.Header.Set(correlation.Header, c.header)
+			}
+			w := httptest.NewRecorder()
+			logger.ServeHTTP(w, r)
+
+			require.NotEmpty(t, seen)
+			require.Equal(t, seen, w.Header().Get(correlation.Header))
+			if c.keep {
+				require.Equal(t, c.header, seen)
+			} else {
+				require.NotEqual(t, c.header, seen)
+			}
+		})
+	}
+}
+
+func TestLoggingRoundTripper_RequestId(t *testing.T) {
+	var sent []string
+	rt := NewLoggingRoundTripper(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		sent = append(sent, r.Header.Get(correlation.Header))
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader(""))}, nil
+	}))
+
+	ctx := correlation.NewContext(context.Background(), "ctx-id")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://elections/stat", nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Empty(t, req.Header.Get(correlation.Header), "the caller's request must not be changed")
+
+	req.Header.Set(correlation.Header, "header-id")
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+
+	req, err = http.NewRequest(http.MethodGet, "http://elections/stat", nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+
+	require.Equal(t, "ctx-id", sent[0])
+	require.Equal(t, "header-id", sent[1])
+	require.True(t, correlation.Valid(sent[2]), "expected a generated id, got %q", sent[2])
+}
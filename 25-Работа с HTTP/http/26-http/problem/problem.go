@@ -0,0 +1,199 @@
+// Package problem is the error model of the HTTP API, an RFC 7807
+// "problem details" document served as application/problem+json.
+//
+// Servers write a *Problem with Write, clients turn an error response back
+// into a *Problem with FromResponse and check it with errors.Is against the
+// Err* values, which compare by Code.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const ContentType = "application/problem+json"
+
+// typePrefix turns a code into the problem type URI.
+const typePrefix = "urn:elections:problem:"
+
+// Code is the machine readable reason of a problem, it is stable across
+// releases unlike Title and Detail.
+type Code string
+
+const (
+	CodeInvalidRequest      Code = "invalid_request"
+	CodeValidation          Code = "validation_failed"
+	CodeUnauthorized        Code = "unauthorized"
+	CodeForbidden           Code = "forbidden"
+	CodeNotFound            Code = "not_found"
+	CodeMethodNotAllowed    Code = "method_not_allowed"
+	CodeNotAcceptable       Code = "not_acceptable"
+	CodeUnsupportedMedia    Code = "unsupported_media_type"
+	CodeConflict            Code = "conflict"
+	CodeAlreadyVoted        Code = "already_voted"
+	CodeBatchAborted        Code = "batch_aborted"
+	CodeCandidateNotAllowed Code = "candidate_not_allowed"
+	CodeElectionNotStarted  Code = "election_not_started"
+	CodeElectionClosed      Code = "election_closed"
+	CodeRateLimited         Code = "rate_limited"
+	CodeUnavailable         Code = "unavailable"
+	CodeInternal            Code = "internal"
+)
+
+// Sentinels for errors.Is, only Code is compared.
+var (
+	ErrInvalidRequest      = &Problem{Code: CodeInvalidRequest}
+	ErrValidation          = &Problem{Code: CodeValidation}
+	ErrUnauthorized        = &Problem{Code: CodeUnauthorized}
+	ErrForbidden           = &Problem{Code: CodeForbidden}
+	ErrNotFound            = &Problem{Code: CodeNotFound}
+	ErrMethodNotAllowed    = &Problem{Code: CodeMethodNotAllowed}
+	ErrNotAcceptable       = &Problem{Code: CodeNotAcceptable}
+	ErrUnsupportedMedia    = &Problem{Code: CodeUnsupportedMedia}
+	ErrConflict            = &Problem{Code: CodeConflict}
+	ErrAlreadyVoted        = &Problem{Code: CodeAlreadyVoted}
+	ErrBatchAborted        = &Problem{Code: CodeBatchAborted}
+	ErrCandidateNotAllowed = &Problem{Code: CodeCandidateNotAllowed}
+	ErrElectionNotStarted  = &Problem{Code: CodeElectionNotStarted}
+	ErrElectionClosed      = &Problem{Code: CodeElectionClosed}
+	ErrRateLimited         = &Problem{Code: CodeRateLimited}
+	ErrUnavailable         = &Problem{Code: CodeUnavailable}
+	ErrInternal            = &Problem{Code: CodeInternal}
+)
+
+// Violation points at a request field that failed validation.
+type Violation struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+type Problem struct {
+	Type       string      `json:"type"`
+	Title      string      `json:"title"`
+	Status     int         `json:"status"`
+	Detail     string      `json:"detail,omitempty"`
+	Instance   string      `json:"instance,omitempty"`
+	Code       Code        `json:"code"`
+	RequestId  string      `json:"request_id,omitempty"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// New returns a problem with the title taken from the status.
+func New(status int, code Code, detail string) *Problem {
+	return &Problem{
+		Type:   typePrefix + string(code),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Validation returns a 400 problem listing the wrong fields.
+func Validation(violations ...Violation) *Problem {
+	fields := make([]string, 0, len(violations))
+	for _, v := range violations {
+		fields = append(fields, v.Field)
+	}
+	p := New(http.StatusBadRequest, CodeValidation, "invalid fields: "+strings.Join(fields, ", "))
+	p.Violations = violations
+	return p
+}
+
+func (p *Problem) Error() string {
+	msg := fmt.Sprintf("%d %s", p.Status, p.Code)
+	if p.Detail != "" {
+		msg += ": " + p.Detail
+	}
+	if p.RequestId != "" {
+		msg += " (request " + p.RequestId + ")"
+	}
+	return msg
+}
+
+func (p *Problem) Is(target error) bool {
+	t, ok := target.(*Problem)
+	return ok && t.Code == p.Code
+}
+
+// Write sends the problem, Instance is set to the request path if empty.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.Path
+	}
+	buf, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, p.Detail, p.Status)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	w.Write(buf)
+}
+
+// FromResponse reads the body of a non 2xx response into a *Problem. A body
+// that is not a problem document still gives a *Problem built from the
+// status, so callers can always use errors.Is and errors.As.
+func FromResponse(res *http.Response) error {
+	body, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if err != nil {
+		return fmt.Errorf("cannot read error response: %w", err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType == ContentType {
+		p := &Problem{}
+		if err := json.Unmarshal(body, p); err == nil && p.Code != "" {
+			if p.Status == 0 {
+				p.Status = res.StatusCode
+			}
+			return p
+		}
+	}
+
+	p := New(res.StatusCode, codeOf(res.StatusCode), strings.TrimSpace(string(body)))
+	p.Type = "about:blank"
+	return p
+}
+
+// codeOf guesses the code for responses without a problem document, like
+// the ones written by proxies.
+func codeOf(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusNotAcceptable:
+		return CodeNotAcceptable
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMedia
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return CodeUnavailable
+	default:
+		return CodeInternal
+	}
+}
+
+// As is a shortcut for errors.As with a *Problem target.
+func As(err error) (*Problem, bool) {
+	var p *Problem
+	ok := errors.As(err, &p)
+	return p, ok
+}
@@ -0,0 +1,61 @@
+package problem_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/problem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAndDecode(t *testing.T) {
+	p := problem.Validation(
+		problem.Violation{Field: "passport", Reason: "is required"},
+		problem.Violation{Field: "candidate_id", Reason: "is required"},
+	)
+	p.RequestId = "42"
+
+	w := httptest.NewRecorder()
+	problem.Write(w, httptest.NewRequest(http.MethodPost, "/vote", nil), p)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{
+		"type": "urn:elections:problem:validation_failed",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "invalid fields: passport, candidate_id",
+		"instance": "/vote",
+		"code": "validation_failed",
+		"request_id": "42",
+		"violations": [
+			{"field": "passport", "reason": "is required"},
+			{"field": "candidate_id", "reason": "is required"}
+		]
+	}`, w.Body.String())
+
+	err := problem.FromResponse(w.Result())
+	require.ErrorIs(t, err, problem.ErrValidation)
+	require.NotErrorIs(t, err, problem.ErrNotFound)
+
+	decoded, ok := problem.As(fmt.Errorf("vote: %w", err))
+	require.True(t, ok)
+	require.Equal(t, p, decoded)
+	require.Equal(t, "400 validation_failed: invalid fields: passport, candidate_id (request 42)", decoded.Error())
+}
+
+func TestFromResponse_NotProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	http.Error(w, "upstream is down", http.StatusBadGateway)
+
+	err := problem.FromResponse(w.Result())
+	require.True(t, errors.Is(err, problem.ErrUnavailable))
+
+	p, ok := problem.As(err)
+	require.True(t, ok)
+	require.Equal(t, "about:blank", p.Type)
+	require.Equal(t, http.StatusBadGateway, p.Status)
+	require.Equal(t, "upstream is down", p.Detail)
+}
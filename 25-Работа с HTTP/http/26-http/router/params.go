@@ -0,0 +1,28 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Uint32 returns the path parameter as uint32. The error says which
+// parameter is wrong, it is meant to be sent to the client.
+func Uint32(r *http.Request, name string) (uint32, error) {
+	raw := r.PathValue(name)
+	v, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("cant parse %s, expect positive int, got: %s", name, raw)
+	}
+	return uint32(v), nil
+}
+
+// Int64 returns the path parameter as int64, see Uint32.
+func Int64(r *http.Request, name string) (int64, error) {
+	raw := r.PathValue(name)
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cant parse %s, expect int, got: %s", name, raw)
+	}
+	return v, nil
+}
@@ -0,0 +1,305 @@
+// Package router dispatches requests by method and path pattern.
+//
+// A pattern is an optional method and a path, like "GET /elections/{id}"
+// or "/vote". Path segments in braces are parameters, "{id:uint}" and
+// "{id:int}" only match numbers. Parameters are available through
+// r.PathValue and the typed helpers of this package. Static segments win
+// over parameters, a trailing slash is ignored.
+//
+// A path without a route gets 404, a path whose routes don't accept the
+// method gets 405 with the Allow header.
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Middleware func(http.Handler) http.Handler
+
+type paramKind int
+
+const (
+	kindString paramKind = iota
+	kindUint
+	kindInt
+)
+
+type node struct {
+	static map[string]*node
+	param  *node
+	name   string
+	kind   paramKind
+	// pattern is the path of the routes ending here, like "/elections/{id:uint}"
+	pattern string
+	// handlers by method, "" accepts any method
+	handlers map[string]http.Handler
+}
+
+func (n *node) matches(segment string) bool {
+	switch n.kind {
+	case kindUint:
+		_, err := strconv.ParseUint(segment, 10, 64)
+		return err == nil
+	case kindInt:
+		_, err := strconv.ParseInt(segment, 10, 64)
+		return err == nil
+	}
+	return true
+}
+
+// Router registers routes like a Group for the root path and serves
+// requests. It must be set up before it starts serving.
+type Router struct {
+	// NotFound and MethodNotAllowed write the error responses, by default
+	// plain text like http.Error. Router middlewares are applied to them.
+	NotFound         http.Handler
+	MethodNotAllowed http.Handler
+
+	root  *node
+	mws   []Middleware
+	group Group
+}
+
+func New() *Router {
+	r := &Router{root: &node{}}
+	r.group.router = r
+	return r
+}
+
+// Use adds middlewares that run for every request, including the ones
+// answered with 404 or 405, no matter when the routes were registered.
+func (r *Router) Use(mws ...Middleware) {
+	r.mws = append(r.mws, mws...)
+}
+
+func (r *Router) Group(prefix string, mws ...Middleware) *Group {
+	return r.group.Group(prefix, mws...)
+}
+
+func (r *Router) Route(prefix string, fn func(g *Group)) {
+	r.group.Route(prefix, fn)
+}
+
+func (r *Router) Handle(pattern string, h http.Handler, mws ...Middleware) {
+	r.group.Handle(pattern, h, mws...)
+}
+
+func (r *Router) HandleFunc(pattern string, h http.HandlerFunc, mws ...Middleware) {
+	r.group.Handle(pattern, h, mws...)
+}
+
+// Group registers routes under a common prefix with common middlewares.
+type Group struct {
+	router *Router
+	prefix string
+	mws    []Middleware
+}
+
+// Use adds middlewares for routes registered after the call. The first
+// middleware is the outermost.
+func (g *Group) Use(mws ...Middleware) {
+	g.mws = append(g.mws, mws...)
+}
+
+// Group returns a sub group, it inherits middlewares added so far.
+func (g *Group) Group(prefix string, mws ...Middleware) *Group {
+	return &Group{
+		router: g.router,
+		prefix: g.prefix + strings.TrimSuffix(prefix, "/"),
+		mws:    append(slices.Clone(g.mws), mws...),
+	}
+}
+
+// Route calls fn with a sub group, it's a shortcut for nested groups.
+func (g *Group) Route(prefix string, fn func(g *Group)) {
+	fn(g.Group(prefix))
+}
+
+// Handle registers h for the pattern, mws are applied to this route only
+// inside the group middlewares. It panics on an invalid or duplicated
+// pattern like http.ServeMux does.
+func (g *Group) Handle(pattern string, h http.Handler, mws ...Middleware) {
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		method, path = "", pattern
+	}
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "/") {
+		panic(fmt.Sprintf("router: pattern %q: path must start with /", pattern))
+	}
+
+	all := append(slices.Clone(g.mws), mws...)
+	for i := len(all) - 1; i >= 0; i-- {
+		h = all[i](h)
+	}
+	g.router.add(method, g.prefix+path, h)
+}
+
+func (g *Group) HandleFunc(pattern string, h http.HandlerFunc, mws ...Middleware) {
+	g.Handle(pattern, h, mws...)
+}
+
+func (r *Router) add(method, path string, h http.Handler) {
+	n := r.root
+	for _, segment := range split(path) {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			if n.static == nil {
+				n.static = make(map[string]*node)
+			}
+			next, ok := n.static[segment]
+			if !ok {
+				next = &node{}
+				n.static[segment] = next
+			}
+			n = next
+			continue
+		}
+
+		name, kind := parseParam(path, segment)
+		if n.param == nil {
+			n.param = &node{name: name, kind: kind}
+		} else if n.param.name != name || n.param.kind != kind {
+			panic(fmt.Sprintf("router: path %q: parameter %s conflicts with {%s} registered before", path, segment, n.param.name))
+		}
+		n = n.param
+	}
+
+	if n.handlers == nil {
+		n.handlers = make(map[string]http.Handler)
+		n.pattern = "/" + strings.Join(split(path), "/")
+	}
+	if _, ok := n.handlers[method]; ok {
+		panic(fmt.Sprintf("router: %s %s is already registered", method, path))
+	}
+	n.handlers[method] = h
+}
+
+func parseParam(path, segment string) (string, paramKind) {
+	name, kind, _ := strings.Cut(segment[1:len(segment)-1], ":")
+	if name == "" {
+		panic(fmt.Sprintf("router: path %q: empty parameter name", path))
+	}
+	switch kind {
+	case "":
+		return name, kindString
+	case "uint":
+		return name, kindUint
+	case "int":
+		return name, kindInt
+	default:
+		panic(fmt.Sprintf("router: path %q: unknown parameter type %q", path, kind))
+	}
+}
+
+type param struct {
+	name, value string
+}
+
+// match finds the node for the path, static segments are tried before the
+// parameter and the search backtracks if a static branch leads nowhere.
+func (n *node) match(segments []string, params []param) (*node, []param) {
+	if len(segments) == 0 {
+		if n.handlers == nil {
+			return nil, nil
+		}
+		return n, params
+	}
+
+	segment := segments[0]
+	if next, ok := n.static[segment]; ok {
+		if found, p := next.match(segments[1:], params); found != nil {
+			return found, p
+		}
+	}
+	if n.param != nil && n.param.matches(segment) {
+		return n.param.match(segments[1:], append(params, param{n.param.name, segment}))
+	}
+	return nil, nil
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var h http.Handler = http.HandlerFunc(r.dispatch)
+	for i := len(r.mws) - 1; i >= 0; i-- {
+		h = r.mws[i](h)
+	}
+	h.ServeHTTP(w, req)
+}
+
+// Pattern returns the path pattern of the routes matching the request
+// path, like "/elections/{id:uint}", empty if there are none. Middlewares
+// use it where the raw path would be too detailed, like metric labels.
+func (r *Router) Pattern(req *http.Request) string {
+	n, _ := r.root.match(split(req.URL.Path), nil)
+	if n == nil {
+		return ""
+	}
+	return n.pattern
+}
+
+func (r *Router) dispatch(w http.ResponseWriter, req *http.Request) {
+	r.handler(w, req).ServeHTTP(w, req)
+}
+
+// handler picks the handler for the request and sets its path values.
+func (r *Router) handler(w http.ResponseWriter, req *http.Request) http.Handler {
+	n, params := r.root.match(split(req.URL.Path), nil)
+	if n == nil {
+		return r.notFound()
+	}
+	for _, p := range params {
+		req.SetPathValue(p.name, p.value)
+	}
+
+	if h, ok := n.handlers[req.Method]; ok {
+		return h
+	}
+	if h, ok := n.handlers[http.MethodGet]; ok && req.Method == http.MethodHead {
+		return h
+	}
+	if h, ok := n.handlers[""]; ok {
+		return h
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed(n), ", "))
+	return r.methodNotAllowed()
+}
+
+func (r *Router) notFound() http.Handler {
+	if r.NotFound != nil {
+		return r.NotFound
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, fmt.Sprintf("uri %s not found", req.URL.Path), http.StatusNotFound)
+	})
+}
+
+func (r *Router) methodNotAllowed() http.Handler {
+	if r.MethodNotAllowed != nil {
+		return r.MethodNotAllowed
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, fmt.Sprintf("method %s not supported on uri %s", req.Method, req.URL.Path), http.StatusMethodNotAllowed)
+	})
+}
+
+func allowed(n *node) []string {
+	methods := make([]string, 0, len(n.handlers)+1)
+	for m := range n.handlers {
+		methods = append(methods, m)
+	}
+	if _, ok := n.handlers[http.MethodGet]; ok {
+		if _, ok := n.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+func split(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
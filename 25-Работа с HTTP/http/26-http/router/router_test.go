@@ -0,0 +1,140 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/router"
+	"github.com/stretchr/testify/require"
+)
+
+func reply(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s id=%s candidate=%s", name, r.PathValue("id"), r.PathValue("candidate"))
+	}
+}
+
+// trace appends the middleware name to the X-Trace header.
+func trace(name string) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func newTestRouter() *router.Router {
+	r := router.New()
+	r.Use(trace("root"))
+	r.HandleFunc("POST /vote", reply("vote"))
+	r.HandleFunc("GET /stat", reply("stat"))
+	r.HandleFunc("/any", reply("any"))
+	r.Route("/elections", func(g *router.Group) {
+		g.Use(trace("elections"))
+		g.HandleFunc("GET /", reply("list"))
+		g.HandleFunc("GET /{id:uint}", reply("get"))
+		g.HandleFunc("PUT /{id:uint}", reply("update"), trace("update"))
+		g.HandleFunc("GET /{id:uint}/stats/{candidate:uint}", reply("candidate"))
+		g.HandleFunc("GET /current", reply("current"))
+	})
+	return r
+}
+
+func TestRouter(t *testing.T) {
+	r := newTestRouter()
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		code   int
+		body   string
+		allow  string
+		trace  string
+	}{
+		{"static", http.MethodPost, "/vote", http.StatusOK, "vote id= candidate=", "", "root"},
+		{"trailing_slash", http.MethodGet, "/stat/?candidate_id=1", http.StatusOK, "stat id= candidate=", "", "root"},
+		{"head", http.MethodHead, "/stat", http.StatusOK, "", "", "root"},
+		{"any_method", http.MethodDelete, "/any", http.StatusOK, "any id= candidate=", "", "root"},
+		{"group_root", http.MethodGet, "/elections", http.StatusOK, "list id= candidate=", "", "root,elections"},
+		{"param", http.MethodGet, "/elections/7", http.StatusOK, "get id=7 candidate=", "", "root,elections"},
+		{"route_middleware", http.MethodPut, "/elections/7", http.StatusOK, "update id=7 candidate=", "", "root,elections,update"},
+		{"two_params", http.MethodGet, "/elections/7/stats/2", http.StatusOK, "candidate id=7 candidate=2", "", "root,elections"},
+		{"static_wins", http.MethodGet, "/elections/current", http.StatusOK, "current id= candidate=", "", "root,elections"},
+		{"typed_param_mismatch", http.MethodGet, "/elections/abc", http.StatusNotFound, "", "", "root"},
+		{"not_found", http.MethodGet, "/nope", http.StatusNotFound, "", "", "root"},
+		{"method_not_allowed", http.MethodGet, "/vote", http.StatusMethodNotAllowed, "", "POST", "root"},
+		{"allow_lists_head", http.MethodDelete, "/elections/7", http.StatusMethodNotAllowed, "", "GET, HEAD, PUT", "root"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(c.method, c.target, nil))
+
+			require.Equal(t, c.code, w.Code, w.Body.String())
+			if c.body != "" {
+				require.Equal(t, c.body, w.Body.String())
+			}
+			require.Equal(t, c.allow, w.Header().Get("Allow"))
+			require.Equal(t, c.trace, strings.Join(w.Header().Values("X-Trace"), ","))
+		})
+	}
+}
+
+func TestRouter_CustomErrors(t *testing.T) {
+	r := newTestRouter()
+	r.NotFound = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/nope", nil))
+	require.Equal(t, http.StatusTeapot, w.Code)
+}
+
+func TestRouter_Pattern(t *testing.T) {
+	r := newTestRouter()
+
+	cases := map[string]string{
+		"/vote":                "/vote",
+		"/stat/":               "/stat",
+		"/elections":           "/elections",
+		"/elections/7":         "/elections/{id:uint}",
+		"/elections/7/stats/2": "/elections/{id:uint}/stats/{candidate:uint}",
+		"/elections/current":   "/elections/current",
+		"/elections/abc":       "",
+		"/nope":                "",
+	}
+	for target, exp := range cases {
+		require.Equal(t, exp, r.Pattern(httptest.NewRequest(http.MethodGet, target, nil)), target)
+	}
+}
+
+func TestRouter_Conflicts(t *testing.T) {
+	r := router.New()
+	r.HandleFunc("GET /elections/{id}", reply("get"))
+
+	require.Panics(t, func() { r.HandleFunc("GET /elections/{id}", reply("get")) })
+	require.Panics(t, func() { r.HandleFunc("GET /elections/{name}/stats", reply("stats")) })
+	require.Panics(t, func() { r.HandleFunc("GET /elections/{id:float}", reply("get")) })
+	require.Panics(t, func() { r.HandleFunc("GET elections", reply("get")) })
+	require.NotPanics(t, func() { r.HandleFunc("PUT /elections/{id}", reply("update")) })
+}
+
+func TestUint32(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetPathValue("id", "42")
+	r.SetPathValue("bad", "-1")
+
+	id, err := router.Uint32(r, "id")
+	require.NoError(t, err)
+	require.Equal(t, uint32(42), id)
+
+	_, err = router.Uint32(r, "bad")
+	require.EqualError(t, err, "cant parse bad, expect positive int, got: -1")
+}
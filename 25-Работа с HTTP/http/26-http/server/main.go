@@ -1,12 +1,16 @@
 package main
 
 import (
-	"fmt"
+	"flag"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/OtusGolang/webinars_practical_part/26-http/handler"
+	"github.com/OtusGolang/webinars_practical_part/26-http/router"
+	"github.com/OtusGolang/webinars_practical_part/26-http/store"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
 )
 
 type MyHandler struct {
@@ -14,29 +18,47 @@ type MyHandler struct {
 }
 
 func (m *MyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	serverHandler(w, r)
+	routes.ServeHTTP(w, r)
 }
 
-var vote = handler.NewService()
-
-func serverHandler(w http.ResponseWriter, r *http.Request) {
-	resp := &handler.Response{}
-	switch r.URL.Path {
-	case "/vote":
-		vote.SubmitVote(w, r)
-	case "/stat", "/stat/":
-		vote.GetStats(w, r)
-	default:
-		resp.Error.Message = fmt.Sprintf("uri %s not found", r.URL.Path)
-		w.WriteHeader(http.StatusNotFound)
-		handler.WriteResponse(w, resp)
-	}
+var vote = handler.NewService(store.NewMemory(), candidates.NewRegistry(), elections.NewRegistry())
+
+var routes = newRouter()
+
+var (
+	candidatesFile = flag.String("candidates", "", "JSON file with the initial list of candidates")
+	electionsFile  = flag.String("elections", "", "JSON file with the list of elections")
+)
+
+func newRouter() *router.Router {
+	r := router.New()
+	r.NotFound = http.HandlerFunc(handler.NotFound)
+	r.MethodNotAllowed = http.HandlerFunc(handler.MethodNotAllowed)
+
+	r.HandleFunc("POST /vote", vote.SubmitVote)
+	// also serves /stat/, the router ignores the trailing slash
+	r.HandleFunc("GET /stat", vote.GetStats)
+	r.HandleFunc("GET /elections/{id:uint}/stats", vote.GetElectionStats)
+	r.HandleFunc("GET /elections/{id:uint}/stats/{candidate:uint}", vote.GetElectionStats)
+	return r
 }
 
-// curl -d '{"candidate_id": 1, "passport": "test"}' -X POST 0.0.0.0:8080/vote
-// curl 0.0.0.0:8080/stat
-// curl 0.0.0.0:8080/stat/?candidate_id=1
+// curl -H 'Content-Type: application/json' -d '{"election_id": 1, "candidate_id": 1, "passport": "test"}' -X POST 0.0.0.0:8080/vote
+// curl 0.0.0.0:8080/stat?election_id=1
+// curl 0.0.0.0:8080/stat/?election_id=1&candidate_id=1
+// curl 0.0.0.0:8080/elections/1/stats/1
 func main() {
+	flag.Parse()
+	if *candidatesFile != "" {
+		if err := vote.Candidates.LoadFile(*candidatesFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *electionsFile != "" {
+		if err := vote.Elections.LoadFile(*electionsFile); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	handlerHttp := &MyHandler{}
 
@@ -3,14 +3,28 @@ package main
 import (
 	"bytes"
 	"github.com/OtusGolang/webinars_practical_part/26-http/middleware"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestMyHandler(t *testing.T) {
+	_, err := vote.Candidates.Create(candidates.Candidate{Id: 1, Name: "test", Active: true})
+	require.NoError(t, err)
+	_, err = vote.Elections.Create(elections.Election{
+		Id:         1,
+		Title:      "test",
+		Candidates: []uint32{1},
+		OpensAt:    time.Now().Add(-time.Hour),
+		ClosesAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
 	server := &MyHandler{}
 	logger := middleware.NewLogger(server)
 	ts := httptest.NewServer(logger)
@@ -28,9 +42,14 @@ func TestMyHandler(t *testing.T) {
 			"ok",
 			http.MethodPost,
 			"/vote",
-			bytes.NewBufferString(`{"candidate_id": 1, "passport": "test"}`),
+			bytes.NewBufferString(`{"election_id": 1, "candidate_id": 1, "passport": "test"}`),
 			http.StatusOK,
 		},
+		{"method_not_allowed", http.MethodGet, "/vote", nil, http.StatusMethodNotAllowed},
+		{"not_found", http.MethodGet, "/votes", nil, http.StatusNotFound},
+		{"stat_slash", http.MethodGet, "/stat/?election_id=1&candidate_id=1", nil, http.StatusOK},
+		{"election_candidate_stat", http.MethodGet, "/elections/1/stats/1", nil, http.StatusOK},
+		{"election_bad_id", http.MethodGet, "/elections/abc/stats", nil, http.StatusNotFound},
 	}
 
 	for _, c := range cases {
@@ -41,6 +60,9 @@ func TestMyHandler(t *testing.T) {
 			res, err := http.DefaultClient.Do(req)
 			require.NoError(t, err)
 			require.Equal(t, c.responseCode, res.StatusCode)
+			if res.StatusCode == http.StatusMethodNotAllowed {
+				require.Equal(t, "POST", res.Header.Get("Allow"))
+			}
 		})
 	}
 }
@@ -0,0 +1,232 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	_ VoteStore = (*File)(nil)
+	_ Batcher   = (*File)(nil)
+)
+
+// File is an append-only journal of votes, one JSON document per line.
+// Counters are rebuilt from the journal on open and kept in memory.
+type File struct {
+	lock  sync.RWMutex
+	path  string
+	f     *os.File
+	size  int64
+	stats elections
+}
+
+func NewFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open vote journal: %w", err)
+	}
+
+	s := &File{
+		path:  path,
+		f:     f,
+		stats: make(elections),
+	}
+	if err := s.replay(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// replay rebuilds counters from the journal and drops a torn last line
+// left by a crash in the middle of a write.
+func (s *File) replay() error {
+	offset, err := readJournal(s.f, -1, func(v Vote) error {
+		s.stats.count(v)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.f.Truncate(offset); err != nil {
+		return fmt.Errorf("cannot truncate torn vote journal record: %w", err)
+	}
+	if _, err := s.f.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("cannot seek vote journal: %w", err)
+	}
+	s.size = offset
+	return nil
+}
+
+// readJournal decodes complete records from r up to limit bytes (no limit if
+// negative) and returns the offset right after the last complete record.
+func readJournal(r io.Reader, limit int64, fn func(Vote) error) (int64, error) {
+	if limit >= 0 {
+		r = io.LimitReader(r, limit)
+	}
+	br := bufio.NewReader(r)
+	var offset int64
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, fmt.Errorf("cannot read vote journal: %w", err)
+		}
+
+		v := Vote{}
+		if err := json.Unmarshal(bytes.TrimSpace(line), &v); err != nil {
+			return offset, fmt.Errorf("corrupted vote journal record at offset %d: %w", offset, err)
+		}
+		if err := fn(v); err != nil {
+			return offset, err
+		}
+		offset += int64(len(line))
+	}
+}
+
+func (s *File) Add(_ context.Context, v Vote) error {
+	rec, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("cannot marshal vote: %w", err)
+	}
+	rec = append(rec, '\n')
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.f == nil {
+		return ErrClosed
+	}
+	if _, err := s.f.Write(rec); err != nil {
+		return fmt.Errorf("cannot write vote journal: %w", err)
+	}
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("cannot sync vote journal: %w", err)
+	}
+	s.size += int64(len(rec))
+	s.stats.count(v)
+	return nil
+}
+
+// AddAll writes the votes with one write and one sync. A failed write is
+// truncated, so no part of the batch is replayed on the next open.
+func (s *File) AddAll(_ context.Context, votes []Vote) error {
+	var buf bytes.Buffer
+	for _, v := range votes {
+		rec, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("cannot marshal vote: %w", err)
+		}
+		buf.Write(rec)
+		buf.WriteByte('\n')
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.f == nil {
+		return ErrClosed
+	}
+	_, err := s.f.Write(buf.Bytes())
+	if err == nil {
+		err = s.f.Sync()
+	}
+	if err != nil {
+		s.rollback()
+		return fmt.Errorf("cannot write vote journal: %w", err)
+	}
+	s.size += int64(buf.Len())
+	for _, v := range votes {
+		s.stats.count(v)
+	}
+	return nil
+}
+
+// rollback cuts the journal back to the last complete write, a failure is
+// left to replay, which drops a torn last line.
+func (s *File) rollback() {
+	if err := s.f.Truncate(s.size); err == nil {
+		s.f.Seek(s.size, io.SeekStart)
+	}
+}
+
+func (s *File) Stats(_ context.Context, electionId uint32) (map[uint32]uint32, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.f == nil {
+		return nil, ErrClosed
+	}
+	return copyStats(s.stats[electionId]), nil
+}
+
+func (s *File) CandidateStat(_ context.Context, electionId, candidateId uint32) (uint32, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.f == nil {
+		return 0, false, ErrClosed
+	}
+	stat, ok := s.stats[electionId][candidateId]
+	return stat, ok, nil
+}
+
+// Votes reads the journal from a separate descriptor, so writers are not
+// blocked. Votes added after the call started are not visited.
+func (s *File) Votes(_ context.Context, fn func(Vote) error) error {
+	s.lock.RLock()
+	if s.f == nil {
+		s.lock.RUnlock()
+		return ErrClosed
+	}
+	size := s.size
+	s.lock.RUnlock()
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("cannot open vote journal: %w", err)
+	}
+	defer f.Close()
+
+	_, err = readJournal(f, size, fn)
+	return err
+}
+
+// ReadFile calls fn for every vote of the journal at path without opening
+// it as a store, so it is safe while a server appends to the journal. A
+// torn last line is skipped.
+func ReadFile(ctx context.Context, path string, fn func(Vote) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot open vote journal: %w", err)
+	}
+	defer f.Close()
+
+	_, err = readJournal(f, -1, func(v Vote) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(v)
+	})
+	return err
+}
+
+func (s *File) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
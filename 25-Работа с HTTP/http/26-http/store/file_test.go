@@ -0,0 +1,69 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/store"
+	"github.com/OtusGolang/webinars_practical_part/26-http/store/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.VoteStore {
+		s, err := store.NewFile(filepath.Join(t.TempDir(), "votes.jsonl"))
+		require.NoError(t, err)
+		return s
+	})
+}
+
+func TestFile_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "votes.jsonl")
+	ctx := context.Background()
+
+	s, err := store.NewFile(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(ctx, store.Vote{ElectionId: 1, Passport: "a", CandidateId: 1, Time: time.Now()}))
+	require.NoError(t, s.Add(ctx, store.Vote{ElectionId: 1, Passport: "b", CandidateId: 2, Time: time.Now()}))
+	require.NoError(t, s.Close())
+
+	// simulate a crash in the middle of the next write
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"passport":"c","candi`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	var passports []string
+	err = store.ReadFile(ctx, path, func(v store.Vote) error {
+		passports = append(passports, v.Passport)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, passports, "the torn line is skipped")
+
+	s, err = store.NewFile(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	stats, err := s.Stats(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, map[uint32]uint32{1: 1, 2: 1}, stats)
+
+	require.NoError(t, s.Add(ctx, store.Vote{ElectionId: 1, Passport: "c", CandidateId: 2, Time: time.Now()}))
+	stat, ok, err := s.CandidateStat(ctx, 1, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint32(2), stat)
+}
+
+func TestFile_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "votes.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("not a vote\n"), 0o644))
+
+	_, err := store.NewFile(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	_ VoteStore = (*Memory)(nil)
+	_ Batcher   = (*Memory)(nil)
+)
+
+type Memory struct {
+	lock   sync.RWMutex
+	stats  elections
+	votes  []Vote
+	closed bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		stats: make(elections),
+	}
+}
+
+func (m *Memory) Add(_ context.Context, v Vote) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	m.votes = append(m.votes, v)
+	m.stats.count(v)
+	return nil
+}
+
+func (m *Memory) AddAll(_ context.Context, votes []Vote) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	m.votes = append(m.votes, votes...)
+	for _, v := range votes {
+		m.stats.count(v)
+	}
+	return nil
+}
+
+func (m *Memory) Stats(_ context.Context, electionId uint32) (map[uint32]uint32, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if m.closed {
+		return nil, ErrClosed
+	}
+	return copyStats(m.stats[electionId]), nil
+}
+
+func (m *Memory) CandidateStat(_ context.Context, electionId, candidateId uint32) (uint32, bool, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if m.closed {
+		return 0, false, ErrClosed
+	}
+	stat, ok := m.stats[electionId][candidateId]
+	return stat, ok, nil
+}
+
+func (m *Memory) Votes(_ context.Context, fn func(Vote) error) error {
+	m.lock.RLock()
+	if m.closed {
+		m.lock.RUnlock()
+		return ErrClosed
+	}
+	votes := m.votes[:len(m.votes):len(m.votes)]
+	m.lock.RUnlock()
+
+	for _, v := range votes {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Close() error {
+	m.lock.Lock()
+	m.closed = true
+	m.lock.Unlock()
+	return nil
+}
+
+func copyStats(src map[uint32]uint32) map[uint32]uint32 {
+	stats := make(map[uint32]uint32, len(src))
+	for k, v := range src {
+		stats[k] = v
+	}
+	return stats
+}
+
+// elections holds candidate counters of every election.
+type elections map[uint32]map[uint32]uint32
+
+func (e elections) count(v Vote) {
+	stats, ok := e[v.ElectionId]
+	if !ok {
+		stats = make(map[uint32]uint32)
+		e[v.ElectionId] = stats
+	}
+	stats[v.CandidateId]++
+	if v.Replaces != 0 && stats[v.Replaces] > 0 {
+		stats[v.Replaces]--
+	}
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+var (
+	_ VoteStore = (*Postgres)(nil)
+	_ Batcher   = (*Postgres)(nil)
+)
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS votes (
+	id           BIGSERIAL PRIMARY KEY,
+	passport     TEXT        NOT NULL,
+	candidate_id BIGINT      NOT NULL,
+	note         TEXT        NOT NULL DEFAULT '',
+	time         TIMESTAMPTZ NOT NULL,
+	replaces     BIGINT      NOT NULL DEFAULT 0
+);
+-- votes stored before elections were introduced belong to election 0
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS election_id BIGINT NOT NULL DEFAULT 0;
+DROP INDEX IF EXISTS votes_candidate_id_idx;
+CREATE INDEX IF NOT EXISTS votes_election_candidate_idx ON votes (election_id, candidate_id);
+`
+
+type Postgres struct {
+	db     *sql.DB
+	closed atomic.Bool
+}
+
+func NewPostgres(ctx context.Context, dsn string) (*Postgres, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open pgx driver: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot connect to postgres: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, postgresSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create votes schema: %w", err)
+	}
+	return &Postgres{db: db}, nil
+}
+
+const insertVote = `INSERT INTO votes (election_id, passport, candidate_id, note, time, replaces) VALUES ($1, $2, $3, $4, $5, $6)`
+
+func (p *Postgres) Add(ctx context.Context, v Vote) error {
+	if p.closed.Load() {
+		return ErrClosed
+	}
+	_, err := p.db.ExecContext(ctx, insertVote,
+		int64(v.ElectionId), v.Passport, int64(v.CandidateId), v.Note, v.Time, int64(v.Replaces),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot insert vote: %w", err)
+	}
+	return nil
+}
+
+// AddAll inserts the votes in one transaction.
+func (p *Postgres) AddAll(ctx context.Context, votes []Vote) error {
+	if p.closed.Load() {
+		return ErrClosed
+	}
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("cannot begin votes transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertVote)
+	if err != nil {
+		return fmt.Errorf("cannot prepare vote insert: %w", err)
+	}
+	defer stmt.Close()
+	for _, v := range votes {
+		_, err := stmt.ExecContext(ctx,
+			int64(v.ElectionId), v.Passport, int64(v.CandidateId), v.Note, v.Time, int64(v.Replaces),
+		)
+		if err != nil {
+			return fmt.Errorf("cannot insert vote: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("cannot commit votes: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) Stats(ctx context.Context, electionId uint32) (map[uint32]uint32, error) {
+	if p.closed.Load() {
+		return nil, ErrClosed
+	}
+	// a revote withdraws one vote from the replaced candidate
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, sum(n) FROM (
+			SELECT candidate_id AS id, count(*) AS n FROM votes
+			WHERE election_id = $1 GROUP BY candidate_id
+			UNION ALL
+			SELECT replaces, -count(*) FROM votes
+			WHERE election_id = $1 AND replaces <> 0 GROUP BY replaces
+		) AS t GROUP BY id
+	`, int64(electionId))
+	if err != nil {
+		return nil, fmt.Errorf("cannot select stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[uint32]uint32)
+	for rows.Next() {
+		var id, cnt int64
+		if err := rows.Scan(&id, &cnt); err != nil {
+			return nil, fmt.Errorf("cannot scan stats: %w", err)
+		}
+		stats[uint32(id)] = uint32(cnt)
+	}
+	return stats, rows.Err()
+}
+
+func (p *Postgres) CandidateStat(ctx context.Context, electionId, candidateId uint32) (uint32, bool, error) {
+	if p.closed.Load() {
+		return 0, false, ErrClosed
+	}
+	var cnt, withdrawn int64
+	err := p.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT count(*) FROM votes WHERE election_id = $1 AND candidate_id = $2),
+			(SELECT count(*) FROM votes WHERE election_id = $1 AND replaces = $2)
+	`, int64(electionId), int64(candidateId)).Scan(&cnt, &withdrawn)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot select candidate stat: %w", err)
+	}
+	return uint32(cnt - withdrawn), cnt > 0, nil
+}
+
+func (p *Postgres) Votes(ctx context.Context, fn func(Vote) error) error {
+	if p.closed.Load() {
+		return ErrClosed
+	}
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT election_id, passport, candidate_id, note, time, replaces FROM votes ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("cannot select votes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v Vote
+		var electionId, candidateId, replaces int64
+		if err := rows.Scan(&electionId, &v.Passport, &candidateId, &v.Note, &v.Time, &replaces); err != nil {
+			return fmt.Errorf("cannot scan vote: %w", err)
+		}
+		v.ElectionId, v.CandidateId, v.Replaces = uint32(electionId), uint32(candidateId), uint32(replaces)
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (p *Postgres) Close() error {
+	if p.closed.Swap(true) {
+		return nil
+	}
+	return p.db.Close()
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrClosed = errors.New("vote store is closed")
+
+type Vote struct {
+	ElectionId  uint32    `json:"election_id"`
+	Passport    string    `json:"passport"`
+	CandidateId uint32    `json:"candidate_id"`
+	Note        string    `json:"note,omitempty"`
+	Time        time.Time `json:"time"`
+	// Replaces is the candidate whose earlier vote from the same passport
+	// is withdrawn by this one, 0 if nothing is withdrawn.
+	Replaces uint32 `json:"replaces,omitempty"`
+}
+
+// VoteStore keeps accepted votes and answers stat queries over them,
+// stats are counted per election. Implementations must be safe for
+// concurrent use.
+type VoteStore interface {
+	Add(ctx context.Context, v Vote) error
+	Stats(ctx context.Context, electionId uint32) (map[uint32]uint32, error)
+	CandidateStat(ctx context.Context, electionId, candidateId uint32) (stat uint32, ok bool, err error)
+	// Votes calls fn for every vote stored when the call started, in the
+	// order they were added. Votes added meanwhile are not visited, so the
+	// votes seen are a consistent snapshot.
+	Votes(ctx context.Context, fn func(Vote) error) error
+	Close() error
+}
+
+// Batcher is implemented by stores adding many votes at once: either all
+// of them are stored or none.
+type Batcher interface {
+	AddAll(ctx context.Context, votes []Vote) error
+}
+
+// AddAll stores the votes with one AddAll call if the store is a Batcher.
+// Otherwise they are added one by one and a failure leaves the votes
+// before it stored.
+func AddAll(ctx context.Context, s VoteStore, votes []Vote) error {
+	if b, ok := s.(Batcher); ok {
+		return b.AddAll(ctx, votes)
+	}
+	for _, v := range votes {
+		if err := s.Add(ctx, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Package storetest contains the conformance suite every store.VoteStore
+// implementation has to pass.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/26-http/store"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty store. The suite closes it itself.
+type Factory func(t *testing.T) store.VoteStore
+
+func Run(t *testing.T, newStore Factory) {
+	t.Run("empty", func(t *testing.T) {
+		s := newStore(t)
+		defer s.Close()
+
+		stats, err := s.Stats(context.Background(), 1)
+		require.NoError(t, err)
+		require.Empty(t, stats)
+
+		_, ok, err := s.CandidateStat(context.Background(), 1, 1)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("add", func(t *testing.T) {
+		s := newStore(t)
+		defer s.Close()
+
+		ctx := context.Background()
+		for _, id := range []uint32{1, 2, 1, 3, 1} {
+			require.NoError(t, s.Add(ctx, vote(id)))
+		}
+
+		stats, err := s.Stats(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, map[uint32]uint32{1: 3, 2: 1, 3: 1}, stats)
+
+		stat, ok, err := s.CandidateStat(ctx, 1, 1)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, uint32(3), stat)
+
+		_, ok, err = s.CandidateStat(ctx, 1, 4)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("stats_is_a_copy", func(t *testing.T) {
+		s := newStore(t)
+		defer s.Close()
+
+		ctx := context.Background()
+		require.NoError(t, s.Add(ctx, vote(1)))
+
+		stats, err := s.Stats(ctx, 1)
+		require.NoError(t, err)
+		stats[1] = 100
+
+		stat, _, err := s.CandidateStat(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), stat)
+	})
+
+	t.Run("revote", func(t *testing.T) {
+		s := newStore(t)
+		defer s.Close()
+
+		ctx := context.Background()
+		require.NoError(t, s.Add(ctx, vote(1)))
+		revote := vote(2)
+		revote.Replaces = 1
+		require.NoError(t, s.Add(ctx, revote))
+
+		stats, err := s.Stats(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, map[uint32]uint32{1: 0, 2: 1}, stats)
+	})
+
+	t.Run("elections", func(t *testing.T) {
+		s := newStore(t)
+		defer s.Close()
+
+		ctx := context.Background()
+		require.NoError(t, s.Add(ctx, vote(1)))
+		other := vote(1)
+		other.ElectionId = 2
+		require.NoError(t, s.Add(ctx, other))
+		other.CandidateId = 2
+		other.Replaces = 1
+		require.NoError(t, s.Add(ctx, other))
+
+		stats, err := s.Stats(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, map[uint32]uint32{1: 1}, stats)
+
+		stats, err = s.Stats(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, map[uint32]uint32{1: 0, 2: 1}, stats)
+
+		_, ok, err := s.CandidateStat(ctx, 3, 1)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		var elections []uint32
+		err = s.Votes(ctx, func(v store.Vote) error {
+			elections = append(elections, v.ElectionId)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []uint32{1, 2, 2}, elections)
+	})
+
+	t.Run("votes", func(t *testing.T) {
+		s := newStore(t)
+		defer s.Close()
+
+		ctx := context.Background()
+		for _, id := range []uint32{3, 1, 2} {
+			require.NoError(t, s.Add(ctx, vote(id)))
+		}
+
+		var ids []uint32
+		err := s.Votes(ctx, func(v store.Vote) error {
+			require.Equal(t, "test", v.Passport)
+			ids = append(ids, v.CandidateId)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []uint32{3, 1, 2}, ids)
+
+		errStop := errors.New("stop")
+		err = s.Votes(ctx, func(store.Vote) error { return errStop })
+		require.ErrorIs(t, err, errStop)
+	})
+
+	t.Run("add_all", func(t *testing.T) {
+		s := newStore(t)
+		defer s.Close()
+
+		ctx := context.Background()
+		require.NoError(t, s.Add(ctx, vote(1)))
+		revote := vote(2)
+		revote.Replaces = 1
+		require.NoError(t, store.AddAll(ctx, s, []store.Vote{vote(3), revote, vote(3)}))
+
+		stats, err := s.Stats(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, map[uint32]uint32{1: 0, 2: 1, 3: 2}, stats)
+
+		var ids []uint32
+		err = s.Votes(ctx, func(v store.Vote) error {
+			ids = append(ids, v.CandidateId)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []uint32{1, 3, 2, 3}, ids)
+	})
+
+	t.Run("votes_snapshot", func(t *testing.T) {
+		s := newStore(t)
+		defer s.Close()
+
+		ctx := context.Background()
+		require.NoError(t, s.Add(ctx, vote(1)))
+		require.NoError(t, s.Add(ctx, vote(2)))
+
+		visited := 0
+		err := s.Votes(ctx, func(store.Vote) error {
+			visited++
+			return s.Add(ctx, vote(3))
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, visited, "votes added during the call are not visited")
+	})
+
+	t.Run("concurrent_add", func(t *testing.T) {
+		s := newStore(t)
+		defer s.Close()
+
+		const workers, votes = 8, 25
+		ctx := context.Background()
+		wg := sync.WaitGroup{}
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(id uint32) {
+				defer wg.Done()
+				for i := 0; i < votes; i++ {
+					if err := s.Add(ctx, vote(id%2+1)); err != nil {
+						t.Error(err)
+						return
+					}
+					if _, err := s.Stats(ctx, 1); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}(uint32(w))
+		}
+		wg.Wait()
+
+		stats, err := s.Stats(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, map[uint32]uint32{1: workers / 2 * votes, 2: workers / 2 * votes}, stats)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		s := newStore(t)
+		require.NoError(t, s.Close())
+
+		err := s.Add(context.Background(), vote(1))
+		require.True(t, errors.Is(err, store.ErrClosed), "got %v", err)
+		err = store.AddAll(context.Background(), s, []store.Vote{vote(1)})
+		require.True(t, errors.Is(err, store.ErrClosed), "got %v", err)
+		_, err = s.Stats(context.Background(), 1)
+		require.True(t, errors.Is(err, store.ErrClosed), "got %v", err)
+	})
+}
+
+func vote(candidateId uint32) store.Vote {
+	return store.Vote{
+		ElectionId:  1,
+		Passport:    "test",
+		CandidateId: candidateId,
+		Time:        time.Now().UTC(),
+	}
+}
@@ -0,0 +1,450 @@
+// Package wshub pushes stats updates to subscribers only when the stats
+// change, instead of every subscriber polling on its own. Websocket
+// clients are served by the hub itself, other transports (SSE) subscribe
+// to the same events with Subscribe.
+package wshub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultSendQueue    = 16
+	defaultWriteTimeout = 10 * time.Second
+	defaultPongTimeout  = 60 * time.Second
+	maxMessageSize      = 512
+)
+
+var ErrClosed = errors.New("websocket hub is closed")
+
+// SnapshotFunc returns the current state sent to every subscriber.
+type SnapshotFunc func(ctx context.Context) (any, error)
+
+// Event is one broadcasted snapshot. Value is what SnapshotFunc returned,
+// Data is Value encoded as JSON once for all subscribers.
+type Event struct {
+	// Id is unique across hub restarts, so a client resuming with an id
+	// from a previous process always gets a fresh snapshot.
+	Id    string
+	Value any
+	Data  []byte
+}
+
+type subscriber struct {
+	send     chan Event
+	conn     *websocket.Conn
+	closeMsg []byte
+}
+
+// Subscription receives events until it is closed, the hub shuts down or
+// the subscriber falls more than SendQueue events behind. C is closed in
+// all these cases.
+type Subscription struct {
+	C <-chan Event
+
+	hub *Hub
+	sub *subscriber
+}
+
+func (s *Subscription) Close() {
+	s.hub.remove(s.sub, websocket.CloseNormalClosure, "")
+}
+
+// Hub fans a single snapshot out to every subscriber. Tunables can be
+// changed after NewHub until the first Notify, Subscribe or ServeWS call.
+type Hub struct {
+	// Coalesce delays a broadcast to merge votes arriving within the window,
+	// zero pushes every change right away.
+	Coalesce time.Duration
+	// SendQueue is the number of events buffered per subscriber, a subscriber
+	// falling further behind is disconnected.
+	SendQueue    int
+	WriteTimeout time.Duration
+	// PongTimeout is how long a silent websocket peer is considered alive,
+	// pings are sent at 9/10 of it.
+	PongTimeout time.Duration
+	// Gauge, if set, follows the number of subscribers like Clients, e.g.
+	// a prometheus.Gauge.
+	Gauge interface {
+		Inc()
+		Dec()
+	}
+
+	upgrader websocket.Upgrader
+	snapshot SnapshotFunc
+	epoch    int64
+
+	startOnce sync.Once
+	notify    chan struct{}
+	stop      chan struct{}
+	loopDone  chan struct{}
+
+	lock sync.Mutex
+	subs map[*subscriber]struct{}
+	// conns are the websockets until their write loop closes them, a
+	// socket stays here after its subscriber is removed
+	conns  map[*websocket.Conn]struct{}
+	seq    uint64
+	last   *Event
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewHub(snapshot SnapshotFunc) *Hub {
+	return &Hub{
+		SendQueue:    defaultSendQueue,
+		WriteTimeout: defaultWriteTimeout,
+		PongTimeout:  defaultPongTimeout,
+		upgrader:     websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024},
+		snapshot:     snapshot,
+		epoch:        time.Now().UnixNano(),
+		notify:       make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+		loopDone:     make(chan struct{}),
+		subs:         make(map[*subscriber]struct{}),
+		conns:        make(map[*websocket.Conn]struct{}),
+	}
+}
+
+// Notify tells the hub that stats changed. It never blocks.
+func (h *Hub) Notify() {
+	h.start()
+	select {
+	case h.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Clients returns the number of subscribers, websocket or not.
+func (h *Hub) Clients() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.subs)
+}
+
+// Current returns the last broadcasted event, building one if nothing was
+// broadcasted yet.
+func (h *Hub) Current(ctx context.Context) (Event, error) {
+	h.lock.Lock()
+	last := h.last
+	h.lock.Unlock()
+	if last != nil {
+		return *last, nil
+	}
+
+	e, err := h.build(ctx)
+	if err != nil {
+		return Event{}, err
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.last == nil {
+		h.last = &e
+	}
+	return *h.last, nil
+}
+
+// Subscribe registers an in-process subscriber. The current snapshot is not
+// sent, use Current for it.
+func (h *Hub) Subscribe() (*Subscription, error) {
+	h.start()
+
+	sub := &subscriber{send: make(chan Event, h.SendQueue)}
+	if !h.register(context.Background(), sub, 0, false) {
+		return nil, ErrClosed
+	}
+	return &Subscription{C: sub.send, hub: h, sub: sub}, nil
+}
+
+// ServeWS upgrades the connection and subscribes it to updates. The current
+// snapshot is sent right after the upgrade.
+func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+	h.start()
+
+	h.lock.Lock()
+	closed := h.closed
+	h.lock.Unlock()
+	if closed {
+		http.Error(w, ErrClosed.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// upgrader has already written the response
+		slog.WarnContext(r.Context(), "web socket upgrade failed", "err", err)
+		return
+	}
+
+	sub := &subscriber{
+		conn: conn,
+		send: make(chan Event, h.SendQueue),
+	}
+	if !h.register(r.Context(), sub, 2, true) {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutdown"),
+			time.Now().Add(h.WriteTimeout))
+		conn.Close()
+		return
+	}
+	slog.InfoContext(r.Context(), "web socket connected", "remote_addr", conn.RemoteAddr())
+
+	go h.writeLoop(sub)
+	go h.readLoop(sub)
+}
+
+// Close sends a close frame to every socket, closes every subscription and
+// waits for sockets to finish, sockets still open when ctx is done are
+// closed forcibly.
+func (h *Hub) Close(ctx context.Context) error {
+	h.start()
+
+	h.lock.Lock()
+	if h.closed {
+		h.lock.Unlock()
+		return nil
+	}
+	h.closed = true
+	for sub := range h.subs {
+		h.removeLocked(sub, websocket.CloseGoingAway, "server shutdown")
+	}
+	h.lock.Unlock()
+
+	close(h.stop)
+	<-h.loopDone
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// unblock the writes to peers that stopped reading
+		h.lock.Lock()
+		for conn := range h.conns {
+			conn.Close()
+		}
+		h.lock.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (h *Hub) start() {
+	h.startOnce.Do(func() {
+		go h.loop()
+	})
+}
+
+func (h *Hub) loop() {
+	defer close(h.loopDone)
+
+	var timer *time.Timer
+	var fire <-chan time.Time
+	for {
+		select {
+		case <-h.stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case <-h.notify:
+			if h.Coalesce <= 0 {
+				h.broadcast()
+				continue
+			}
+			if fire == nil {
+				timer = time.NewTimer(h.Coalesce)
+				fire = timer.C
+			}
+
+		case <-fire:
+			fire = nil
+			h.broadcast()
+		}
+	}
+}
+
+func (h *Hub) broadcast() {
+	e, err := h.build(context.Background())
+	if err != nil {
+		slog.Error("unable to build stats snapshot", "err", err)
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.last = &e
+	for sub := range h.subs {
+		h.enqueueLocked(sub, e)
+	}
+}
+
+func (h *Hub) build(ctx context.Context) (Event, error) {
+	value, err := h.snapshot(ctx)
+	if err != nil {
+		return Event{}, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Event{}, err
+	}
+
+	h.lock.Lock()
+	h.seq++
+	id := fmt.Sprintf("%d-%d", h.epoch, h.seq)
+	h.lock.Unlock()
+
+	return Event{Id: id, Value: value, Data: data}, nil
+}
+
+// register adds the subscriber, with current set the last event is queued
+// to it under the same lock, so a broadcast can't slip in between and be
+// followed by an older snapshot. It is built first if nothing was
+// broadcasted yet.
+func (h *Hub) register(ctx context.Context, sub *subscriber, loops int, current bool) bool {
+	var built *Event
+	if current {
+		h.lock.Lock()
+		missing := h.last == nil
+		h.lock.Unlock()
+		if missing {
+			if e, err := h.build(ctx); err != nil {
+				slog.ErrorContext(ctx, "unable to build stats snapshot", "err", err)
+			} else {
+				built = &e
+			}
+		}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return false
+	}
+	h.subs[sub] = struct{}{}
+	if sub.conn != nil {
+		h.conns[sub.conn] = struct{}{}
+	}
+	if h.Gauge != nil {
+		h.Gauge.Inc()
+	}
+	h.wg.Add(loops)
+	if current {
+		// a broadcast may have set it while building
+		if h.last == nil {
+			h.last = built
+		}
+		if h.last != nil {
+			h.enqueueLocked(sub, *h.last)
+		}
+	}
+	return true
+}
+
+func (h *Hub) enqueueLocked(sub *subscriber, e Event) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	select {
+	case sub.send <- e:
+	default:
+		slog.Warn("stats subscriber is too slow, disconnect it")
+		h.removeLocked(sub, websocket.ClosePolicyViolation, "client is too slow")
+	}
+}
+
+func (h *Hub) remove(sub *subscriber, code int, text string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.removeLocked(sub, code, text)
+}
+
+// removeLocked unsubscribes and closes the send queue. For websocket
+// clients the write loop then sends the close frame and closes the
+// connection.
+func (h *Hub) removeLocked(sub *subscriber, code int, text string) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	if h.Gauge != nil {
+		h.Gauge.Dec()
+	}
+	sub.closeMsg = websocket.FormatCloseMessage(code, text)
+	close(sub.send)
+}
+
+func (h *Hub) writeLoop(sub *subscriber) {
+	defer h.wg.Done()
+	defer func() {
+		sub.conn.Close()
+		h.lock.Lock()
+		delete(h.conns, sub.conn)
+		h.lock.Unlock()
+		slog.Info("web socket closed", "remote_addr", sub.conn.RemoteAddr())
+	}()
+
+	ping := time.NewTicker(h.PongTimeout * 9 / 10)
+	defer ping.Stop()
+
+	for {
+		select {
+		case e, ok := <-sub.send:
+			sub.conn.SetWriteDeadline(time.Now().Add(h.WriteTimeout))
+			if !ok {
+				sub.conn.WriteMessage(websocket.CloseMessage, sub.closeMsg)
+				return
+			}
+			if err := sub.conn.WriteMessage(websocket.TextMessage, e.Data); err != nil {
+				h.remove(sub, websocket.CloseAbnormalClosure, "")
+				return
+			}
+
+		case <-ping.C:
+			err := sub.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.WriteTimeout))
+			if err != nil {
+				h.remove(sub, websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+// readLoop only watches the peer: it handles pongs and close frames and
+// drops the client once nothing was heard for PongTimeout.
+func (h *Hub) readLoop(sub *subscriber) {
+	defer h.wg.Done()
+
+	sub.conn.SetReadLimit(maxMessageSize)
+	sub.conn.SetReadDeadline(time.Now().Add(h.PongTimeout))
+	sub.conn.SetPongHandler(func(string) error {
+		return sub.conn.SetReadDeadline(time.Now().Add(h.PongTimeout))
+	})
+
+	for {
+		if _, _, err := sub.conn.ReadMessage(); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				slog.Warn("web socket read failed", "remote_addr", sub.conn.RemoteAddr(), "err", err)
+			}
+			h.remove(sub, websocket.CloseNormalClosure, "")
+			return
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package wshub
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHub(t *testing.T) (*Hub, *int64, string) {
+	var version int64
+	h := NewHub(func(context.Context) (any, error) {
+		return atomic.LoadInt64(&version), nil
+	})
+	ts := httptest.NewServer(http.HandlerFunc(h.ServeWS))
+	t.Cleanup(ts.Close)
+	return h, &version, "ws" + strings.TrimPrefix(ts.URL, "http")
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func read(t *testing.T, conn *websocket.Conn) string {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	return string(msg)
+}
+
+func TestHub_Push(t *testing.T) {
+	h, version, url := newTestHub(t)
+	defer h.Close(context.Background())
+
+	conn := dial(t, url)
+	require.Equal(t, "0", read(t, conn), "initial snapshot")
+
+	atomic.StoreInt64(version, 1)
+	h.Notify()
+	require.Equal(t, "1", read(t, conn))
+	require.Equal(t, 1, h.Clients())
+}
+
+func TestHub_PushWhileConnecting(t *testing.T) {
+	var calls int64
+	started, release := make(chan struct{}), make(chan struct{})
+	h := NewHub(func(context.Context) (any, error) {
+		n := atomic.AddInt64(&calls, 1)
+		if n == 1 {
+			close(started)
+			<-release
+		}
+		return n, nil
+	})
+	ts := httptest.NewServer(http.HandlerFunc(h.ServeWS))
+	defer ts.Close()
+	defer h.Close(context.Background())
+
+	// a broadcast lands while the first snapshot of a socket is built
+	conn := dial(t, "ws"+strings.TrimPrefix(ts.URL, "http"))
+	<-started
+	h.Notify()
+	require.Eventually(t, func() bool {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		return h.last != nil
+	}, 2*time.Second, 10*time.Millisecond)
+	close(release)
+
+	require.Equal(t, "2", read(t, conn), "the newer snapshot, once")
+	h.Notify()
+	require.Equal(t, "3", read(t, conn))
+}
+
+func TestHub_Coalesce(t *testing.T) {
+	h, version, url := newTestHub(t)
+	h.Coalesce = 100 * time.Millisecond
+	defer h.Close(context.Background())
+
+	conn := dial(t, url)
+	require.Equal(t, "0", read(t, conn))
+
+	for i := 1; i <= 5; i++ {
+		atomic.StoreInt64(version, int64(i))
+		h.Notify()
+	}
+	require.Equal(t, "5", read(t, conn))
+
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, _, err := conn.ReadMessage()
+	require.Error(t, err, "updates within the window must be merged")
+}
+
+func TestHub_DeadPeer(t *testing.T) {
+	h, _, url := newTestHub(t)
+	h.PongTimeout = 200 * time.Millisecond
+	defer h.Close(context.Background())
+
+	conn := dial(t, url)
+	// don't answer pings
+	conn.SetPingHandler(func(string) error { return nil })
+	require.Equal(t, "0", read(t, conn))
+
+	require.Eventually(t, func() bool { return h.Clients() == 0 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestHub_Close(t *testing.T) {
+	h, _, url := newTestHub(t)
+
+	conn := dial(t, url)
+	require.Equal(t, "0", read(t, conn))
+
+	closed := make(chan error, 1)
+	go func() {
+		_, _, err := conn.ReadMessage()
+		closed <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, h.Close(ctx))
+
+	err := <-closed
+	require.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "got %v", err)
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+// TestHub_CloseTimeout closes the sockets still writing to a peer that
+// stopped reading once Close gives up.
+func TestHub_CloseTimeout(t *testing.T) {
+	payload := strings.Repeat("x", 1<<20)
+	h := NewHub(func(context.Context) (any, error) { return payload, nil })
+	h.WriteTimeout = time.Minute
+	ts := httptest.NewServer(http.HandlerFunc(h.ServeWS))
+	t.Cleanup(ts.Close)
+	dial(t, "ws"+strings.TrimPrefix(ts.URL, "http"))
+
+	// fill the socket buffers until a write blocks
+	for i := 0; i < 10; i++ {
+		h.Notify()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, h.Close(ctx), context.DeadlineExceeded)
+	require.Eventually(t, func() bool {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		return len(h.conns) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHub_Subscribe(t *testing.T) {
+	h, version, _ := newTestHub(t)
+
+	sub, err := h.Subscribe()
+	require.NoError(t, err)
+
+	first, err := h.Current(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(0), first.Value)
+
+	atomic.StoreInt64(version, 1)
+	h.Notify()
+	select {
+	case e := <-sub.C:
+		require.Equal(t, int64(1), e.Value)
+		require.Equal(t, "1", string(e.Data))
+		require.NotEqual(t, first.Id, e.Id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event")
+	}
+
+	require.NoError(t, h.Close(context.Background()))
+	_, ok := <-sub.C
+	require.False(t, ok, "subscription must be closed on shutdown")
+
+	_, err = h.Subscribe()
+	require.ErrorIs(t, err, ErrClosed)
+}
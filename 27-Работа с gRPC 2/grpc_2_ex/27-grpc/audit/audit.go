@@ -0,0 +1,378 @@
+// Package audit keeps a tamper-evident log of accepted votes for every
+// entry point (HTTP and gRPC). Records are chained: the hash of a record
+// covers its vote and the hash of the record before it, so changing,
+// removing or reordering a record breaks every hash after it. Passports are
+// stored as keyed hashes, the log can be published without them.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/dedup"
+)
+
+var (
+	// ErrBroken is reported for a record that doesn't continue the chain.
+	ErrBroken = errors.New("audit log is broken")
+	// ErrNotFound is reported by Prove for an index beyond the log.
+	ErrNotFound = errors.New("audit record not found")
+	// ErrMismatch is reported for a receipt whose hash differs from the
+	// record at its index.
+	ErrMismatch = errors.New("receipt doesn't match the audit record")
+	ErrClosed   = errors.New("audit log is closed")
+)
+
+// genesis is the previous hash of the first record.
+var genesis = hex.EncodeToString(make([]byte, sha256.Size))
+
+// Entry is an accepted vote to append.
+type Entry struct {
+	ElectionId  uint32
+	Passport    string
+	CandidateId uint32
+	// Replaces is the candidate whose vote is withdrawn by a revote.
+	Replaces uint32
+	Time     time.Time
+}
+
+// Record is an entry as written to the log, one JSON document per line.
+// Hashes are hex encoded SHA-256.
+type Record struct {
+	Index       uint64 `json:"index"`
+	ElectionId  uint32 `json:"election_id"`
+	CandidateId uint32 `json:"candidate_id"`
+	Replaces    uint32 `json:"replaces,omitempty"`
+	// Voter is the HMAC-SHA256 of the normalized passport, equal for every
+	// vote of a passport and useless without the key.
+	Voter string    `json:"voter"`
+	Time  time.Time `json:"time"`
+	Prev  string    `json:"prev"`
+	Hash  string    `json:"hash"`
+}
+
+// Digest is the hex SHA-256 of the record fields except the hashes.
+func (r *Record) Digest() string {
+	var buf bytes.Buffer
+	buf.WriteString(strconv.FormatUint(r.Index, 10))
+	for _, field := range []string{
+		strconv.FormatUint(uint64(r.ElectionId), 10),
+		strconv.FormatUint(uint64(r.CandidateId), 10),
+		strconv.FormatUint(uint64(r.Replaces), 10),
+		r.Voter,
+		r.Time.UTC().Format(time.RFC3339Nano),
+	} {
+		buf.WriteByte('\n')
+		buf.WriteString(field)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// check returns ErrBroken unless the record is the one after prev.
+func (r *Record) check(index uint64, prev string) error {
+	switch {
+	case r.Index != index:
+		return fmt.Errorf("%w: expect record %d, got %d", ErrBroken, index, r.Index)
+	case r.Prev != prev:
+		return fmt.Errorf("%w: record %d doesn't follow the previous hash", ErrBroken, r.Index)
+	case r.Hash != chain(r.Prev, r.Digest()):
+		return fmt.Errorf("%w: record %d was modified", ErrBroken, r.Index)
+	}
+	return nil
+}
+
+// chain returns the hash of a record from the previous hash and the record
+// digest. Bad hex is hashed as is, the result just won't match.
+func chain(prev, digest string) string {
+	h := sha256.New()
+	for _, s := range []string{prev, digest} {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			b = []byte(s)
+		}
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Receipt identifies a record, it is given to the voter and to observers.
+type Receipt struct {
+	Index uint64 `json:"index" xml:"index"`
+	Hash  string `json:"hash" xml:"hash"`
+}
+
+// Log is an append-only chain of records. The records are kept in memory
+// for proofs, the file log also writes them to a file, one line each.
+type Log struct {
+	lock    sync.RWMutex
+	key     []byte
+	f       *os.File
+	size    int64
+	records []Record
+	closed  bool
+}
+
+// NewMemory returns a log that is lost on exit, for tests and demos.
+func NewMemory(key []byte) *Log {
+	return &Log{key: key}
+}
+
+// OpenFile opens or creates the log at path and checks the chain of the
+// records already there. A torn last line left by a crash is dropped.
+func OpenFile(path string, key []byte) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log: %w", err)
+	}
+
+	l := &Log{key: key, f: f}
+	offset, err := read(f, func(r Record) error {
+		l.records = append(l.records, r)
+		return nil
+	})
+	if err == nil {
+		err = f.Truncate(offset)
+	}
+	if err == nil {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	l.size = offset
+	return l, nil
+}
+
+// Verify reads a log and checks that every record continues the chain from
+// the first one, fn is called for every record if not nil. It returns the
+// receipt of the last record. A torn last line, like the one of a write in
+// progress, is skipped.
+func Verify(r io.Reader, fn func(Record) error) (head Receipt, err error) {
+	_, err = read(r, func(rec Record) error {
+		head = Receipt{Index: rec.Index, Hash: rec.Hash}
+		if fn != nil {
+			return fn(rec)
+		}
+		return nil
+	})
+	return head, err
+}
+
+// read decodes and checks complete records and returns the offset right
+// after the last one.
+func read(r io.Reader, fn func(Record) error) (int64, error) {
+	br := bufio.NewReader(r)
+	var offset int64
+	prev := genesis
+	for index := uint64(0); ; index++ {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, fmt.Errorf("cannot read audit log: %w", err)
+		}
+
+		rec := Record{}
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			return offset, fmt.Errorf("%w: record %d at offset %d: %v", ErrBroken, index, offset, err)
+		}
+		if err := rec.check(index, prev); err != nil {
+			return offset, err
+		}
+		if err := fn(rec); err != nil {
+			return offset, err
+		}
+		prev = rec.Hash
+		offset += int64(len(line))
+	}
+}
+
+// Voter returns the keyed hash of the passport as written to records.
+func (l *Log) Voter(passport string) string {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(dedup.Normalize(passport)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Append adds the entries with one write and one sync and returns their
+// receipts. Either every entry is appended or none.
+func (l *Log) Append(entries ...Entry) ([]Receipt, error) {
+	return l.AppendFunc(nil, entries...)
+}
+
+// AppendFunc is Append that calls commit with the receipts once the entries
+// are written, still holding the log. An error of commit takes the entries
+// back out and is returned; if they can't be cut from the file the log is
+// closed rather than keep records of what commit didn't take.
+func (l *Log) AppendFunc(commit func([]Receipt) error, entries ...Entry) ([]Receipt, error) {
+	voters := make([]string, len(entries))
+	for i, e := range entries {
+		voters[i] = l.Voter(e.Passport)
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.closed {
+		return nil, ErrClosed
+	}
+	prev := genesis
+	if n := len(l.records); n > 0 {
+		prev = l.records[n-1].Hash
+	}
+	records := make([]Record, len(entries))
+	receipts := make([]Receipt, len(entries))
+	var buf bytes.Buffer
+	for i, e := range entries {
+		rec := Record{
+			Index:       uint64(len(l.records) + i),
+			ElectionId:  e.ElectionId,
+			CandidateId: e.CandidateId,
+			Replaces:    e.Replaces,
+			Voter:       voters[i],
+			Time:        e.Time.UTC(),
+			Prev:        prev,
+		}
+		rec.Hash = chain(prev, rec.Digest())
+		prev = rec.Hash
+		records[i] = rec
+		receipts[i] = Receipt{Index: rec.Index, Hash: rec.Hash}
+
+		if l.f != nil {
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return nil, fmt.Errorf("cannot marshal audit record: %w", err)
+			}
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+	}
+
+	if l.f != nil {
+		_, err := l.f.Write(buf.Bytes())
+		if err == nil {
+			err = l.f.Sync()
+		}
+		if err != nil {
+			// cut back to the last complete write, a failure is left to
+			// OpenFile, which drops a torn last line
+			if err := l.f.Truncate(l.size); err == nil {
+				l.f.Seek(l.size, io.SeekStart)
+			}
+			return nil, fmt.Errorf("cannot write audit log: %w", err)
+		}
+	}
+	if commit != nil {
+		if err := commit(receipts); err != nil {
+			if l.f != nil {
+				if terr := l.f.Truncate(l.size); terr != nil {
+					l.closed = true
+					l.f.Close()
+					return nil, fmt.Errorf("%w, cannot roll back audit log: %v", err, terr)
+				}
+				l.f.Seek(l.size, io.SeekStart)
+			}
+			return nil, err
+		}
+	}
+	if l.f != nil {
+		l.size += int64(buf.Len())
+	}
+	l.records = append(l.records, records...)
+	return receipts, nil
+}
+
+// Head returns the receipt of the last record, false if the log is empty.
+// Observers publish it, proofs lead to it.
+func (l *Log) Head() (Receipt, bool) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	n := len(l.records)
+	if n == 0 {
+		return Receipt{}, false
+	}
+	return Receipt{Index: l.records[n-1].Index, Hash: l.records[n-1].Hash}, true
+}
+
+// Proof shows that a record is in the log: the hash of the record is
+// rebuilt from its fields, then the digests of the records after it lead
+// to the head. The head must be compared with one the verifier trusts,
+// like a head published by observers.
+type Proof struct {
+	Record  Record   `json:"record" xml:"record"`
+	Digests []string `json:"digests" xml:"digests>digest"`
+	Head    Receipt  `json:"head" xml:"head"`
+}
+
+// Prove returns the proof of the receipt up to the current head.
+func (l *Log) Prove(receipt Receipt) (*Proof, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if receipt.Index >= uint64(len(l.records)) {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, receipt.Index)
+	}
+	rec := l.records[receipt.Index]
+	if rec.Hash != receipt.Hash {
+		return nil, fmt.Errorf("%w: %d", ErrMismatch, receipt.Index)
+	}
+	p := &Proof{
+		Record:  rec,
+		Digests: make([]string, 0, len(l.records)-int(rec.Index)-1),
+	}
+	for _, r := range l.records[rec.Index+1:] {
+		p.Digests = append(p.Digests, r.Digest())
+	}
+	last := l.records[len(l.records)-1]
+	p.Head = Receipt{Index: last.Index, Hash: last.Hash}
+	return p, nil
+}
+
+// Verify checks that the proof leads from the receipt to p.Head.
+func (p *Proof) Verify(receipt Receipt) error {
+	rec := p.Record
+	if rec.Index != receipt.Index || rec.Hash != receipt.Hash {
+		return fmt.Errorf("%w: %d", ErrMismatch, receipt.Index)
+	}
+	if rec.Hash != chain(rec.Prev, rec.Digest()) {
+		return fmt.Errorf("%w: record %d was modified", ErrBroken, rec.Index)
+	}
+	hash := rec.Hash
+	for _, digest := range p.Digests {
+		hash = chain(hash, digest)
+	}
+	if p.Head.Index != rec.Index+uint64(len(p.Digests)) || p.Head.Hash != hash {
+		return fmt.Errorf("%w: proof of record %d doesn't lead to the head", ErrBroken, rec.Index)
+	}
+	return nil
+}
+
+// Close closes the file of the log, later appends fail with ErrClosed.
+func (l *Log) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
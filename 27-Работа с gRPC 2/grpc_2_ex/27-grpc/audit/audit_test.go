@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var key = []byte("test-key")
+
+func entries(n int) []Entry {
+	start := time.Date(2024, 9, 8, 8, 0, 0, 0, time.UTC)
+	list := make([]Entry, n)
+	for i := range list {
+		list[i] = Entry{
+			ElectionId:  1,
+			Passport:    string(rune('a' + i)),
+			CandidateId: uint32(i%3 + 1),
+			Time:        start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return list
+}
+
+func TestLog_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := OpenFile(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := entries(5)
+	first, err := l.Append(list[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest, err := l.Append(list[1:]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first[0].Index != 0 || rest[0].Index != 1 || rest[3].Index != 4 {
+		t.Fatalf("unexpected indexes %v %v", first, rest)
+	}
+	head, _ := l.Head()
+	l.Close()
+	if _, err := l.Append(list[0]); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+
+	// a crash in the middle of a write
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"index":5,"elec`)
+	f.Close()
+
+	l, err = OpenFile(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if got, _ := l.Head(); got != head {
+		t.Fatalf("expected head %v after reopen, got %v", head, got)
+	}
+	more, err := l.Append(entries(6)[5])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if more[0].Index != 5 {
+		t.Fatalf("expected index 5, got %d", more[0].Index)
+	}
+
+	data, _ := os.ReadFile(path)
+	n := 0
+	got, err := Verify(bytes.NewReader(data), func(Record) error {
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 || got != more[0] {
+		t.Fatalf("expected 6 records up to %v, got %d up to %v", more[0], n, got)
+	}
+}
+
+func TestLog_AppendFunc(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := OpenFile(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	list := entries(3)
+	failed := errors.New("not counted")
+	if _, err := l.AppendFunc(func(r []Receipt) error {
+		if len(r) != 2 || r[0].Index != 0 {
+			t.Errorf("unexpected receipts %v", r)
+		}
+		return failed
+	}, list[:2]...); !errors.Is(err, failed) {
+		t.Fatalf("expected the commit error, got %v", err)
+	}
+	if _, ok := l.Head(); ok {
+		t.Fatal("expected the entries to be taken back out")
+	}
+
+	receipts, err := l.AppendFunc(func([]Receipt) error { return nil }, list[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipts[0].Index != 0 {
+		t.Fatalf("expected index 0, got %d", receipts[0].Index)
+	}
+	data, _ := os.ReadFile(path)
+	n := 0
+	got, err := Verify(bytes.NewReader(data), func(Record) error {
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || got != receipts[0] {
+		t.Fatalf("expected 1 record up to %v, got %d up to %v", receipts[0], n, got)
+	}
+}
+
+func TestVerify_Tampered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := OpenFile(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(entries(4)...); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	data, _ := os.ReadFile(path)
+	lines := bytes.SplitAfter(data, []byte("\n"))[:4]
+
+	cases := map[string][]byte{
+		"modified": bytes.Join([][]byte{
+			lines[0], bytes.Replace(lines[1], []byte(`"candidate_id":2`), []byte(`"candidate_id":3`), 1), lines[2], lines[3],
+		}, nil),
+		"removed":   bytes.Join([][]byte{lines[0], lines[2], lines[3]}, nil),
+		"reordered": bytes.Join([][]byte{lines[0], lines[2], lines[1], lines[3]}, nil),
+		"first":     bytes.Join(lines[1:], nil),
+		"garbage":   bytes.Join([][]byte{lines[0], []byte("{}\n"), lines[1]}, nil),
+	}
+	for name, log := range cases {
+		if _, err := Verify(bytes.NewReader(log), nil); !errors.Is(err, ErrBroken) {
+			t.Errorf("%s: expected ErrBroken, got %v", name, err)
+		}
+		os.WriteFile(path, log, 0o644)
+		if _, err := OpenFile(path, key); !errors.Is(err, ErrBroken) {
+			t.Errorf("%s: expected OpenFile to fail with ErrBroken, got %v", name, err)
+		}
+	}
+}
+
+func TestLog_Prove(t *testing.T) {
+	l := NewMemory(key)
+	receipts, err := l.Append(entries(5)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, _ := l.Head()
+
+	for _, r := range receipts {
+		p, err := l.Prove(r)
+		if err != nil {
+			t.Fatalf("prove %d: %v", r.Index, err)
+		}
+		if p.Head != head {
+			t.Fatalf("proof of %d leads to %v, expected %v", r.Index, p.Head, head)
+		}
+		if err := p.Verify(r); err != nil {
+			t.Fatalf("verify %d: %v", r.Index, err)
+		}
+	}
+
+	p, _ := l.Prove(receipts[1])
+	p.Digests[0] = p.Digests[1]
+	if err := p.Verify(receipts[1]); !errors.Is(err, ErrBroken) {
+		t.Fatalf("expected ErrBroken for a changed digest, got %v", err)
+	}
+	p, _ = l.Prove(receipts[1])
+	p.Record.CandidateId = 3
+	if err := p.Verify(receipts[1]); !errors.Is(err, ErrBroken) {
+		t.Fatalf("expected ErrBroken for a changed record, got %v", err)
+	}
+	if err := p.Verify(receipts[2]); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("expected ErrMismatch for another receipt, got %v", err)
+	}
+
+	if _, err := l.Prove(Receipt{Index: 5}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := l.Prove(Receipt{Index: 1, Hash: receipts[2].Hash}); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("expected ErrMismatch, got %v", err)
+	}
+}
+
+func TestLog_Voter(t *testing.T) {
+	l := NewMemory(key)
+	if l.Voter(" ab123 ") != l.Voter("AB123") {
+		t.Fatal("expected the hash of the normalized passport")
+	}
+	if l.Voter("AB123") == NewMemory([]byte("other")).Voter("AB123") {
+		t.Fatal("expected the hash to depend on the key")
+	}
+	receipts, _ := l.Append(Entry{ElectionId: 1, Passport: "AB123", CandidateId: 1, Time: time.Now()})
+	p, _ := l.Prove(receipts[0])
+	if p.Record.Voter != l.Voter("AB123") {
+		t.Fatal("expected the record to hold the passport hash")
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+)
+
+// go run ./audit/verify -log audit.jsonl
+// go run ./audit/verify -log audit.jsonl -receipt 41:9f86d08...
+// curl '0.0.0.0:8080/audit/proof?index=41&hash=9f86d08...' | jq .data > proof.json
+// go run ./audit/verify -proof proof.json -receipt 41:9f86d08...
+//
+// A log cut after its last published head still verifies, so compare the
+// printed head with the published one or pass it with -receipt.
+
+var (
+	logFile   = flag.String("log", "", "audit log to check")
+	proofFile = flag.String("proof", "", "JSON inclusion proof to check against -receipt instead of a log")
+	receipt   = flag.String("receipt", "", "index:hash of a record that must be in the log or the proof")
+)
+
+func main() {
+	flag.Parse()
+
+	var want *audit.Receipt
+	if *receipt != "" {
+		r, err := parseReceipt(*receipt)
+		if err != nil {
+			log.Fatal(err)
+		}
+		want = &r
+	}
+
+	switch {
+	case *proofFile != "":
+		if want == nil {
+			log.Fatal("-proof needs -receipt")
+		}
+		if err := verifyProof(*proofFile, *want); err != nil {
+			log.Fatal(err)
+		}
+	case *logFile != "":
+		if err := verifyLog(*logFile, want); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatal("set -log or -proof")
+	}
+}
+
+func verifyLog(path string, want *audit.Receipt) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var found bool
+	var records uint64
+	head, err := audit.Verify(f, func(r audit.Record) error {
+		records++
+		if want == nil || r.Index != want.Index {
+			return nil
+		}
+		if r.Hash != want.Hash {
+			return fmt.Errorf("%w: %d", audit.ErrMismatch, r.Index)
+		}
+		found = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if want != nil && !found {
+		return fmt.Errorf("%w: %d, the log has %d records", audit.ErrNotFound, want.Index, records)
+	}
+	if records == 0 {
+		fmt.Println("ok: the log is empty")
+		return nil
+	}
+	fmt.Printf("ok: %d records, head %d:%s\n", records, head.Index, head.Hash)
+	return nil
+}
+
+func verifyProof(path string, want audit.Receipt) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	p := &audit.Proof{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return fmt.Errorf("cannot parse proof: %w", err)
+	}
+	if err := p.Verify(want); err != nil {
+		return err
+	}
+	fmt.Printf("ok: record %d leads to head %d:%s\n", want.Index, p.Head.Index, p.Head.Hash)
+	return nil
+}
+
+func parseReceipt(s string) (audit.Receipt, error) {
+	index, hash, ok := strings.Cut(s, ":")
+	n, err := strconv.ParseUint(index, 10, 64)
+	if !ok || err != nil || hash == "" {
+		return audit.Receipt{}, errors.New("expect -receipt as index:hash")
+	}
+	return audit.Receipt{Index: n, Hash: hash}, nil
+}
@@ -0,0 +1,238 @@
+// Package auth authenticates callers of the services by HMAC signed JWTs or
+// static API keys and checks their roles.
+//
+// Credentials come in the authorization header or gRPC metadata as
+// "Bearer <jwt>" or "ApiKey <key>".
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnauthenticated means there are no valid credentials, the other
+	// authentication errors wrap it.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrInvalidToken    = fmt.Errorf("%w: invalid token", ErrUnauthenticated)
+	ErrExpired         = fmt.Errorf("%w: token expired", ErrUnauthenticated)
+	ErrUnknownAPIKey   = fmt.Errorf("%w: unknown api key", ErrUnauthenticated)
+	// ErrForbidden means the caller is known but lacks the role.
+	ErrForbidden = errors.New("forbidden")
+)
+
+type Role string
+
+const (
+	// RoleVoter may submit votes.
+	RoleVoter Role = "voter"
+	// RoleObserver may watch stats.
+	RoleObserver Role = "observer"
+	// RoleAdmin may do anything, including managing candidates and
+	// elections.
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) valid() bool {
+	return r == RoleVoter || r == RoleObserver || r == RoleAdmin
+}
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Subject string `json:"subject"`
+	Roles   []Role `json:"roles"`
+}
+
+// HasRole reports whether the principal has one of the roles, admin has
+// all of them. Any principal passes an empty list.
+func (p Principal) HasRole(roles ...Role) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, have := range p.Roles {
+		if have == RoleAdmin {
+			return true
+		}
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal put by the middlewares.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Authenticator checks credentials. Set the exported fields before use.
+type Authenticator struct {
+	secret []byte
+	// API keys are looked up by hash, so the lookup time says nothing
+	// about the key.
+	apiKeys map[[sha256.Size]byte]Principal
+	now     func() time.Time
+
+	// Issuer and Audience are checked if set and put into issued tokens.
+	Issuer   string
+	Audience string
+	// Leeway is the allowed clock skew for exp and nbf.
+	Leeway time.Duration
+}
+
+// NewAuthenticator accepts JWTs signed with the secret, an empty secret
+// leaves only API keys.
+func NewAuthenticator(secret []byte) *Authenticator {
+	return &Authenticator{
+		secret:  secret,
+		apiKeys: make(map[[sha256.Size]byte]Principal),
+		now:     time.Now,
+		Leeway:  30 * time.Second,
+	}
+}
+
+func (a *Authenticator) AddAPIKey(key string, p Principal) error {
+	if key == "" {
+		return errors.New("auth: empty api key")
+	}
+	if err := checkPrincipal(p); err != nil {
+		return err
+	}
+	a.apiKeys[sha256.Sum256([]byte(key))] = p
+	return nil
+}
+
+// LoadAPIKeysFile adds API keys from a JSON file like
+// [{"key": "...", "subject": "stats-board", "roles": ["observer"]}].
+func (a *Authenticator) LoadAPIKeysFile(path string) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+	var keys []struct {
+		Key string `json:"key"`
+		Principal
+	}
+	if err := json.Unmarshal(buf, &keys); err != nil {
+		return fmt.Errorf("auth: cannot parse %s: %w", path, err)
+	}
+	for i, k := range keys {
+		if err := a.AddAPIKey(k.Key, k.Principal); err != nil {
+			return fmt.Errorf("auth: %s, key #%d: %w", path, i+1, err)
+		}
+	}
+	return nil
+}
+
+// Sign issues a token for the principal valid for ttl.
+func (a *Authenticator) Sign(p Principal, ttl time.Duration) (string, error) {
+	if err := checkPrincipal(p); err != nil {
+		return "", err
+	}
+	now := a.now()
+	claims := Claims{
+		Subject:   p.Subject,
+		Roles:     p.Roles,
+		Issuer:    a.Issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+	if a.Audience != "" {
+		claims.Audience = Audience{a.Audience}
+	}
+	return Sign(a.secret, claims)
+}
+
+// Authenticate checks the value of an authorization header.
+func (a *Authenticator) Authenticate(authorization string) (Principal, error) {
+	scheme, credential, _ := strings.Cut(strings.TrimSpace(authorization), " ")
+	credential = strings.TrimSpace(credential)
+	if credential == "" {
+		return Principal{}, ErrUnauthenticated
+	}
+
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		return a.verifyToken(credential)
+	case "apikey":
+		p, ok := a.apiKeys[sha256.Sum256([]byte(credential))]
+		if !ok {
+			return Principal{}, ErrUnknownAPIKey
+		}
+		return p, nil
+	default:
+		return Principal{}, fmt.Errorf("%w: unsupported scheme %q", ErrUnauthenticated, scheme)
+	}
+}
+
+func (a *Authenticator) verifyToken(token string) (Principal, error) {
+	if len(a.secret) == 0 {
+		return Principal{}, fmt.Errorf("%w: tokens are not accepted", ErrUnauthenticated)
+	}
+	claims, err := parseJWT(a.secret, token)
+	if err != nil {
+		return Principal{}, err
+	}
+	if err := claims.checkTime(a.now(), a.Leeway); err != nil {
+		return Principal{}, err
+	}
+	if a.Issuer != "" && claims.Issuer != a.Issuer {
+		return Principal{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if a.Audience != "" && !claims.Audience.contains(a.Audience) {
+		return Principal{}, fmt.Errorf("%w: token is for another audience", ErrInvalidToken)
+	}
+
+	p := Principal{Subject: claims.Subject, Roles: claims.Roles}
+	if err := checkPrincipal(p); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return p, nil
+}
+
+func checkPrincipal(p Principal) error {
+	if p.Subject == "" {
+		return errors.New("auth: subject is required")
+	}
+	for _, r := range p.Roles {
+		if !r.valid() {
+			return fmt.Errorf("auth: unknown role %q", r)
+		}
+	}
+	return nil
+}
+
+// Config selects the credentials accepted by New.
+type Config struct {
+	JWTSecret   string
+	APIKeysFile string
+}
+
+// New returns nil if cfg accepts no credentials, so auth is off.
+func New(cfg Config) (*Authenticator, error) {
+	if cfg.JWTSecret == "" && cfg.APIKeysFile == "" {
+		return nil, nil
+	}
+	a := NewAuthenticator([]byte(cfg.JWTSecret))
+	if cfg.APIKeysFile != "" {
+		if err := a.LoadAPIKeysFile(cfg.APIKeysFile); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newKey returns a random secret, the tests never use fixed keys.
+func newKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestAuthenticator_Token(t *testing.T) {
+	now := time.Date(2024, 9, 8, 12, 0, 0, 0, time.UTC)
+	secret := newKey(t)
+	a := NewAuthenticator(secret)
+	a.Issuer, a.Audience = "elections", "votes"
+	a.now = func() time.Time { return now }
+
+	token, err := a.Sign(Principal{Subject: "alice", Roles: []Role{RoleVoter}}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := a.Authenticate("Bearer " + token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Subject != "alice" || !p.HasRole(RoleVoter) || p.HasRole(RoleAdmin) {
+		t.Fatalf("unexpected principal %+v", p)
+	}
+
+	header, payload, _ := strings.Cut(token, ".")
+	payload, signature, _ := strings.Cut(payload, ".")
+	forged, _ := Sign(secret, Claims{Subject: "bob", ExpiresAt: now.Add(time.Hour).Unix()})
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + payload + "."
+	otherKey, _ := Sign(newKey(t), Claims{Subject: "alice", Issuer: "elections", Audience: Audience{"votes"}, ExpiresAt: now.Add(time.Hour).Unix()})
+
+	cases := []struct {
+		name          string
+		authorization string
+		now           time.Time
+		exp           error
+	}{
+		{"expired", "Bearer " + token, now.Add(time.Hour + time.Minute), ErrExpired},
+		{"within_leeway", "Bearer " + token, now.Add(time.Hour - time.Second), nil},
+		{"tampered", "Bearer " + header + "." + payload + "x." + signature, now, ErrInvalidToken},
+		{"alg_none", "Bearer " + none, now, ErrInvalidToken},
+		{"other_key", "Bearer " + otherKey, now, ErrInvalidToken},
+		{"wrong_issuer", "Bearer " + forged, now, ErrInvalidToken},
+		{"malformed", "Bearer abc", now, ErrInvalidToken},
+		{"no_credentials", "", now, ErrUnauthenticated},
+		{"basic", "Basic YTpi", now, ErrUnauthenticated},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a.now = func() time.Time { return c.now }
+			_, err := a.Authenticate(c.authorization)
+			if !errors.Is(err, c.exp) {
+				t.Fatalf("expected %v, got %v", c.exp, err)
+			}
+			if c.exp != nil && !errors.Is(err, ErrUnauthenticated) {
+				t.Fatalf("expected %v to be ErrUnauthenticated", err)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_APIKeys(t *testing.T) {
+	key := base64.RawURLEncoding.EncodeToString(newKey(t))
+	path := filepath.Join(t.TempDir(), "keys.json")
+	content := `[{"key": "` + key + `", "subject": "stats-board", "roles": ["observer"]}]`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(Config{APIKeysFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := a.Authenticate("ApiKey " + key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Subject != "stats-board" || !p.HasRole(RoleObserver) || p.HasRole(RoleVoter) {
+		t.Fatalf("unexpected principal %+v", p)
+	}
+	if _, err := a.Authenticate("ApiKey " + key + "x"); !errors.Is(err, ErrUnknownAPIKey) {
+		t.Fatalf("expected ErrUnknownAPIKey, got %v", err)
+	}
+	// the authenticator has no secret, so tokens are not accepted
+	token, _ := Sign(newKey(t), Claims{Subject: "a", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	if _, err := a.Authenticate("Bearer " + token); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+
+	if err := a.AddAPIKey("k", Principal{Subject: "x", Roles: []Role{"root"}}); err == nil {
+		t.Fatal("expected unknown role error")
+	}
+	if a, err := New(Config{}); a != nil || err != nil {
+		t.Fatalf("expected auth to be off, got %v, %v", a, err)
+	}
+}
+
+func TestPrincipal_HasRole(t *testing.T) {
+	admin := Principal{Subject: "root", Roles: []Role{RoleAdmin}}
+	observer := Principal{Subject: "board", Roles: []Role{RoleObserver}}
+	if !admin.HasRole(RoleVoter) || !admin.HasRole(RoleObserver) {
+		t.Fatal("admin has every role")
+	}
+	if !observer.HasRole(RoleVoter, RoleObserver) || observer.HasRole(RoleVoter) {
+		t.Fatal("observer has only its role")
+	}
+	if !(Principal{Subject: "nobody"}).HasRole() {
+		t.Fatal("empty list is open to any principal")
+	}
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Rules maps a full gRPC method name to the roles allowed to call it, one
+// of them is enough. A method listed without roles is public, a method
+// missing from Rules is open to any authenticated caller.
+type Rules map[string][]Role
+
+// Merge returns the union of the rules, later ones win.
+func Merge(rules ...Rules) Rules {
+	all := Rules{}
+	for _, r := range rules {
+		for method, roles := range r {
+			all[method] = roles
+		}
+	}
+	return all
+}
+
+// UnaryServerInterceptor reads the authorization metadata, puts the
+// principal into the context and checks the roles of the method.
+func UnaryServerInterceptor(a *Authenticator, rules Rules) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, a, rules, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(a *Authenticator, rules Rules) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), a, rules, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
+
+func authorize(ctx context.Context, a *Authenticator, rules Rules, method string) (context.Context, error) {
+	roles, listed := rules[method]
+	public := listed && len(roles) == 0
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		if public {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	p, err := a.Authenticate(values[0])
+	if err != nil {
+		return nil, Status(err)
+	}
+	if !p.HasRole(roles...) {
+		return nil, Status(ErrForbidden)
+	}
+	return NewContext(ctx, p), nil
+}
+
+// Status maps auth errors to gRPC statuses.
+func Status(err error) error {
+	switch {
+	case errors.Is(err, ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// Credentials sends the authorization metadata with every call, like
+// Credentials("Bearer " + token) or Credentials("ApiKey " + key).
+type Credentials string
+
+// Token returns credentials of a JWT.
+func Token(token string) Credentials {
+	return Credentials("Bearer " + token)
+}
+
+// APIKey returns credentials of an API key.
+func APIKey(key string) Credentials {
+	return Credentials("ApiKey " + key)
+}
+
+func (c Credentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": string(c)}, nil
+}
+
+// RequireTransportSecurity is false as the examples run without TLS, use
+// TLS anywhere else as the credentials are sent in clear text.
+func (c Credentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	a := NewAuthenticator(newKey(t))
+	token := func(roles ...Role) string {
+		tok, err := a.Sign(Principal{Subject: "alice", Roles: roles}, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + tok
+	}
+
+	interceptor := UnaryServerInterceptor(a, Rules{
+		"/elections.Elections/SubmitVote": {RoleVoter},
+		"/elections.Elections/Internal":   {RoleAdmin},
+		"/candidates.Candidates/List":     nil,
+	})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		p, _ := FromContext(ctx)
+		return p.Subject, nil
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		authorization string
+		exp           codes.Code
+	}{
+		{"voter_votes", "/elections.Elections/SubmitVote", token(RoleVoter), codes.OK},
+		{"observer_votes", "/elections.Elections/SubmitVote", token(RoleObserver), codes.PermissionDenied},
+		{"anonymous_votes", "/elections.Elections/SubmitVote", "", codes.Unauthenticated},
+		{"bad_token", "/elections.Elections/SubmitVote", "Bearer x.y.z", codes.Unauthenticated},
+		{"voter_internal", "/elections.Elections/Internal", token(RoleVoter), codes.PermissionDenied},
+		{"admin_internal", "/elections.Elections/Internal", token(RoleAdmin), codes.OK},
+		{"public", "/candidates.Candidates/List", "", codes.OK},
+		{"public_with_bad_token", "/candidates.Candidates/List", "Bearer x", codes.Unauthenticated},
+		{"unlisted_anonymous", "/candidates.Candidates/Create", "", codes.Unauthenticated},
+		{"unlisted_any_role", "/candidates.Candidates/Create", token(), codes.OK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", c.authorization))
+			}
+			resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: c.method}, handler)
+			if code := status.Code(err); code != c.exp {
+				t.Fatalf("expected %v, got %v", c.exp, err)
+			}
+			if err == nil && c.authorization != "" && resp != "alice" {
+				t.Fatalf("expected principal in context, got %v", resp)
+			}
+		})
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	md, err := Token("abc").GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md["authorization"] != "Bearer abc" {
+		t.Fatalf("unexpected metadata %v", md)
+	}
+	if md, _ := APIKey("k").GetRequestMetadata(context.Background()); md["authorization"] != "ApiKey k" {
+		t.Fatalf("unexpected metadata %v", md)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+)
+
+// go run ./auth/token -secret dev-secret -sub alice -roles admin
+// go run ./auth/token -secret dev-secret -sub board -roles observer -ttl 24h
+
+var (
+	secret   = flag.String("secret", os.Getenv("JWT_SECRET"), "HMAC secret, JWT_SECRET by default")
+	subject  = flag.String("sub", "", "subject of the token")
+	roles    = flag.String("roles", "voter", "comma separated roles: voter, observer, admin")
+	ttl      = flag.Duration("ttl", time.Hour, "token lifetime")
+	issuer   = flag.String("iss", "", "issuer")
+	audience = flag.String("aud", "", "audience")
+)
+
+func main() {
+	flag.Parse()
+
+	a := auth.NewAuthenticator([]byte(*secret))
+	a.Issuer = *issuer
+	a.Audience = *audience
+
+	p := auth.Principal{Subject: *subject}
+	for _, r := range strings.Split(*roles, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			p.Roles = append(p.Roles, auth.Role(r))
+		}
+	}
+
+	token, err := a.Sign(p, *ttl)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(token)
+}
@@ -0,0 +1,156 @@
+// Package candidates keeps the list of candidates votes can be cast for.
+package candidates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotFound = errors.New("candidate not found")
+	ErrInactive = errors.New("candidate is not active")
+	ErrExists   = errors.New("candidate already exists")
+	ErrInvalid  = errors.New("candidate name is required")
+)
+
+type Candidate struct {
+	Id     uint32 `json:"id" xml:"id"`
+	Name   string `json:"name" xml:"name"`
+	Party  string `json:"party,omitempty" xml:"party,omitempty"`
+	Active bool   `json:"active" xml:"active"`
+}
+
+type Registry struct {
+	lock       sync.RWMutex
+	candidates map[uint32]Candidate
+	lastId     uint32
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		candidates: make(map[uint32]Candidate),
+	}
+}
+
+// Create adds a candidate, a zero id is replaced with the next free one.
+func (r *Registry) Create(c Candidate) (Candidate, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return Candidate{}, ErrInvalid
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if c.Id == 0 {
+		c.Id = r.lastId + 1
+	}
+	if _, ok := r.candidates[c.Id]; ok {
+		return Candidate{}, fmt.Errorf("%w: %d", ErrExists, c.Id)
+	}
+	r.candidates[c.Id] = c
+	if c.Id > r.lastId {
+		r.lastId = c.Id
+	}
+	return c, nil
+}
+
+func (r *Registry) Get(id uint32) (Candidate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	c, ok := r.candidates[id]
+	if !ok {
+		return Candidate{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return c, nil
+}
+
+// List returns candidates ordered by id.
+func (r *Registry) List() []Candidate {
+	r.lock.RLock()
+	list := make([]Candidate, 0, len(r.candidates))
+	for _, c := range r.candidates {
+		list = append(list, c)
+	}
+	r.lock.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	return list
+}
+
+func (r *Registry) Update(c Candidate) (Candidate, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return Candidate{}, ErrInvalid
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.candidates[c.Id]; !ok {
+		return Candidate{}, fmt.Errorf("%w: %d", ErrNotFound, c.Id)
+	}
+	r.candidates[c.Id] = c
+	return c, nil
+}
+
+func (r *Registry) Delete(id uint32) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.candidates[id]; !ok {
+		return fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	delete(r.candidates, id)
+	return nil
+}
+
+// CheckVote reports whether a vote for the candidate can be accepted.
+func (r *Registry) CheckVote(id uint32) error {
+	c, err := r.Get(id)
+	if err != nil {
+		return err
+	}
+	if !c.Active {
+		return fmt.Errorf("%w: %d", ErrInactive, id)
+	}
+	return nil
+}
+
+// Names returns candidate names by id.
+func (r *Registry) Names() map[uint32]string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	names := make(map[uint32]string, len(r.candidates))
+	for id, c := range r.candidates {
+		names[id] = c.Name
+	}
+	return names
+}
+
+// LoadFile creates candidates from a JSON array like
+// [{"id": 1, "name": "Alice", "party": "Green", "active": true}].
+func (r *Registry) LoadFile(path string) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read candidates file: %w", err)
+	}
+
+	var list []Candidate
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return fmt.Errorf("cannot parse candidates file: %w", err)
+	}
+	for _, c := range list {
+		if _, err := r.Create(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package candidates
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	alice, err := r.Create(Candidate{Name: "Alice", Party: "Green", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alice.Id != 1 {
+		t.Fatalf("expected id 1, got %d", alice.Id)
+	}
+	if _, err := r.Create(Candidate{Id: 5, Name: "Bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Create(Candidate{Id: 5, Name: "Eve"}); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if _, err := r.Create(Candidate{Name: " "}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+	if c, _ := r.Create(Candidate{Name: "Carol", Active: true}); c.Id != 6 {
+		t.Fatalf("expected id 6, got %d", c.Id)
+	}
+
+	cases := []struct {
+		id  uint32
+		exp error
+	}{
+		{1, nil},
+		{5, ErrInactive},
+		{7, ErrNotFound},
+	}
+	for _, c := range cases {
+		if err := r.CheckVote(c.id); !errors.Is(err, c.exp) {
+			t.Errorf("CheckVote(%d): expected %v, got %v", c.id, c.exp, err)
+		}
+	}
+
+	if _, err := r.Update(Candidate{Id: 5, Name: "Bob", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CheckVote(5); err != nil {
+		t.Fatalf("activated candidate: %v", err)
+	}
+	if err := r.Delete(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(5); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	list := r.List()
+	if len(list) != 2 || list[0].Id != 1 || list[1].Id != 6 {
+		t.Fatalf("unexpected list %+v", list)
+	}
+}
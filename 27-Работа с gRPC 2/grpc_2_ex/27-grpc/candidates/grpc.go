@@ -0,0 +1,106 @@
+package candidates
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// AuthRules lets anyone read the candidates, changes are for admins.
+var AuthRules = auth.Rules{
+	pb.Candidates_GetCandidate_FullMethodName:    nil,
+	pb.Candidates_ListCandidates_FullMethodName:  nil,
+	pb.Candidates_CreateCandidate_FullMethodName: {auth.RoleAdmin},
+	pb.Candidates_UpdateCandidate_FullMethodName: {auth.RoleAdmin},
+	pb.Candidates_DeleteCandidate_FullMethodName: {auth.RoleAdmin},
+}
+
+// GRPCService exposes the registry as the candidates.Candidates gRPC service.
+type GRPCService struct {
+	pb.UnimplementedCandidatesServer
+
+	registry *Registry
+}
+
+func NewGRPCService(registry *Registry) *GRPCService {
+	return &GRPCService{registry: registry}
+}
+
+func (s *GRPCService) CreateCandidate(ctx context.Context, req *pb.Candidate) (*pb.Candidate, error) {
+	c, err := s.registry.Create(fromPb(req))
+	if err != nil {
+		return nil, Status(err)
+	}
+	return toPb(c), nil
+}
+
+func (s *GRPCService) GetCandidate(ctx context.Context, req *pb.CandidateId) (*pb.Candidate, error) {
+	c, err := s.registry.Get(req.GetId())
+	if err != nil {
+		return nil, Status(err)
+	}
+	return toPb(c), nil
+}
+
+func (s *GRPCService) ListCandidates(ctx context.Context, _ *emptypb.Empty) (*pb.CandidateList, error) {
+	list := s.registry.List()
+	resp := &pb.CandidateList{Candidates: make([]*pb.Candidate, 0, len(list))}
+	for _, c := range list {
+		resp.Candidates = append(resp.Candidates, toPb(c))
+	}
+	return resp, nil
+}
+
+func (s *GRPCService) UpdateCandidate(ctx context.Context, req *pb.Candidate) (*pb.Candidate, error) {
+	c, err := s.registry.Update(fromPb(req))
+	if err != nil {
+		return nil, Status(err)
+	}
+	return toPb(c), nil
+}
+
+func (s *GRPCService) DeleteCandidate(ctx context.Context, req *pb.CandidateId) (*emptypb.Empty, error) {
+	if err := s.registry.Delete(req.GetId()); err != nil {
+		return nil, Status(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// Status converts registry errors to gRPC status errors.
+func Status(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrInactive):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func toPb(c Candidate) *pb.Candidate {
+	return &pb.Candidate{
+		Id:     c.Id,
+		Name:   c.Name,
+		Party:  c.Party,
+		Active: c.Active,
+	}
+}
+
+func fromPb(c *pb.Candidate) Candidate {
+	return Candidate{
+		Id:     c.GetId(),
+		Name:   c.GetName(),
+		Party:  c.GetParty(),
+		Active: c.GetActive(),
+	}
+}
@@ -0,0 +1,274 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.3
+// source: api/candidates/candidates.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Candidates_CreateCandidate_FullMethodName = "/candidates.Candidates/CreateCandidate"
+	Candidates_GetCandidate_FullMethodName    = "/candidates.Candidates/GetCandidate"
+	Candidates_ListCandidates_FullMethodName  = "/candidates.Candidates/ListCandidates"
+	Candidates_UpdateCandidate_FullMethodName = "/candidates.Candidates/UpdateCandidate"
+	Candidates_DeleteCandidate_FullMethodName = "/candidates.Candidates/DeleteCandidate"
+)
+
+// CandidatesClient is the client API for Candidates service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CandidatesClient interface {
+	CreateCandidate(ctx context.Context, in *Candidate, opts ...grpc.CallOption) (*Candidate, error)
+	GetCandidate(ctx context.Context, in *CandidateId, opts ...grpc.CallOption) (*Candidate, error)
+	ListCandidates(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CandidateList, error)
+	UpdateCandidate(ctx context.Context, in *Candidate, opts ...grpc.CallOption) (*Candidate, error)
+	DeleteCandidate(ctx context.Context, in *CandidateId, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type candidatesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCandidatesClient(cc grpc.ClientConnInterface) CandidatesClient {
+	return &candidatesClient{cc}
+}
+
+func (c *candidatesClient) CreateCandidate(ctx context.Context, in *Candidate, opts ...grpc.CallOption) (*Candidate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Candidate)
+	err := c.cc.Invoke(ctx, Candidates_CreateCandidate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *candidatesClient) GetCandidate(ctx context.Context, in *CandidateId, opts ...grpc.CallOption) (*Candidate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Candidate)
+	err := c.cc.Invoke(ctx, Candidates_GetCandidate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *candidatesClient) ListCandidates(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CandidateList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CandidateList)
+	err := c.cc.Invoke(ctx, Candidates_ListCandidates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *candidatesClient) UpdateCandidate(ctx context.Context, in *Candidate, opts ...grpc.CallOption) (*Candidate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Candidate)
+	err := c.cc.Invoke(ctx, Candidates_UpdateCandidate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *candidatesClient) DeleteCandidate(ctx context.Context, in *CandidateId, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Candidates_DeleteCandidate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CandidatesServer is the server API for Candidates service.
+// All implementations must embed UnimplementedCandidatesServer
+// for forward compatibility.
+type CandidatesServer interface {
+	CreateCandidate(context.Context, *Candidate) (*Candidate, error)
+	GetCandidate(context.Context, *CandidateId) (*Candidate, error)
+	ListCandidates(context.Context, *emptypb.Empty) (*CandidateList, error)
+	UpdateCandidate(context.Context, *Candidate) (*Candidate, error)
+	DeleteCandidate(context.Context, *CandidateId) (*emptypb.Empty, error)
+	mustEmbedUnimplementedCandidatesServer()
+}
+
+// UnimplementedCandidatesServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCandidatesServer struct{}
+
+func (UnimplementedCandidatesServer) CreateCandidate(context.Context, *Candidate) (*Candidate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCandidate not implemented")
+}
+func (UnimplementedCandidatesServer) GetCandidate(context.Context, *CandidateId) (*Candidate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCandidate not implemented")
+}
+func (UnimplementedCandidatesServer) ListCandidates(context.Context, *emptypb.Empty) (*CandidateList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCandidates not implemented")
+}
+func (UnimplementedCandidatesServer) UpdateCandidate(context.Context, *Candidate) (*Candidate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCandidate not implemented")
+}
+func (UnimplementedCandidatesServer) DeleteCandidate(context.Context, *CandidateId) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCandidate not implemented")
+}
+func (UnimplementedCandidatesServer) mustEmbedUnimplementedCandidatesServer() {}
+func (UnimplementedCandidatesServer) testEmbeddedByValue()                    {}
+
+// UnsafeCandidatesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CandidatesServer will
+// result in compilation errors.
+type UnsafeCandidatesServer interface {
+	mustEmbedUnimplementedCandidatesServer()
+}
+
+func RegisterCandidatesServer(s grpc.ServiceRegistrar, srv CandidatesServer) {
+	// If the following call pancis, it indicates UnimplementedCandidatesServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Candidates_ServiceDesc, srv)
+}
+
+func _Candidates_CreateCandidate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Candidate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CandidatesServer).CreateCandidate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Candidates_CreateCandidate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CandidatesServer).CreateCandidate(ctx, req.(*Candidate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Candidates_GetCandidate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CandidateId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CandidatesServer).GetCandidate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Candidates_GetCandidate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CandidatesServer).GetCandidate(ctx, req.(*CandidateId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Candidates_ListCandidates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CandidatesServer).ListCandidates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Candidates_ListCandidates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CandidatesServer).ListCandidates(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Candidates_UpdateCandidate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Candidate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CandidatesServer).UpdateCandidate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Candidates_UpdateCandidate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CandidatesServer).UpdateCandidate(ctx, req.(*Candidate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Candidates_DeleteCandidate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CandidateId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CandidatesServer).DeleteCandidate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Candidates_DeleteCandidate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CandidatesServer).DeleteCandidate(ctx, req.(*CandidateId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Candidates_ServiceDesc is the grpc.ServiceDesc for Candidates service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Candidates_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "candidates.Candidates",
+	HandlerType: (*CandidatesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateCandidate",
+			Handler:    _Candidates_CreateCandidate_Handler,
+		},
+		{
+			MethodName: "GetCandidate",
+			Handler:    _Candidates_GetCandidate_Handler,
+		},
+		{
+			MethodName: "ListCandidates",
+			Handler:    _Candidates_ListCandidates_Handler,
+		},
+		{
+			MethodName: "UpdateCandidate",
+			Handler:    _Candidates_UpdateCandidate_Handler,
+		},
+		{
+			MethodName: "DeleteCandidate",
+			Handler:    _Candidates_DeleteCandidate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/candidates/candidates.proto",
+}
@@ -0,0 +1,76 @@
+// Package correlation carries the request id of a call through HTTP
+// headers, gRPC metadata, contexts and log records, so one request can be
+// followed across the services.
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const (
+	// Header is the HTTP header with the id, in requests and responses.
+	Header = "X-Request-ID"
+	// MetadataKey is the gRPC metadata key with the id, in both directions.
+	MetadataKey = "x-request-id"
+	// LogKey is the attribute name in log records.
+	LogKey = "request_id"
+)
+
+// maxLen limits ids coming from callers, they end up in every log record.
+const maxLen = 128
+
+type idKey struct{}
+
+// NewContext returns a context carrying the id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext returns the id of the context, empty if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
+
+// New generates a random id.
+func New() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// Valid reports whether an id from a caller can be used as is: up to 128
+// letters, digits and "-_.:" characters.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Ensure returns the id if it is valid and a new one otherwise.
+func Ensure(id string) string {
+	if Valid(id) {
+		return id
+	}
+	return New()
+}
+
+// Outgoing returns the id to send with an outgoing call made with ctx: the
+// id of ctx or a new one if ctx has none.
+func Outgoing(ctx context.Context) string {
+	if id := FromContext(ctx); id != "" {
+		return id
+	}
+	return New()
+}
@@ -0,0 +1,121 @@
+package correlation
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestEnsure(t *testing.T) {
+	cases := []struct {
+		id   string
+		keep bool
+	}{
+		{"20240101120000:1", true},
+		{"a-b_c.d", true},
+		{"", false},
+		{"bad id", false},
+		{"line\nbreak", false},
+		{strings.Repeat("x", maxLen), true},
+		{strings.Repeat("x", maxLen+1), false},
+	}
+	for _, c := range cases {
+		got := Ensure(c.id)
+		if c.keep && got != c.id {
+			t.Fatalf("expected %q to be kept, got %q", c.id, got)
+		}
+		if !c.keep && (got == c.id || !Valid(got)) {
+			t.Fatalf("expected a new id instead of %q, got %q", c.id, got)
+		}
+	}
+	if New() == New() {
+		t.Fatal("expected different ids")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil))).With("svc", "elections")
+
+	logger.InfoContext(NewContext(context.Background(), "req-1"), "vote accepted")
+	logger.InfoContext(context.Background(), "no request")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "request_id=req-1") || !strings.Contains(lines[0], "svc=elections") {
+		t.Fatalf("expected request id and attrs, got %q", lines[0])
+	}
+	if strings.Contains(lines[1], LogKey) {
+		t.Fatalf("expected no request id, got %q", lines[1])
+	}
+}
+
+type headerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *headerStream) Context() context.Context { return s.ctx }
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestServerInterceptors(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "req-1"))
+	var got string
+	_, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = FromContext(ctx)
+		return nil, nil
+	})
+	if err != nil || got != "req-1" {
+		t.Fatalf("expected id from metadata, got %q, %v", got, err)
+	}
+
+	ss := &headerStream{ctx: context.Background()}
+	err = StreamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		got = FromContext(stream.Context())
+		return nil
+	})
+	if err != nil || !Valid(got) {
+		t.Fatalf("expected generated id, got %q, %v", got, err)
+	}
+	if sent := ss.header.Get(MetadataKey); len(sent) != 1 || sent[0] != got {
+		t.Fatalf("expected id %q in header, got %v", got, sent)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	var sent []string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		sent = md.Get(MetadataKey)
+		return nil
+	}
+	interceptor := UnaryClientInterceptor()
+
+	interceptor(NewContext(context.Background(), "req-1"), "/m", nil, nil, nil, invoker)
+	if len(sent) != 1 || sent[0] != "req-1" {
+		t.Fatalf("expected id of the context, got %v", sent)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(NewContext(context.Background(), "req-1"), MetadataKey, "explicit")
+	interceptor(ctx, "/m", nil, nil, nil, invoker)
+	if len(sent) != 1 || sent[0] != "explicit" {
+		t.Fatalf("expected explicit id to be kept, got %v", sent)
+	}
+
+	interceptor(context.Background(), "/m", nil, nil, nil, invoker)
+	if len(sent) != 1 || !Valid(sent[0]) {
+		t.Fatalf("expected generated id, got %v", sent)
+	}
+}
@@ -0,0 +1,37 @@
+package correlation
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler adds the request id of the context to every record, use the
+// slog *Context functions so the handler sees the context:
+//
+//	slog.SetDefault(slog.New(correlation.NewHandler(slog.NewTextHandler(os.Stdout, nil))))
+//	slog.InfoContext(ctx, "vote accepted")
+type Handler struct {
+	slog.Handler
+}
+
+func NewHandler(h slog.Handler) *Handler {
+	return &Handler{Handler: h}
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		r = r.Clone()
+		r.AddAttrs(slog.String(LogKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps adding the id, it goes into the group like other
+// attributes of the record.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
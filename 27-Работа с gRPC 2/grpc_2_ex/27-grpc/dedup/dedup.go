@@ -0,0 +1,171 @@
+// Package dedup enforces the one-vote-per-passport rule for every entry
+// point (HTTP and gRPC) that accepts votes.
+package dedup
+
+import (
+	"errors"
+	"hash/fnv"
+	"strings"
+	"sync"
+)
+
+const shardCount = 64
+
+var (
+	ErrAlreadyVoted = errors.New("passport has already voted")
+	// ErrAborted is reported by SubmitAll for the votes of a batch that
+	// were fine but not applied because another vote of it was rejected.
+	ErrAborted = errors.New("batch aborted, another vote of it was rejected")
+)
+
+type Policy struct {
+	// AllowRevote lets a voter change the choice, the latest vote wins.
+	AllowRevote bool
+}
+
+type shard struct {
+	sync.Mutex
+	votes map[string]uint32
+}
+
+// Guard remembers the candidate every passport voted for. Votes for
+// different passports are processed in parallel, votes for the same
+// passport are serialized.
+type Guard struct {
+	policy Policy
+	shards [shardCount]shard
+}
+
+func NewGuard(policy Policy) *Guard {
+	g := &Guard{policy: policy}
+	for i := range g.shards {
+		g.shards[i].votes = make(map[string]uint32)
+	}
+	return g
+}
+
+func (g *Guard) Policy() Policy {
+	return g.policy
+}
+
+// Submit checks the passport and calls apply to store the vote. replaces is
+// the candidate whose vote is withdrawn by a revote, 0 for the first vote.
+// The passport is remembered only if apply succeeds; apply runs under the
+// passport lock, so it must not call back into the guard.
+func (g *Guard) Submit(passport string, candidateId uint32, apply func(replaces uint32) error) error {
+	passport = Normalize(passport)
+	sh := g.shard(passport)
+
+	sh.Lock()
+	defer sh.Unlock()
+
+	replaces, voted := sh.votes[passport]
+	if voted && !g.policy.AllowRevote {
+		return ErrAlreadyVoted
+	}
+	if err := apply(replaces); err != nil {
+		return err
+	}
+	sh.votes[passport] = candidateId
+	return nil
+}
+
+// Ballot is one vote of a batch passed to SubmitAll.
+type Ballot struct {
+	Passport    string
+	CandidateId uint32
+}
+
+// SubmitAll is Submit of a whole batch: either every ballot is applied or
+// none. The passports are locked together and checked, a passport repeated
+// in the batch counts as a revote. apply is called once with the candidate
+// replaced by every ballot. If a ballot is rejected apply is not called,
+// errs has the reason for it and ErrAborted for the others. If apply fails
+// its error is set for every ballot. errs is nil if the batch was applied.
+func (g *Guard) SubmitAll(ballots []Ballot, apply func(replaces []uint32) error) (errs []error) {
+	passports := make([]string, len(ballots))
+	locked := make(map[*shard]bool)
+	for i, b := range ballots {
+		passports[i] = Normalize(b.Passport)
+		locked[g.shard(passports[i])] = true
+	}
+	// shards are always locked in the same order, so batches don't deadlock
+	for i := range g.shards {
+		if sh := &g.shards[i]; locked[sh] {
+			sh.Lock()
+			defer sh.Unlock()
+		}
+	}
+
+	replaces := make([]uint32, len(ballots))
+	pending := make(map[string]uint32)
+	rejected := false
+	errs = make([]error, len(ballots))
+	for i, passport := range passports {
+		last, voted := pending[passport]
+		if !voted {
+			last, voted = g.shard(passport).votes[passport]
+		}
+		if voted && !g.policy.AllowRevote {
+			errs[i] = ErrAlreadyVoted
+			rejected = true
+			continue
+		}
+		replaces[i] = last
+		pending[passport] = ballots[i].CandidateId
+	}
+	if rejected {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = ErrAborted
+			}
+		}
+		return errs
+	}
+
+	if err := apply(replaces); err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	for passport, candidateId := range pending {
+		g.shard(passport).votes[passport] = candidateId
+	}
+	return nil
+}
+
+// Voted returns the candidate the passport voted for.
+func (g *Guard) Voted(passport string) (uint32, bool) {
+	passport = Normalize(passport)
+	sh := g.shard(passport)
+
+	sh.Lock()
+	defer sh.Unlock()
+
+	candidateId, ok := sh.votes[passport]
+	return candidateId, ok
+}
+
+// Restore marks the passport as voted without any checks, it is used to
+// rebuild the guard from persisted votes on startup.
+func (g *Guard) Restore(passport string, candidateId uint32) {
+	passport = Normalize(passport)
+	sh := g.shard(passport)
+
+	sh.Lock()
+	sh.votes[passport] = candidateId
+	sh.Unlock()
+}
+
+func (g *Guard) shard(passport string) *shard {
+	h := fnv.New32a()
+	h.Write([]byte(passport))
+	return &g.shards[h.Sum32()%shardCount]
+}
+
+// Normalize makes " ab123456 " and "AB123456" the same passport. Inner
+// whitespace is kept, "ab 123456" is another passport.
+func Normalize(passport string) string {
+	return strings.ToUpper(strings.TrimSpace(passport))
+}
@@ -0,0 +1,162 @@
+package dedup
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGuard_Submit(t *testing.T) {
+	g := NewGuard(Policy{})
+
+	if err := g.Submit(" ab123 ", 1, noop); err != nil {
+		t.Fatalf("first vote: %v", err)
+	}
+	if err := g.Submit("AB123", 2, noop); !errors.Is(err, ErrAlreadyVoted) {
+		t.Fatalf("repeat vote: expected ErrAlreadyVoted, got %v", err)
+	}
+	if id, _ := g.Voted("ab123"); id != 1 {
+		t.Fatalf("expected vote for 1, got %d", id)
+	}
+	if err := g.Submit("ab 123", 2, noop); err != nil {
+		t.Fatalf("inner whitespace is another passport: %v", err)
+	}
+}
+
+func TestGuard_Revote(t *testing.T) {
+	g := NewGuard(Policy{AllowRevote: true})
+
+	var replaced []uint32
+	apply := func(replaces uint32) error {
+		replaced = append(replaced, replaces)
+		return nil
+	}
+	for _, id := range []uint32{1, 2, 3} {
+		if err := g.Submit("p", id, apply); err != nil {
+			t.Fatalf("vote for %d: %v", id, err)
+		}
+	}
+
+	if want := []uint32{0, 1, 2}; !equal(replaced, want) {
+		t.Fatalf("expected replaces %v, got %v", want, replaced)
+	}
+}
+
+func TestGuard_ApplyFailed(t *testing.T) {
+	g := NewGuard(Policy{})
+	errStore := errors.New("store failed")
+
+	if err := g.Submit("p", 1, func(uint32) error { return errStore }); !errors.Is(err, errStore) {
+		t.Fatalf("expected store error, got %v", err)
+	}
+	if _, ok := g.Voted("p"); ok {
+		t.Fatal("failed vote must not be remembered")
+	}
+	if err := g.Submit("p", 1, noop); err != nil {
+		t.Fatalf("vote after failure: %v", err)
+	}
+}
+
+func TestGuard_SubmitAll(t *testing.T) {
+	g := NewGuard(Policy{})
+	if err := g.Submit("p1", 1, noop); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := false
+	errs := g.SubmitAll([]Ballot{{"p2", 1}, {"P1", 2}, {"p3", 1}, {" P3 ", 2}}, func([]uint32) error {
+		applied = true
+		return nil
+	})
+	if applied {
+		t.Fatal("rejected batch applied")
+	}
+	want := []error{ErrAborted, ErrAlreadyVoted, ErrAborted, ErrAlreadyVoted}
+	for i := range want {
+		if !errors.Is(errs[i], want[i]) {
+			t.Fatalf("ballot %d: expected %v, got %v", i, want[i], errs[i])
+		}
+	}
+	if _, ok := g.Voted("p2"); ok {
+		t.Fatal("passport of a rejected batch remembered")
+	}
+
+	errApply := errors.New("store failed")
+	errs = g.SubmitAll([]Ballot{{"p2", 1}}, func([]uint32) error { return errApply })
+	if len(errs) != 1 || !errors.Is(errs[0], errApply) {
+		t.Fatalf("expected apply error, got %v", errs)
+	}
+
+	if errs := g.SubmitAll([]Ballot{{"p2", 1}, {"p3", 2}}, noopAll); errs != nil {
+		t.Fatalf("batch: %v", errs)
+	}
+	if id, _ := g.Voted("p3"); id != 2 {
+		t.Fatalf("expected vote for 2, got %d", id)
+	}
+}
+
+func TestGuard_SubmitAllRevote(t *testing.T) {
+	g := NewGuard(Policy{AllowRevote: true})
+	if err := g.Submit("p", 1, noop); err != nil {
+		t.Fatal(err)
+	}
+
+	var replaced []uint32
+	errs := g.SubmitAll([]Ballot{{"p", 2}, {"q", 1}, {"p", 3}}, func(replaces []uint32) error {
+		replaced = replaces
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("batch: %v", errs)
+	}
+	if want := []uint32{1, 0, 2}; !equal(replaced, want) {
+		t.Fatalf("expected replaces %v, got %v", want, replaced)
+	}
+	if id, _ := g.Voted("p"); id != 3 {
+		t.Fatalf("expected vote for 3, got %d", id)
+	}
+}
+
+func TestGuard_Concurrent(t *testing.T) {
+	g := NewGuard(Policy{})
+
+	var accepted, rejected int64
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(id uint32) {
+			defer wg.Done()
+			err := g.Submit("same passport", id, noop)
+			switch {
+			case err == nil:
+				atomic.AddInt64(&accepted, 1)
+			case errors.Is(err, ErrAlreadyVoted):
+				atomic.AddInt64(&rejected, 1)
+			default:
+				t.Error(err)
+			}
+		}(uint32(i%5 + 1))
+	}
+	wg.Wait()
+
+	if accepted != 1 || rejected != 99 {
+		t.Fatalf("expected 1 accepted and 99 rejected, got %d and %d", accepted, rejected)
+	}
+}
+
+func noop(uint32) error { return nil }
+
+func noopAll([]uint32) error { return nil }
+
+func equal(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
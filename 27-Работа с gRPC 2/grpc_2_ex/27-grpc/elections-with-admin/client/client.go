@@ -3,16 +3,33 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
 	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-admin/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"io"
 	"log"
+	"os"
 	"time"
 )
 
+// token has to carry the admin role if the server checks auth
+var token = flag.String("token", os.Getenv("ELECTIONS_TOKEN"), "JWT sent in the authorization metadata, ELECTIONS_TOKEN by default")
+
 func main() {
-	conn, err := grpc.NewClient(":50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	flag.Parse()
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(correlation.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(correlation.StreamClientInterceptor()),
+	}
+	if *token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.Token(*token)))
+	}
+	conn, err := grpc.NewClient(":50051", opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -27,10 +44,11 @@ func main() {
 	done := make(chan struct{})
 
 	go func() {
-		for candidateID := uint32(0); candidateID < 5; candidateID++ {
+		for candidateID := uint32(1); candidateID <= 5; candidateID++ {
 			vote := &pb.Vote{
 				Passport:    "100",
 				CandidateId: candidateID,
+				ElectionId:  1,
 			}
 
 			if errSend := stream.Send(vote); errSend != nil {
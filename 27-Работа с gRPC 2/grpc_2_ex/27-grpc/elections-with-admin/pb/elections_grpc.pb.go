@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-grpc v1.3.0
+// - protoc-gen-go-grpc v1.5.1
 // - protoc             v4.25.3
 // source: api/elections-with-admin/elections.proto
 
@@ -16,8 +16,8 @@ import (
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.32.0 or later.
-const _ = grpc.SupportPackageIsVersion7
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
 
 const (
 	Elections_SubmitVote_FullMethodName = "/elections_with_admin.Elections/SubmitVote"
@@ -29,7 +29,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ElectionsClient interface {
 	SubmitVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	Internal(ctx context.Context, opts ...grpc.CallOption) (Elections_InternalClient, error)
+	Internal(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Vote, StatsVote], error)
 }
 
 type electionsClient struct {
@@ -41,65 +41,52 @@ func NewElectionsClient(cc grpc.ClientConnInterface) ElectionsClient {
 }
 
 func (c *electionsClient) SubmitVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
-	err := c.cc.Invoke(ctx, Elections_SubmitVote_FullMethodName, in, out, opts...)
+	err := c.cc.Invoke(ctx, Elections_SubmitVote_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *electionsClient) Internal(ctx context.Context, opts ...grpc.CallOption) (Elections_InternalClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Elections_ServiceDesc.Streams[0], Elections_Internal_FullMethodName, opts...)
+func (c *electionsClient) Internal(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Vote, StatsVote], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Elections_ServiceDesc.Streams[0], Elections_Internal_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &electionsInternalClient{stream}
+	x := &grpc.GenericClientStream[Vote, StatsVote]{ClientStream: stream}
 	return x, nil
 }
 
-type Elections_InternalClient interface {
-	Send(*Vote) error
-	Recv() (*StatsVote, error)
-	grpc.ClientStream
-}
-
-type electionsInternalClient struct {
-	grpc.ClientStream
-}
-
-func (x *electionsInternalClient) Send(m *Vote) error {
-	return x.ClientStream.SendMsg(m)
-}
-
-func (x *electionsInternalClient) Recv() (*StatsVote, error) {
-	m := new(StatsVote)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
-}
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_InternalClient = grpc.BidiStreamingClient[Vote, StatsVote]
 
 // ElectionsServer is the server API for Elections service.
 // All implementations must embed UnimplementedElectionsServer
-// for forward compatibility
+// for forward compatibility.
 type ElectionsServer interface {
 	SubmitVote(context.Context, *Vote) (*emptypb.Empty, error)
-	Internal(Elections_InternalServer) error
+	Internal(grpc.BidiStreamingServer[Vote, StatsVote]) error
 	mustEmbedUnimplementedElectionsServer()
 }
 
-// UnimplementedElectionsServer must be embedded to have forward compatible implementations.
-type UnimplementedElectionsServer struct {
-}
+// UnimplementedElectionsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedElectionsServer struct{}
 
 func (UnimplementedElectionsServer) SubmitVote(context.Context, *Vote) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SubmitVote not implemented")
 }
-func (UnimplementedElectionsServer) Internal(Elections_InternalServer) error {
+func (UnimplementedElectionsServer) Internal(grpc.BidiStreamingServer[Vote, StatsVote]) error {
 	return status.Errorf(codes.Unimplemented, "method Internal not implemented")
 }
 func (UnimplementedElectionsServer) mustEmbedUnimplementedElectionsServer() {}
+func (UnimplementedElectionsServer) testEmbeddedByValue()                   {}
 
 // UnsafeElectionsServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to ElectionsServer will
@@ -109,6 +96,13 @@ type UnsafeElectionsServer interface {
 }
 
 func RegisterElectionsServer(s grpc.ServiceRegistrar, srv ElectionsServer) {
+	// If the following call pancis, it indicates UnimplementedElectionsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&Elections_ServiceDesc, srv)
 }
 
@@ -131,30 +125,11 @@ func _Elections_SubmitVote_Handler(srv interface{}, ctx context.Context, dec fun
 }
 
 func _Elections_Internal_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(ElectionsServer).Internal(&electionsInternalServer{stream})
-}
-
-type Elections_InternalServer interface {
-	Send(*StatsVote) error
-	Recv() (*Vote, error)
-	grpc.ServerStream
-}
-
-type electionsInternalServer struct {
-	grpc.ServerStream
+	return srv.(ElectionsServer).Internal(&grpc.GenericServerStream[Vote, StatsVote]{ServerStream: stream})
 }
 
-func (x *electionsInternalServer) Send(m *StatsVote) error {
-	return x.ServerStream.SendMsg(m)
-}
-
-func (x *electionsInternalServer) Recv() (*Vote, error) {
-	m := new(Vote)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
-}
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_InternalServer = grpc.BidiStreamingServer[Vote, StatsVote]
 
 // Elections_ServiceDesc is the grpc.ServiceDesc for Elections service.
 // It's only intended for direct use with grpc.RegisterService,
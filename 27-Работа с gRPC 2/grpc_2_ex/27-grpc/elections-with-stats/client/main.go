@@ -1,25 +1,58 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"flag"
 	"fmt"
-	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-stats/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/encoding/protojson"
 	"log"
+	"os"
 	"time"
 )
 
+var electionId = flag.Uint("election", 1, "election to watch")
+var token = flag.String("token", os.Getenv("ELECTIONS_TOKEN"), "JWT sent in the authorization metadata, ELECTIONS_TOKEN by default")
+var batchFile = flag.String("batch", "", "NDJSON file with votes to upload with SubmitVotes instead of watching stats")
+var atomic = flag.Bool("atomic", false, "apply the -batch votes all together or none of them")
+
+// go run ./elections-with-stats/client -batch votes.ndjson -atomic
+// votes.ndjson:
+// {"election_id": 1, "candidate_id": 1, "passport": "a"}
+// {"election_id": 1, "candidate_id": 2, "passport": "b"}
+
 func main() {
-	conn, err := grpc.NewClient(":50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	flag.Parse()
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(correlation.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(correlation.StreamClientInterceptor()),
+	}
+	if *token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.Token(*token)))
+	}
+	conn, err := grpc.NewClient(":50051", opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	client := pb.NewElectionsClient(conn)
+	if *batchFile != "" {
+		if err := uploadBatch(client, *batchFile, *atomic); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	stream, errStat := client.GetStats(context.Background(), &emptypb.Empty{})
+	stream, errStat := client.WatchStats(context.Background(), &pb.WatchStatsRequest{ElectionId: uint32(*electionId)})
 	if errStat != nil {
 		log.Fatal(errStat)
 	}
@@ -39,3 +72,46 @@ func main() {
 		log.Fatal(errClose)
 	}
 }
+
+// uploadBatch streams the votes of the NDJSON file, one vote per line in
+// the JSON form of pb.Vote, and prints the result of every vote.
+func uploadBatch(client pb.ElectionsClient, path string, atomic bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stream, err := client.SubmitVotes(correlation.NewContext(context.Background(), correlation.New()))
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(f)
+	for first := true; scanner.Scan(); {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		vote := &pb.Vote{}
+		if err := protojson.Unmarshal(line, vote); err != nil {
+			return fmt.Errorf("invalid vote %q: %w", line, err)
+		}
+		if err := stream.Send(&pb.SubmitVotesRequest{Vote: vote, Atomic: first && atomic}); err != nil {
+			return err
+		}
+		first = false
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	for _, r := range resp.Results {
+		fmt.Printf("%d: %s %s\n", r.Index, codes.Code(r.Code), r.Message)
+	}
+	fmt.Printf("accepted %d, rejected %d\n", resp.Accepted, resp.Rejected)
+	return nil
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-grpc v1.3.0
+// - protoc-gen-go-grpc v1.5.1
 // - protoc             v4.25.3
 // source: api/elections-with-stats/elections.proto
 
@@ -16,20 +16,38 @@ import (
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.32.0 or later.
-const _ = grpc.SupportPackageIsVersion7
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Elections_SubmitVote_FullMethodName = "/elections_with_stat.Elections/SubmitVote"
-	Elections_GetStats_FullMethodName   = "/elections_with_stat.Elections/GetStats"
+	Elections_SubmitVote_FullMethodName     = "/elections_with_stat.Elections/SubmitVote"
+	Elections_GetStats_FullMethodName       = "/elections_with_stat.Elections/GetStats"
+	Elections_SubmitVotes_FullMethodName    = "/elections_with_stat.Elections/SubmitVotes"
+	Elections_GetAuditHead_FullMethodName   = "/elections_with_stat.Elections/GetAuditHead"
+	Elections_ProveVote_FullMethodName      = "/elections_with_stat.Elections/ProveVote"
+	Elections_GetReceiptKeys_FullMethodName = "/elections_with_stat.Elections/GetReceiptKeys"
 )
 
 // ElectionsClient is the client API for Elections service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ElectionsClient interface {
-	SubmitVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	GetStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Elections_GetStatsClient, error)
+	// SubmitVote answers with the receipt of the vote, signed if the server
+	// has receipt keys, empty if neither they nor the audit log are on.
+	SubmitVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*VoteReceipt, error)
+	GetStats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Stats], error)
+	// SubmitVotes takes a batch of votes, like the upload of a polling
+	// station, and answers with the result of every vote once the stream ends.
+	SubmitVotes(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SubmitVotesRequest, SubmitVotesResponse], error)
+	// GetAuditHead returns the receipt of the last audit record, observers
+	// publish it so proofs can be checked against it.
+	GetAuditHead(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Receipt, error)
+	// ProveVote returns the proof that the record of the receipt is in the
+	// audit log, see 27-grpc/audit.
+	ProveVote(ctx context.Context, in *Receipt, opts ...grpc.CallOption) (*AuditProof, error)
+	// GetReceiptKeys returns the public keys receipts are signed with,
+	// including retired ones, see 27-grpc/receipts.
+	GetReceiptKeys(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReceiptKeys, error)
 }
 
 type electionsClient struct {
@@ -40,21 +58,23 @@ func NewElectionsClient(cc grpc.ClientConnInterface) ElectionsClient {
 	return &electionsClient{cc}
 }
 
-func (c *electionsClient) SubmitVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
-	err := c.cc.Invoke(ctx, Elections_SubmitVote_FullMethodName, in, out, opts...)
+func (c *electionsClient) SubmitVote(ctx context.Context, in *Vote, opts ...grpc.CallOption) (*VoteReceipt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VoteReceipt)
+	err := c.cc.Invoke(ctx, Elections_SubmitVote_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *electionsClient) GetStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Elections_GetStatsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Elections_ServiceDesc.Streams[0], Elections_GetStats_FullMethodName, opts...)
+func (c *electionsClient) GetStats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Stats], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Elections_ServiceDesc.Streams[0], Elections_GetStats_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &electionsGetStatsClient{stream}
+	x := &grpc.GenericClientStream[StatsRequest, Stats]{ClientStream: stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -64,43 +84,102 @@ func (c *electionsClient) GetStats(ctx context.Context, in *emptypb.Empty, opts
 	return x, nil
 }
 
-type Elections_GetStatsClient interface {
-	Recv() (*Stats, error)
-	grpc.ClientStream
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_GetStatsClient = grpc.ServerStreamingClient[Stats]
+
+func (c *electionsClient) SubmitVotes(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SubmitVotesRequest, SubmitVotesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Elections_ServiceDesc.Streams[1], Elections_SubmitVotes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubmitVotesRequest, SubmitVotesResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_SubmitVotesClient = grpc.ClientStreamingClient[SubmitVotesRequest, SubmitVotesResponse]
+
+func (c *electionsClient) GetAuditHead(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Receipt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Receipt)
+	err := c.cc.Invoke(ctx, Elections_GetAuditHead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type electionsGetStatsClient struct {
-	grpc.ClientStream
+func (c *electionsClient) ProveVote(ctx context.Context, in *Receipt, opts ...grpc.CallOption) (*AuditProof, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditProof)
+	err := c.cc.Invoke(ctx, Elections_ProveVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *electionsGetStatsClient) Recv() (*Stats, error) {
-	m := new(Stats)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *electionsClient) GetReceiptKeys(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReceiptKeys, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReceiptKeys)
+	err := c.cc.Invoke(ctx, Elections_GetReceiptKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
 // ElectionsServer is the server API for Elections service.
 // All implementations must embed UnimplementedElectionsServer
-// for forward compatibility
+// for forward compatibility.
 type ElectionsServer interface {
-	SubmitVote(context.Context, *Vote) (*emptypb.Empty, error)
-	GetStats(*emptypb.Empty, Elections_GetStatsServer) error
+	// SubmitVote answers with the receipt of the vote, signed if the server
+	// has receipt keys, empty if neither they nor the audit log are on.
+	SubmitVote(context.Context, *Vote) (*VoteReceipt, error)
+	GetStats(*StatsRequest, grpc.ServerStreamingServer[Stats]) error
+	// SubmitVotes takes a batch of votes, like the upload of a polling
+	// station, and answers with the result of every vote once the stream ends.
+	SubmitVotes(grpc.ClientStreamingServer[SubmitVotesRequest, SubmitVotesResponse]) error
+	// GetAuditHead returns the receipt of the last audit record, observers
+	// publish it so proofs can be checked against it.
+	GetAuditHead(context.Context, *emptypb.Empty) (*Receipt, error)
+	// ProveVote returns the proof that the record of the receipt is in the
+	// audit log, see 27-grpc/audit.
+	ProveVote(context.Context, *Receipt) (*AuditProof, error)
+	// GetReceiptKeys returns the public keys receipts are signed with,
+	// including retired ones, see 27-grpc/receipts.
+	GetReceiptKeys(context.Context, *emptypb.Empty) (*ReceiptKeys, error)
 	mustEmbedUnimplementedElectionsServer()
 }
 
-// UnimplementedElectionsServer must be embedded to have forward compatible implementations.
-type UnimplementedElectionsServer struct {
-}
+// UnimplementedElectionsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedElectionsServer struct{}
 
-func (UnimplementedElectionsServer) SubmitVote(context.Context, *Vote) (*emptypb.Empty, error) {
+func (UnimplementedElectionsServer) SubmitVote(context.Context, *Vote) (*VoteReceipt, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SubmitVote not implemented")
 }
-func (UnimplementedElectionsServer) GetStats(*emptypb.Empty, Elections_GetStatsServer) error {
+func (UnimplementedElectionsServer) GetStats(*StatsRequest, grpc.ServerStreamingServer[Stats]) error {
 	return status.Errorf(codes.Unimplemented, "method GetStats not implemented")
 }
+func (UnimplementedElectionsServer) SubmitVotes(grpc.ClientStreamingServer[SubmitVotesRequest, SubmitVotesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method SubmitVotes not implemented")
+}
+func (UnimplementedElectionsServer) GetAuditHead(context.Context, *emptypb.Empty) (*Receipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditHead not implemented")
+}
+func (UnimplementedElectionsServer) ProveVote(context.Context, *Receipt) (*AuditProof, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProveVote not implemented")
+}
+func (UnimplementedElectionsServer) GetReceiptKeys(context.Context, *emptypb.Empty) (*ReceiptKeys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReceiptKeys not implemented")
+}
 func (UnimplementedElectionsServer) mustEmbedUnimplementedElectionsServer() {}
+func (UnimplementedElectionsServer) testEmbeddedByValue()                   {}
 
 // UnsafeElectionsServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to ElectionsServer will
@@ -110,6 +189,13 @@ type UnsafeElectionsServer interface {
 }
 
 func RegisterElectionsServer(s grpc.ServiceRegistrar, srv ElectionsServer) {
+	// If the following call pancis, it indicates UnimplementedElectionsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&Elections_ServiceDesc, srv)
 }
 
@@ -132,24 +218,75 @@ func _Elections_SubmitVote_Handler(srv interface{}, ctx context.Context, dec fun
 }
 
 func _Elections_GetStats_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(emptypb.Empty)
+	m := new(StatsRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(ElectionsServer).GetStats(m, &electionsGetStatsServer{stream})
+	return srv.(ElectionsServer).GetStats(m, &grpc.GenericServerStream[StatsRequest, Stats]{ServerStream: stream})
 }
 
-type Elections_GetStatsServer interface {
-	Send(*Stats) error
-	grpc.ServerStream
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_GetStatsServer = grpc.ServerStreamingServer[Stats]
+
+func _Elections_SubmitVotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ElectionsServer).SubmitVotes(&grpc.GenericServerStream[SubmitVotesRequest, SubmitVotesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_SubmitVotesServer = grpc.ClientStreamingServer[SubmitVotesRequest, SubmitVotesResponse]
+
+func _Elections_GetAuditHead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsServer).GetAuditHead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Elections_GetAuditHead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsServer).GetAuditHead(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type electionsGetStatsServer struct {
-	grpc.ServerStream
+func _Elections_ProveVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Receipt)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsServer).ProveVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Elections_ProveVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsServer).ProveVote(ctx, req.(*Receipt))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (x *electionsGetStatsServer) Send(m *Stats) error {
-	return x.ServerStream.SendMsg(m)
+func _Elections_GetReceiptKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsServer).GetReceiptKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Elections_GetReceiptKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsServer).GetReceiptKeys(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
 // Elections_ServiceDesc is the grpc.ServiceDesc for Elections service.
@@ -163,6 +300,18 @@ var Elections_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SubmitVote",
 			Handler:    _Elections_SubmitVote_Handler,
 		},
+		{
+			MethodName: "GetAuditHead",
+			Handler:    _Elections_GetAuditHead_Handler,
+		},
+		{
+			MethodName: "ProveVote",
+			Handler:    _Elections_ProveVote_Handler,
+		},
+		{
+			MethodName: "GetReceiptKeys",
+			Handler:    _Elections_GetReceiptKeys_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -170,6 +319,11 @@ var Elections_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Elections_GetStats_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SubmitVotes",
+			Handler:       _Elections_SubmitVotes_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "api/elections-with-stats/elections.proto",
 }
@@ -1,24 +1,301 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	candidatespb "github.com/OtusGolang/webinars_practical_part/27-grpc/candidates/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
 	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-stats/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/validate"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/legacy"
+	v1 "github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/gateway"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/lifecycle"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/metrics"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/ratelimit"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/receipts"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
+var (
+	candidatesFile = flag.String("candidates", "", "JSON file with the initial list of candidates")
+	electionsFile  = flag.String("elections", "", "JSON file with elections, their ballots and voting windows")
+
+	rateLimit     = flag.Int("rate-limit", 0, "votes allowed per passport and per client IP within -rate-period, 0 disables the limit")
+	ratePeriod    = flag.Duration("rate-period", time.Minute, "rate limit period")
+	rateAlgorithm = flag.String("rate-algorithm", "token_bucket", "rate limit algorithm: token_bucket or sliding_window")
+	redisAddr     = flag.String("redis", "", "Redis address to share the rate limit between instances, kept in memory if empty")
+
+	jwtSecret   = flag.String("jwt-secret", os.Getenv("JWT_SECRET"), "HMAC secret of accepted JWTs, JWT_SECRET by default")
+	apiKeysFile = flag.String("api-keys", "", "JSON file with API keys and their roles")
+
+	auditPath = flag.String("audit-log", "", "hash-chained audit log of accepted votes, off if empty")
+	auditKey  = flag.String("audit-key", os.Getenv("AUDIT_KEY"), "HMAC key of the passports in the audit log, AUDIT_KEY by default")
+
+	statsInterval = flag.Duration("stats-interval", 2*time.Second, "how often GetStats and WatchStats send the stats if the subscriber sets no min_interval")
+
+	receiptKeys = flag.String("receipt-keys", "", "JSON file with the Ed25519 keys vote receipts are signed with, see receipts/keygen; unsigned if empty")
+
+	drainDelay      = flag.Duration("drain-delay", 5*time.Second, "time between the health service reporting NOT_SERVING and the server stopping")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 15*time.Second, "time running calls have to finish on shutdown")
+
+	metricsAddr = flag.String("metrics-addr", ":9090", "address serving /metrics in the Prometheus format, empty turns it off")
+	gatewayAddr = flag.String("gateway-addr", ":8080", "address serving elections.v1 over HTTP/JSON, see electionsv1.GatewayRules; empty turns it off")
+)
+
+// newLimiter returns nil if rate limiting is off.
+func newLimiter() (ratelimit.Limiter, error) {
+	if *rateLimit <= 0 {
+		return nil, nil
+	}
+	return ratelimit.New(ratelimit.Config{
+		Algorithm: ratelimit.Algorithm(*rateAlgorithm),
+		Rate:      ratelimit.Rate{Limit: *rateLimit, Period: *ratePeriod},
+		RedisAddr: *redisAddr,
+	})
+}
+
+// newAuthenticator returns nil if no credentials are configured.
+func newAuthenticator() (*auth.Authenticator, error) {
+	a, err := auth.New(auth.Config{JWTSecret: *jwtSecret, APIKeysFile: *apiKeysFile})
+	if err == nil && a == nil {
+		log.Printf("auth is off, set -jwt-secret or -api-keys to turn it on")
+	}
+	return a, err
+}
+
+// newAuditLog returns nil if the audit log is off.
+func newAuditLog() (*audit.Log, error) {
+	if *auditPath == "" {
+		return nil, nil
+	}
+	if *auditKey == "" {
+		return nil, errors.New("the audit log needs -audit-key")
+	}
+	return audit.OpenFile(*auditPath, []byte(*auditKey))
+}
+
+// newKeyring returns nil if receipts are not signed.
+func newKeyring() (*receipts.Keyring, error) {
+	if *receiptKeys == "" {
+		return nil, nil
+	}
+	k, err := receipts.LoadFile(*receiptKeys)
+	if err == nil && k.SigningKey() == "" {
+		err = receipts.ErrNoSigningKey
+	}
+	return k, err
+}
+
+// newGateway returns nil if the gateway is off. It calls the server over
+// loopback, so votes pass the same interceptors; the address of the HTTP
+// client is forwarded for the limits by IP, see clientIP. The connection
+// is closed once the servers stop.
+func newGateway(lsn net.Listener) (*gateway.Gateway, *grpc.ClientConn, error) {
+	if *gatewayAddr == "" {
+		return nil, nil, nil
+	}
+	addr := net.JoinHostPort("localhost", strconv.Itoa(lsn.Addr().(*net.TCPAddr).Port))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, err
+	}
+	gw, err := gateway.New(conn, electionsv1.GatewayRules...)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return gw, conn, nil
+}
+
+// clientIP limits by the peer IP, the calls of the gateway by the address
+// of its client. Only loopback peers are trusted to forward it, the
+// gateway calls the server over loopback.
+func clientIP() ratelimit.KeyFunc {
+	if *gatewayAddr == "" {
+		return ratelimit.PeerIP
+	}
+	return ratelimit.ForwardedIP("127.0.0.1", "::1")
+}
+
+// newRunner registers the health service, it reports NOT_SERVING as soon
+// as the shutdown starts. The metrics and the gateway, if it is on, are
+// served next to the gRPC server.
+func newRunner(server *grpc.Server, lsn net.Listener, m *metrics.Metrics, gw *gateway.Gateway) *lifecycle.Runner {
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	runner := lifecycle.New()
+	runner.DrainDelay = *drainDelay
+	runner.ShutdownTimeout = *shutdownTimeout
+	runner.AddServer("grpc", lifecycle.GRPC(server, lsn))
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", m.Handler())
+		runner.AddServer("metrics", lifecycle.HTTP(&http.Server{Addr: *metricsAddr, Handler: mux}))
+	}
+	if gw != nil {
+		runner.AddServer("gateway", lifecycle.HTTP(&http.Server{Addr: *gatewayAddr, Handler: gw}))
+	}
+	runner.OnReady(func(ready bool) {
+		if ready {
+			healthServer.Resume()
+		} else {
+			healthServer.Shutdown()
+		}
+	})
+	return runner
+}
+
 func main() {
+	flag.Parse()
+	if *statsInterval <= 0 {
+		log.Fatal("-stats-interval must be positive")
+	}
+
+	registry := candidates.NewRegistry()
+	if *candidatesFile != "" {
+		if err := registry.LoadFile(*candidatesFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	electionRegistry := elections.NewRegistry()
+	if *electionsFile != "" {
+		if err := electionRegistry.LoadFile(*electionsFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	lsn, err := net.Listen("tcp", ":50051")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	server := grpc.NewServer()
-	pb.RegisterElectionsServer(server, NewService())
+	limiter, err := newLimiter()
+	if err != nil {
+		log.Fatal(err)
+	}
+	authenticator, err := newAuthenticator()
+	if err != nil {
+		log.Fatal(err)
+	}
+	auditLog, err := newAuditLog()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if auditLog != nil {
+		defer auditLog.Close()
+	}
+	keyring, err := newKeyring()
+	if err != nil {
+		log.Fatal(err)
+	}
+	validator, err := validate.New(slices.Concat(electionsv1.ValidationRules, legacy.ValidationRules)...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	m := metrics.New()
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(correlation.UnaryServerInterceptor(), m.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(
+			correlation.StreamServerInterceptor(),
+			m.StreamServerInterceptor(),
+			m.StatsStreamInterceptor(v1.Elections_WatchStats_FullMethodName, pb.Elections_GetStats_FullMethodName),
+		),
+	}
+	if authenticator != nil {
+		rules := auth.Merge(candidates.AuthRules, electionsv1.AuthRules, auth.Rules{
+			// elections_with_stat, as elections.v1
+			pb.Elections_SubmitVote_FullMethodName:  {auth.RoleVoter},
+			pb.Elections_SubmitVotes_FullMethodName: {auth.RoleVoter},
+			pb.Elections_GetStats_FullMethodName:    {auth.RoleObserver},
+			// voters check their receipts, observers the whole log
+			pb.Elections_GetAuditHead_FullMethodName:   {auth.RoleVoter, auth.RoleObserver},
+			pb.Elections_ProveVote_FullMethodName:      {auth.RoleVoter, auth.RoleObserver},
+			pb.Elections_GetReceiptKeys_FullMethodName: nil,
+			// probes
+			healthpb.Health_Check_FullMethodName: nil,
+			healthpb.Health_Watch_FullMethodName: nil,
+		})
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(authenticator, rules)),
+			grpc.ChainStreamInterceptor(auth.StreamServerInterceptor(authenticator, rules)),
+		)
+	}
+	// SubmitVotes counts once for the client and its votes by passport in
+	// service.VoteLimit, a limited vote would fail the stream as a whole and
+	// drop the upload of a polling station
+	if limiter != nil {
+		opts = append(opts, grpc.ChainUnaryInterceptor(
+			ratelimit.UnaryServerInterceptor(limiter, ratelimit.MessageField("vote.passport")),
+			ratelimit.UnaryServerInterceptor(limiter, ratelimit.MessageField("passport")),
+			ratelimit.UnaryServerInterceptor(limiter, ratelimit.ForMethods(clientIP(),
+				v1.Elections_SubmitVote_FullMethodName, pb.Elections_SubmitVote_FullMethodName)),
+		), grpc.ChainStreamInterceptor(
+			ratelimit.StreamCallServerInterceptor(limiter, ratelimit.ForMethods(clientIP(),
+				v1.Elections_SubmitVotes_FullMethodName)),
+		))
+	}
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(
+			m.VoteInterceptor("vote.candidate_id", v1.Elections_SubmitVote_FullMethodName),
+			m.VoteInterceptor("candidate_id", pb.Elections_SubmitVote_FullMethodName),
+			validate.UnaryServerInterceptor(validator),
+		),
+		grpc.ChainStreamInterceptor(validate.StreamServerInterceptor(validator)),
+	)
+
+	server := grpc.NewServer(opts...)
+	voting := elections.NewVoting(registry, electionRegistry, elections.NewMemoryTally())
+	service := electionsv1.NewService(electionRegistry, voting)
+	service.Metrics = m
+	if limiter != nil {
+		// every vote of a SubmitVotes batch counts for its passport, like a
+		// SubmitVote call, the batch counts once for the client
+		service.VoteLimit = electionsv1.LimitVotes(limiter, ratelimit.MessageField("passport"))
+	}
+	service.Interval = *statsInterval
+	voting.Audit = auditLog
+	voting.Receipts = keyring
+	v1.RegisterElectionsServer(server, service)
+	v1.RegisterElectionsAdminServer(server, electionsv1.NewAdminService(service))
+	pb.RegisterElectionsServer(server, legacy.NewStatsService(service))
+	candidatespb.RegisterCandidatesServer(server, candidates.NewGRPCService(registry))
+
+	gw, conn, err := newGateway(lsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if conn != nil {
+		defer conn.Close()
+	}
+	runner := newRunner(server, lsn, m, gw)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	log.Printf("starting server on %s", lsn.Addr().String())
-	if err := server.Serve(lsn); err != nil {
+	if err := runner.Run(ctx); err != nil {
 		log.Fatal(err)
 	}
+	log.Printf("server stopped")
 }
@@ -4,9 +4,9 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/metadata"
 	"log"
 	"os"
 	"strconv"
@@ -14,6 +14,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
 	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -31,8 +33,21 @@ func GenerateActionId() string {
 	return fmt.Sprintf("%v:%v", time.Now().UTC().Format("20060102150405"), curId)
 }
 
+var electionId = flag.Uint("election", 1, "election to vote in")
+var token = flag.String("token", os.Getenv("ELECTIONS_TOKEN"), "JWT sent in the authorization metadata, ELECTIONS_TOKEN by default")
+
 func main() {
-	conn, err := grpc.NewClient(":50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	flag.Parse()
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(correlation.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(correlation.StreamClientInterceptor()),
+	}
+	if *token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.Token(*token)))
+	}
+	conn, err := grpc.NewClient(":50051", opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -47,9 +62,8 @@ func main() {
 			continue
 		}
 
-		md := metadata.New(nil)
-		md.Append("request_id", GenerateActionId())
-		ctx := metadata.NewOutgoingContext(context.Background(), md)
+		// the interceptor sends it in the x-request-id metadata
+		ctx := correlation.NewContext(context.Background(), GenerateActionId())
 		var response *pb.SubmitVoteResponse
 		if response, err = client.SubmitVote(ctx, req); err != nil {
 			log.Println(err)
@@ -82,6 +96,7 @@ func getRequest(reader *bufio.Reader) (*pb.SubmitVoteRequest, error) {
 			CandidateId: uint32(id),
 			Note:        strings.Join(parts[2:], " "),
 			Time:        timestamppb.Now(),
+			ElectionId:  uint32(*electionId),
 		},
 	}, nil
 }
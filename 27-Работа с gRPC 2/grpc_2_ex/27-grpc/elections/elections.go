@@ -0,0 +1,249 @@
+// Package elections describes elections votes are cast in: the candidates
+// on the ballot, the voting window and the revote policy. Voting applies
+// the rules to votes for every transport (HTTP and gRPC), which only map
+// its errors to their status codes, see Reason.
+package elections
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/dedup"
+)
+
+var (
+	ErrNotFound         = errors.New("election not found")
+	ErrExists           = errors.New("election already exists")
+	ErrInvalid          = errors.New("invalid election")
+	ErrNotStarted       = errors.New("election is not open yet")
+	ErrFinished         = errors.New("election is closed")
+	ErrUnknownCandidate = errors.New("candidate is not on the ballot")
+	// ErrFrozen is returned on attempt to change an election after it opened.
+	ErrFrozen = errors.New("election can't be changed after it opened")
+	// ErrInvalidVote is wrapped by ValidationError.
+	ErrInvalidVote = errors.New("invalid vote")
+	// ErrCandidateNotAllowed is returned for a vote for an unknown or
+	// inactive candidate, it wraps the error of candidates.Registry.
+	ErrCandidateNotAllowed = errors.New("candidate is not allowed")
+	// ErrAlreadyVoted and ErrAborted are the errors of dedup.Guard.
+	ErrAlreadyVoted = dedup.ErrAlreadyVoted
+	ErrAborted      = dedup.ErrAborted
+)
+
+type Status string
+
+const (
+	StatusScheduled Status = "scheduled"
+	StatusOpen      Status = "open"
+	StatusClosed    Status = "closed"
+)
+
+type Election struct {
+	Id          uint32    `json:"id" xml:"id"`
+	Title       string    `json:"title" xml:"title"`
+	Candidates  []uint32  `json:"candidates" xml:"candidates>candidate_id"`
+	OpensAt     time.Time `json:"opens_at" xml:"opens_at"`
+	ClosesAt    time.Time `json:"closes_at" xml:"closes_at"`
+	AllowRevote bool      `json:"allow_revote,omitempty" xml:"allow_revote,omitempty"`
+}
+
+// Status reports where the election is relative to its voting window,
+// which is [OpensAt, ClosesAt).
+func (e Election) Status(now time.Time) Status {
+	switch {
+	case now.Before(e.OpensAt):
+		return StatusScheduled
+	case now.Before(e.ClosesAt):
+		return StatusOpen
+	default:
+		return StatusClosed
+	}
+}
+
+func (e Election) HasCandidate(candidateId uint32) bool {
+	for _, id := range e.Candidates {
+		if id == candidateId {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckVote reports whether a vote received at now can be accepted.
+func (e Election) CheckVote(now time.Time, candidateId uint32) error {
+	switch e.Status(now) {
+	case StatusScheduled:
+		return fmt.Errorf("%w: %d opens at %s", ErrNotStarted, e.Id, e.OpensAt.Format(time.RFC3339))
+	case StatusClosed:
+		return fmt.Errorf("%w: %d closed at %s", ErrFinished, e.Id, e.ClosesAt.Format(time.RFC3339))
+	}
+	if !e.HasCandidate(candidateId) {
+		return fmt.Errorf("%w: candidate %d, election %d", ErrUnknownCandidate, candidateId, e.Id)
+	}
+	return nil
+}
+
+func (e Election) validate() error {
+	if strings.TrimSpace(e.Title) == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalid)
+	}
+	if len(e.Candidates) == 0 {
+		return fmt.Errorf("%w: candidates are required", ErrInvalid)
+	}
+	if e.OpensAt.IsZero() || e.ClosesAt.IsZero() || !e.OpensAt.Before(e.ClosesAt) {
+		return fmt.Errorf("%w: opens_at must be before closes_at", ErrInvalid)
+	}
+	return nil
+}
+
+// entry is never modified once it is in the registry, Update replaces it,
+// so Admit and Restore use it after releasing the lock.
+type entry struct {
+	election Election
+	guard    *dedup.Guard
+}
+
+// Registry keeps elections together with the passport guard of each one,
+// so a passport can vote once in every election.
+type Registry struct {
+	lock      sync.RWMutex
+	elections map[uint32]*entry
+	lastId    uint32
+	now       func() time.Time
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		elections: make(map[uint32]*entry),
+		now:       time.Now,
+	}
+}
+
+// Create adds an election, a zero id is replaced with the next free one.
+func (r *Registry) Create(e Election) (Election, error) {
+	if err := e.validate(); err != nil {
+		return Election{}, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if e.Id == 0 {
+		e.Id = r.lastId + 1
+	}
+	if _, ok := r.elections[e.Id]; ok {
+		return Election{}, fmt.Errorf("%w: %d", ErrExists, e.Id)
+	}
+	e.Candidates = append([]uint32(nil), e.Candidates...)
+	r.elections[e.Id] = &entry{
+		election: e,
+		guard:    dedup.NewGuard(dedup.Policy{AllowRevote: e.AllowRevote}),
+	}
+	if e.Id > r.lastId {
+		r.lastId = e.Id
+	}
+	return e, nil
+}
+
+// Update replaces a scheduled election, an opened one is frozen.
+func (r *Registry) Update(e Election) (Election, error) {
+	if err := e.validate(); err != nil {
+		return Election{}, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cur, ok := r.elections[e.Id]
+	if !ok {
+		return Election{}, fmt.Errorf("%w: %d", ErrNotFound, e.Id)
+	}
+	if cur.election.Status(r.now()) != StatusScheduled {
+		return Election{}, fmt.Errorf("%w: %d", ErrFrozen, e.Id)
+	}
+	e.Candidates = append([]uint32(nil), e.Candidates...)
+	r.elections[e.Id] = &entry{
+		election: e,
+		guard:    dedup.NewGuard(dedup.Policy{AllowRevote: e.AllowRevote}),
+	}
+	return e, nil
+}
+
+func (r *Registry) Get(id uint32) (Election, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	e, ok := r.elections[id]
+	if !ok {
+		return Election{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return e.election, nil
+}
+
+// List returns elections ordered by id.
+func (r *Registry) List() []Election {
+	r.lock.RLock()
+	list := make([]Election, 0, len(r.elections))
+	for _, e := range r.elections {
+		list = append(list, e.election)
+	}
+	r.lock.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	return list
+}
+
+// Admit checks a vote received at now against the election window and
+// ballot and returns the guard that enforces the election revote policy.
+func (r *Registry) Admit(electionId, candidateId uint32, now time.Time) (Election, *dedup.Guard, error) {
+	r.lock.RLock()
+	e, ok := r.elections[electionId]
+	r.lock.RUnlock()
+	if !ok {
+		return Election{}, nil, fmt.Errorf("%w: %d", ErrNotFound, electionId)
+	}
+	if err := e.election.CheckVote(now, candidateId); err != nil {
+		return Election{}, nil, err
+	}
+	return e.election, e.guard, nil
+}
+
+// Restore marks the passport as voted in the election, it is used to
+// rebuild guards from persisted votes on startup.
+func (r *Registry) Restore(electionId uint32, passport string, candidateId uint32) error {
+	r.lock.RLock()
+	e, ok := r.elections[electionId]
+	r.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrNotFound, electionId)
+	}
+	e.guard.Restore(passport, candidateId)
+	return nil
+}
+
+// LoadFile creates elections from a JSON array like
+// [{"id": 1, "title": "Mayor", "candidates": [1, 2],
+// "opens_at": "2024-09-08T08:00:00Z", "closes_at": "2024-09-08T20:00:00Z"}].
+func (r *Registry) LoadFile(path string) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read elections file: %w", err)
+	}
+
+	var list []Election
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return fmt.Errorf("cannot parse elections file: %w", err)
+	}
+	for _, e := range list {
+		if _, err := r.Create(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package elections
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Admit(t *testing.T) {
+	opens := time.Date(2024, 9, 8, 8, 0, 0, 0, time.UTC)
+	closes := opens.Add(12 * time.Hour)
+
+	r := NewRegistry()
+	e, err := r.Create(Election{Title: "Mayor", Candidates: []uint32{1, 2}, OpensAt: opens, ClosesAt: closes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		electionId uint32
+		candidate  uint32
+		now        time.Time
+		exp        error
+	}{
+		{"before_open", e.Id, 1, opens.Add(-time.Second), ErrNotStarted},
+		{"at_open", e.Id, 1, opens, nil},
+		{"last_second", e.Id, 2, closes.Add(-time.Second), nil},
+		{"at_close", e.Id, 1, closes, ErrFinished},
+		{"not_on_ballot", e.Id, 3, opens, ErrUnknownCandidate},
+		{"unknown_election", 42, 1, opens, ErrNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, guard, err := r.Admit(c.electionId, c.candidate, c.now)
+			if !errors.Is(err, c.exp) {
+				t.Fatalf("expected %v, got %v", c.exp, err)
+			}
+			if err == nil && guard == nil {
+				t.Fatal("expected guard")
+			}
+		})
+	}
+}
+
+func TestRegistry_Guards(t *testing.T) {
+	now := time.Now()
+	r := NewRegistry()
+	for _, e := range []Election{
+		{Id: 1, Title: "Mayor", Candidates: []uint32{1}, OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour)},
+		{Id: 2, Title: "Council", Candidates: []uint32{1}, OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour), AllowRevote: true},
+	} {
+		if _, err := r.Create(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, mayor, _ := r.Admit(1, 1, now)
+	_, council, _ := r.Admit(2, 1, now)
+	if mayor == council {
+		t.Fatal("every election must have its own guard")
+	}
+	if mayor.Policy().AllowRevote || !council.Policy().AllowRevote {
+		t.Fatal("guard policy must follow the election")
+	}
+}
+
+func TestRegistry_Update(t *testing.T) {
+	now := time.Now()
+	r := NewRegistry()
+	r.now = func() time.Time { return now }
+
+	scheduled := Election{Id: 1, Title: "Mayor", Candidates: []uint32{1}, OpensAt: now.Add(time.Hour), ClosesAt: now.Add(2 * time.Hour)}
+	open := Election{Id: 2, Title: "Council", Candidates: []uint32{1}, OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour)}
+	for _, e := range []Election{scheduled, open} {
+		if _, err := r.Create(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scheduled.Candidates = []uint32{1, 2}
+	if _, err := r.Update(scheduled); err != nil {
+		t.Fatalf("scheduled election must be editable: %v", err)
+	}
+	open.Title = "City council"
+	if _, err := r.Update(open); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected ErrFrozen, got %v", err)
+	}
+	if _, err := r.Create(Election{Title: "Bad", Candidates: []uint32{1}, OpensAt: now, ClosesAt: now}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+}
+
+// TestRegistry_UpdateAdmit runs Update next to Admit and Restore, -race
+// reports a change of an entry that is in use.
+func TestRegistry_UpdateAdmit(t *testing.T) {
+	now := time.Now()
+	r := NewRegistry()
+	e := Election{Id: 1, Title: "Mayor", Candidates: []uint32{1}, OpensAt: now.Add(time.Hour), ClosesAt: now.Add(2 * time.Hour)}
+	if _, err := r.Create(e); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			e.Title = fmt.Sprint("Mayor ", i)
+			if _, err := r.Update(e); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			r.Admit(1, 1, now.Add(time.Hour))
+			r.Restore(1, "a", 1)
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,208 @@
+// Package electionstest contains the suite every transport of
+// elections.Voting has to pass, so votes sent over HTTP and gRPC are
+// accepted and rejected alike.
+package electionstest
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+)
+
+// Client sends requests over a transport and returns the Reason of the
+// rejection the transport answered with, elections.ReasonNone if the
+// request succeeded. Transport failures fail t.
+type Client interface {
+	Submit(t *testing.T, vote elections.Vote) elections.Reason
+	// SubmitAll sends the votes as an atomic batch and returns the reason
+	// of every vote.
+	SubmitAll(t *testing.T, votes []elections.Vote) []elections.Reason
+	Stats(t *testing.T, electionId uint32) (records map[elections.CandidateID]uint32, final bool, reason elections.Reason)
+}
+
+// Factory returns a client of a server voting with the registries, which
+// the suite has filled with the fixture.
+type Factory func(t *testing.T, candidateRegistry *candidates.Registry, electionRegistry *elections.Registry) Client
+
+// The fixture: Alice, Bob and Dave can be voted for, Carol is inactive.
+const (
+	Alice elections.CandidateID = iota + 1
+	Bob
+	Carol
+	Dave
+)
+
+// Elections of the fixture, Dave is on no ballot.
+const (
+	Open uint32 = iota + 1
+	Scheduled
+	Closed
+	Revote
+)
+
+func fixture(t *testing.T) (*candidates.Registry, *elections.Registry) {
+	t.Helper()
+	candidateRegistry := candidates.NewRegistry()
+	for _, c := range []candidates.Candidate{
+		{Name: "Alice", Active: true},
+		{Name: "Bob", Active: true},
+		{Name: "Carol"},
+		{Name: "Dave", Active: true},
+	} {
+		if _, err := candidateRegistry.Create(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	ballot := []uint32{uint32(Alice), uint32(Bob), uint32(Carol)}
+	electionRegistry := elections.NewRegistry()
+	for _, e := range []elections.Election{
+		{Id: Open, Title: "Mayor", OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour)},
+		{Id: Scheduled, Title: "Council", OpensAt: now.Add(time.Hour), ClosesAt: now.Add(2 * time.Hour)},
+		{Id: Closed, Title: "Governor", OpensAt: now.Add(-2 * time.Hour), ClosesAt: now.Add(-time.Hour)},
+		{Id: Revote, Title: "Poll", OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour), AllowRevote: true},
+	} {
+		e.Candidates = ballot
+		if _, err := electionRegistry.Create(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return candidateRegistry, electionRegistry
+}
+
+func Run(t *testing.T, newClient Factory) {
+	client := func(t *testing.T) Client {
+		candidateRegistry, electionRegistry := fixture(t)
+		return newClient(t, candidateRegistry, electionRegistry)
+	}
+	vote := func(electionId uint32, passport string, candidateId elections.CandidateID) elections.Vote {
+		return elections.Vote{ElectionId: electionId, Passport: passport, CandidateId: candidateId}
+	}
+
+	t.Run("submit", func(t *testing.T) {
+		cases := []struct {
+			name string
+			vote elections.Vote
+			exp  elections.Reason
+		}{
+			{"accepted", vote(Open, "a", Alice), elections.ReasonNone},
+			{"empty", elections.Vote{}, elections.ReasonValidation},
+			{"no_passport", vote(Open, "", Alice), elections.ReasonValidation},
+			{"no_candidate", vote(Open, "a", 0), elections.ReasonValidation},
+			{"no_election", vote(0, "a", Alice), elections.ReasonValidation},
+			{"unknown_candidate", vote(Open, "a", 42), elections.ReasonCandidateNotAllowed},
+			{"inactive_candidate", vote(Open, "a", Carol), elections.ReasonCandidateNotAllowed},
+			{"not_on_ballot", vote(Open, "a", Dave), elections.ReasonCandidateNotAllowed},
+			{"unknown_election", vote(42, "a", Alice), elections.ReasonNotFound},
+			{"scheduled", vote(Scheduled, "a", Alice), elections.ReasonNotStarted},
+			{"closed", vote(Closed, "a", Alice), elections.ReasonClosed},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				if reason := client(t).Submit(t, c.vote); reason != c.exp {
+					t.Fatalf("expected %q, got %q", c.exp, reason)
+				}
+			})
+		}
+	})
+
+	t.Run("twice", func(t *testing.T) {
+		c := client(t)
+		if reason := c.Submit(t, vote(Open, "a", Alice)); reason != elections.ReasonNone {
+			t.Fatalf("expected the first vote to be accepted, got %q", reason)
+		}
+		if reason := c.Submit(t, vote(Open, "a", Bob)); reason != elections.ReasonAlreadyVoted {
+			t.Fatalf("expected %q, got %q", elections.ReasonAlreadyVoted, reason)
+		}
+		// a passport votes once in every election
+		if reason := c.Submit(t, vote(Revote, "a", Bob)); reason != elections.ReasonNone {
+			t.Fatalf("expected the vote in another election to be accepted, got %q", reason)
+		}
+		expectStats(t, c, Open, map[elections.CandidateID]uint32{Alice: 1}, false)
+	})
+
+	t.Run("revote", func(t *testing.T) {
+		c := client(t)
+		for _, v := range []elections.Vote{vote(Revote, "a", Alice), vote(Revote, "a", Bob), vote(Revote, "b", Bob)} {
+			if reason := c.Submit(t, v); reason != elections.ReasonNone {
+				t.Fatalf("expected %v to be accepted, got %q", v, reason)
+			}
+		}
+		records, _, _ := c.Stats(t, Revote)
+		if records[Alice] != 0 || records[Bob] != 2 {
+			t.Fatalf("expected the vote for Alice to be replaced, got %v", records)
+		}
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		c := client(t)
+		reasons := c.SubmitAll(t, []elections.Vote{vote(Open, "a", Alice), vote(Open, "b", Bob)})
+		expectReasons(t, reasons, elections.ReasonNone, elections.ReasonNone)
+		expectStats(t, c, Open, map[elections.CandidateID]uint32{Alice: 1, Bob: 1}, false)
+	})
+
+	t.Run("atomic_abort", func(t *testing.T) {
+		c := client(t)
+		reasons := c.SubmitAll(t, []elections.Vote{vote(Open, "a", Alice), vote(Open, "b", Carol), vote(Open, "c", Bob)})
+		expectReasons(t, reasons, elections.ReasonAborted, elections.ReasonCandidateNotAllowed, elections.ReasonAborted)
+		expectStats(t, c, Open, nil, false)
+
+		// nothing was applied, the passports can still vote
+		if reason := c.Submit(t, vote(Open, "a", Alice)); reason != elections.ReasonNone {
+			t.Fatalf("expected the vote to be accepted after the abort, got %q", reason)
+		}
+	})
+
+	t.Run("atomic_already_voted", func(t *testing.T) {
+		c := client(t)
+		if reason := c.Submit(t, vote(Open, "a", Alice)); reason != elections.ReasonNone {
+			t.Fatalf("expected the vote to be accepted, got %q", reason)
+		}
+		reasons := c.SubmitAll(t, []elections.Vote{vote(Open, "b", Alice), vote(Open, "a", Bob)})
+		expectReasons(t, reasons, elections.ReasonAborted, elections.ReasonAlreadyVoted)
+		expectStats(t, c, Open, map[elections.CandidateID]uint32{Alice: 1}, false)
+	})
+
+	t.Run("atomic_mixed_elections", func(t *testing.T) {
+		c := client(t)
+		reasons := c.SubmitAll(t, []elections.Vote{vote(Open, "a", Alice), vote(Revote, "b", Bob)})
+		expectReasons(t, reasons, elections.ReasonAborted, elections.ReasonValidation)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		c := client(t)
+		expectStats(t, c, Closed, nil, true)
+		if _, _, reason := c.Stats(t, 42); reason != elections.ReasonNotFound {
+			t.Fatalf("expected %q for an unknown election, got %q", elections.ReasonNotFound, reason)
+		}
+	})
+}
+
+func expectReasons(t *testing.T, reasons []elections.Reason, exp ...elections.Reason) {
+	t.Helper()
+	if !slices.Equal(reasons, exp) {
+		t.Fatalf("expected %q, got %q", exp, reasons)
+	}
+}
+
+// expectStats compares the records without the candidates at zero,
+// transports may list them or not.
+func expectStats(t *testing.T, c Client, electionId uint32, exp map[elections.CandidateID]uint32, final bool) {
+	t.Helper()
+	records, isFinal, reason := c.Stats(t, electionId)
+	if reason != elections.ReasonNone {
+		t.Fatalf("unable to get stats of election %d: %q", electionId, reason)
+	}
+	maps.DeleteFunc(records, func(_ elections.CandidateID, v uint32) bool { return v == 0 })
+	if !maps.Equal(records, exp) {
+		t.Fatalf("expected records %v, got %v", exp, records)
+	}
+	if isFinal != final {
+		t.Fatalf("expected final %t, got %t", final, isFinal)
+	}
+}
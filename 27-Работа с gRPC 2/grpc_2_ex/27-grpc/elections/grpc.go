@@ -0,0 +1,78 @@
+package elections
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the ErrorInfo domain of the statuses of StatusError.
+const errorDomain = "elections"
+
+var reasonCodes = map[Reason]codes.Code{
+	ReasonValidation:          codes.InvalidArgument,
+	ReasonCandidateNotAllowed: codes.InvalidArgument,
+	ReasonInvalid:             codes.InvalidArgument,
+	ReasonNotFound:            codes.NotFound,
+	ReasonNotStarted:          codes.FailedPrecondition,
+	ReasonClosed:              codes.FailedPrecondition,
+	ReasonConflict:            codes.FailedPrecondition,
+	ReasonAlreadyVoted:        codes.AlreadyExists,
+	ReasonAborted:             codes.Aborted,
+	ReasonInternal:            codes.Internal,
+}
+
+// StatusError converts errors of Voting and Registry to gRPC status errors,
+// the Reason is sent in an ErrorInfo detail in upper case and the
+// violations of a ValidationError in a BadRequest. A status error is
+// returned as is.
+func StatusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	reason := ReasonOf(err)
+	code := reasonCodes[reason]
+	if errors.Is(err, ErrExists) {
+		code = codes.AlreadyExists
+	}
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: strings.ToUpper(string(reason)), Domain: errorDomain}}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		details = append(details, badRequest(verr))
+	}
+	st := status.New(code, err.Error())
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func badRequest(err *ValidationError) *errdetails.BadRequest {
+	br := &errdetails.BadRequest{}
+	for _, v := range err.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: v.Field, Description: v.Reason})
+	}
+	return br
+}
+
+// ReasonFromStatus returns the Reason of a status error of StatusError,
+// ReasonValidation for a status with a BadRequest only, like the ones of
+// the validate interceptors, ReasonNone for nil or a status without them.
+func ReasonFromStatus(err error) Reason {
+	reason := ReasonNone
+	for _, d := range status.Convert(err).Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() == errorDomain {
+				return Reason(strings.ToLower(d.GetReason()))
+			}
+		case *errdetails.BadRequest:
+			reason = ReasonValidation
+		}
+	}
+	return reason
+}
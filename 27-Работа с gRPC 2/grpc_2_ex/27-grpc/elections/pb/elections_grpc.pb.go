@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-grpc v1.3.0
+// - protoc-gen-go-grpc v1.5.1
 // - protoc             v4.25.3
 // source: api/elections/elections.proto
 
@@ -15,8 +15,8 @@ import (
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.32.0 or later.
-const _ = grpc.SupportPackageIsVersion7
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
 
 const (
 	Elections_SubmitVote_FullMethodName = "/elections.Elections/SubmitVote"
@@ -38,8 +38,9 @@ func NewElectionsClient(cc grpc.ClientConnInterface) ElectionsClient {
 }
 
 func (c *electionsClient) SubmitVote(ctx context.Context, in *SubmitVoteRequest, opts ...grpc.CallOption) (*SubmitVoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SubmitVoteResponse)
-	err := c.cc.Invoke(ctx, Elections_SubmitVote_FullMethodName, in, out, opts...)
+	err := c.cc.Invoke(ctx, Elections_SubmitVote_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -48,20 +49,24 @@ func (c *electionsClient) SubmitVote(ctx context.Context, in *SubmitVoteRequest,
 
 // ElectionsServer is the server API for Elections service.
 // All implementations must embed UnimplementedElectionsServer
-// for forward compatibility
+// for forward compatibility.
 type ElectionsServer interface {
 	SubmitVote(context.Context, *SubmitVoteRequest) (*SubmitVoteResponse, error)
 	mustEmbedUnimplementedElectionsServer()
 }
 
-// UnimplementedElectionsServer must be embedded to have forward compatible implementations.
-type UnimplementedElectionsServer struct {
-}
+// UnimplementedElectionsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedElectionsServer struct{}
 
 func (UnimplementedElectionsServer) SubmitVote(context.Context, *SubmitVoteRequest) (*SubmitVoteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SubmitVote not implemented")
 }
 func (UnimplementedElectionsServer) mustEmbedUnimplementedElectionsServer() {}
+func (UnimplementedElectionsServer) testEmbeddedByValue()                   {}
 
 // UnsafeElectionsServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to ElectionsServer will
@@ -71,6 +76,13 @@ type UnsafeElectionsServer interface {
 }
 
 func RegisterElectionsServer(s grpc.ServiceRegistrar, srv ElectionsServer) {
+	// If the following call pancis, it indicates UnimplementedElectionsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&Elections_ServiceDesc, srv)
 }
 
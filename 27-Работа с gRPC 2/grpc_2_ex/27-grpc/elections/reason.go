@@ -0,0 +1,53 @@
+package elections
+
+import (
+	"errors"
+)
+
+// Reason names why a vote or an election request was rejected, the same on
+// every transport: HTTP sends it as the problem code, gRPC in the
+// ErrorInfo of the status.
+type Reason string
+
+const (
+	ReasonNone                Reason = ""
+	ReasonValidation          Reason = "validation_failed"
+	ReasonCandidateNotAllowed Reason = "candidate_not_allowed"
+	ReasonNotFound            Reason = "not_found"
+	ReasonNotStarted          Reason = "election_not_started"
+	ReasonClosed              Reason = "election_closed"
+	ReasonAlreadyVoted        Reason = "already_voted"
+	ReasonAborted             Reason = "batch_aborted"
+	ReasonConflict            Reason = "conflict"
+	ReasonInvalid             Reason = "invalid_request"
+	ReasonInternal            Reason = "internal"
+)
+
+// ReasonOf classifies an error of Voting or Registry, nil has no reason.
+// Errors it doesn't know, like the ones of the tally, are internal.
+func ReasonOf(err error) Reason {
+	switch {
+	case err == nil:
+		return ReasonNone
+	case errors.Is(err, ErrInvalidVote):
+		return ReasonValidation
+	case errors.Is(err, ErrCandidateNotAllowed), errors.Is(err, ErrUnknownCandidate):
+		return ReasonCandidateNotAllowed
+	case errors.Is(err, ErrNotFound):
+		return ReasonNotFound
+	case errors.Is(err, ErrNotStarted):
+		return ReasonNotStarted
+	case errors.Is(err, ErrFinished):
+		return ReasonClosed
+	case errors.Is(err, ErrAlreadyVoted):
+		return ReasonAlreadyVoted
+	case errors.Is(err, ErrAborted):
+		return ReasonAborted
+	case errors.Is(err, ErrExists), errors.Is(err, ErrFrozen):
+		return ReasonConflict
+	case errors.Is(err, ErrInvalid):
+		return ReasonInvalid
+	default:
+		return ReasonInternal
+	}
+}
@@ -2,61 +2,191 @@ package main
 
 import (
 	"context"
+	"flag"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	candidatespb "github.com/OtusGolang/webinars_practical_part/27-grpc/candidates/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
 	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/validate"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/status"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/legacy"
+	v1 "github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/lifecycle"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/metrics"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/ratelimit"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
 	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/pb"
 	"google.golang.org/grpc"
 )
 
-type Service struct {
-	pb.UnimplementedElectionsServer
-}
+var (
+	candidatesFile = flag.String("candidates", "", "JSON file with the initial list of candidates")
+	electionsFile  = flag.String("elections", "", "JSON file with elections, their ballots and voting windows")
 
-func (s *Service) SubmitVote(ctx context.Context, req *pb.SubmitVoteRequest) (*pb.SubmitVoteResponse, error) {
-	requestId := ""
-	if ctx != nil {
-		md, ok := metadata.FromIncomingContext(ctx)
-		if ok {
-			ids := md.Get("request_id")
-			if len(ids) > 0 {
-				requestId = ids[0]
-			}
-		}
-		vote := req.Vote
-		if vote == nil {
-			return nil, status.Error(codes.InvalidArgument, "vote is not specified")
-		}
+	rateLimit     = flag.Int("rate-limit", 0, "votes allowed per passport and per client IP within -rate-period, 0 disables the limit")
+	ratePeriod    = flag.Duration("rate-period", time.Minute, "rate limit period")
+	rateAlgorithm = flag.String("rate-algorithm", "token_bucket", "rate limit algorithm: token_bucket or sliding_window")
+	redisAddr     = flag.String("redis", "", "Redis address to share the rate limit between instances, kept in memory if empty")
+
+	jwtSecret   = flag.String("jwt-secret", os.Getenv("JWT_SECRET"), "HMAC secret of accepted JWTs, JWT_SECRET by default")
+	apiKeysFile = flag.String("api-keys", "", "JSON file with API keys and their roles")
+
+	drainDelay      = flag.Duration("drain-delay", 5*time.Second, "time between the health service reporting NOT_SERVING and the server stopping")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 15*time.Second, "time running calls have to finish on shutdown")
 
+	metricsAddr = flag.String("metrics-addr", ":9090", "address serving /metrics in the Prometheus format, empty turns it off")
+)
+
+// newLimiter returns nil if rate limiting is off.
+func newLimiter() (ratelimit.Limiter, error) {
+	if *rateLimit <= 0 {
+		return nil, nil
 	}
-	log.Printf("new vote receive (passport=%s, candidate_id=%d, time=%v, request_id: %v)",
-		req.GetVote().GetPassport(), req.GetVote().CandidateId, req.GetVote().GetTime().AsTime(), requestId)
+	return ratelimit.New(ratelimit.Config{
+		Algorithm: ratelimit.Algorithm(*rateAlgorithm),
+		Rate:      ratelimit.Rate{Limit: *rateLimit, Period: *ratePeriod},
+		RedisAddr: *redisAddr,
+	})
+}
 
-	log.Printf("vote accepted")
-	return &pb.SubmitVoteResponse{
-		Ids: []int32{int32(req.GetVote().GetCandidateId())},
-	}, nil
+// newAuthenticator returns nil if no credentials are configured.
+func newAuthenticator() (*auth.Authenticator, error) {
+	a, err := auth.New(auth.Config{JWTSecret: *jwtSecret, APIKeysFile: *apiKeysFile})
+	if err == nil && a == nil {
+		log.Printf("auth is off, set -jwt-secret or -api-keys to turn it on")
+	}
+	return a, err
+}
+
+// newRunner registers the health service, it reports NOT_SERVING as soon
+// as the shutdown starts. The metrics are served next to the gRPC server.
+func newRunner(server *grpc.Server, lsn net.Listener, m *metrics.Metrics) *lifecycle.Runner {
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	runner := lifecycle.New()
+	runner.DrainDelay = *drainDelay
+	runner.ShutdownTimeout = *shutdownTimeout
+	runner.AddServer("grpc", lifecycle.GRPC(server, lsn))
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", m.Handler())
+		runner.AddServer("metrics", lifecycle.HTTP(&http.Server{Addr: *metricsAddr, Handler: mux}))
+	}
+	runner.OnReady(func(ready bool) {
+		if ready {
+			healthServer.Resume()
+		} else {
+			healthServer.Shutdown()
+		}
+	})
+	return runner
 }
 
 func main() {
+	flag.Parse()
+
+	registry := candidates.NewRegistry()
+	if *candidatesFile != "" {
+		if err := registry.LoadFile(*candidatesFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	electionRegistry := elections.NewRegistry()
+	if *electionsFile != "" {
+		if err := electionRegistry.LoadFile(*electionsFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	lsn, err := net.Listen("tcp", ":50051")
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	limiter, err := newLimiter()
+	if err != nil {
+		log.Fatal(err)
+	}
+	authenticator, err := newAuthenticator()
+	if err != nil {
+		log.Fatal(err)
+	}
+	validator, err := validate.New(slices.Concat(electionsv1.ValidationRules, legacy.ValidationRules)...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	m := metrics.New()
+	interceptors := []grpc.UnaryServerInterceptor{
+		correlation.UnaryServerInterceptor(),
+		m.UnaryServerInterceptor(),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		correlation.StreamServerInterceptor(),
+		m.StreamServerInterceptor(),
+		m.StatsStreamInterceptor(v1.Elections_WatchStats_FullMethodName),
+		validate.StreamServerInterceptor(validator),
+	}
+	if authenticator != nil {
+		rules := auth.Merge(candidates.AuthRules, electionsv1.AuthRules, auth.Rules{
+			pb.Elections_SubmitVote_FullMethodName: {auth.RoleVoter},
+			// probes
+			healthpb.Health_Check_FullMethodName: nil,
+			healthpb.Health_Watch_FullMethodName: nil,
+		})
+		interceptors = append(interceptors, auth.UnaryServerInterceptor(authenticator, rules))
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor(authenticator, rules))
+	}
+	// both SubmitVote requests carry the vote in the vote field
+	if limiter != nil {
+		interceptors = append(interceptors,
+			ratelimit.UnaryServerInterceptor(limiter, ratelimit.MessageField("vote.passport")),
+			ratelimit.UnaryServerInterceptor(limiter, ratelimit.ForMethods(ratelimit.PeerIP,
+				v1.Elections_SubmitVote_FullMethodName, pb.Elections_SubmitVote_FullMethodName)),
+		)
+		streamInterceptors = append(streamInterceptors, ratelimit.StreamCallServerInterceptor(limiter,
+			ratelimit.ForMethods(ratelimit.PeerIP, v1.Elections_SubmitVotes_FullMethodName)))
+	}
+	interceptors = append(interceptors,
+		m.VoteInterceptor("vote.candidate_id", v1.Elections_SubmitVote_FullMethodName, pb.Elections_SubmitVote_FullMethodName),
+		validate.UnaryServerInterceptor(validator),
+	)
+
 	server := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			validate.UnaryServerRequestValidatorInterceptor(validate.ValidateReq),
-		),
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
 	)
-	pb.RegisterElectionsServer(server, new(Service))
+	voting := elections.NewVoting(registry, electionRegistry, elections.NewMemoryTally())
+	service := electionsv1.NewService(electionRegistry, voting)
+	service.Metrics = m
+	if limiter != nil {
+		// every vote of a SubmitVotes batch counts for its passport, like a
+		// SubmitVote call, the batch counts once for the client
+		service.VoteLimit = electionsv1.LimitVotes(limiter, ratelimit.MessageField("passport"))
+	}
+	v1.RegisterElectionsServer(server, service)
+	v1.RegisterElectionsAdminServer(server, electionsv1.NewAdminService(service))
+	pb.RegisterElectionsServer(server, legacy.NewElectionsService(service))
+	candidatespb.RegisterCandidatesServer(server, candidates.NewGRPCService(registry))
+
+	runner := newRunner(server, lsn, m)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	log.Printf("starting server on %s", lsn.Addr().String())
-	if err := server.Serve(lsn); err != nil {
+	if err := runner.Run(ctx); err != nil {
 		log.Fatal(err)
 	}
+	log.Printf("server stopped")
 }
@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const timestampName protoreflect.FullName = "google.protobuf.Timestamp"
+
+var (
+	intKinds = []protoreflect.Kind{
+		protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind,
+	}
+	stringKinds    = []protoreflect.Kind{protoreflect.StringKind}
+	timestampKinds = []protoreflect.Kind{protoreflect.MessageKind}
+)
+
+// Required rejects an unset field: a zero scalar, a nil message or an
+// empty list.
+func Required() Check {
+	return Check{required: true}
+}
+
+// Range accepts integers in [min, max].
+func Range(min, max int64) Check {
+	return Check{kinds: intKinds, check: func(v protoreflect.Value, _ time.Time) string {
+		var ok bool
+		switch n := v.Interface().(type) {
+		case int32:
+			ok = int64(n) >= min && int64(n) <= max
+		case int64:
+			ok = n >= min && n <= max
+		case uint32:
+			ok = max >= 0 && int64(n) >= min && uint64(n) <= uint64(max)
+		case uint64:
+			ok = max >= 0 && (min <= 0 || n >= uint64(min)) && n <= uint64(max)
+		}
+		if ok {
+			return ""
+		}
+		return fmt.Sprintf("must be between %d and %d", min, max)
+	}}
+}
+
+// Length accepts strings of min to max characters.
+func Length(min, max int) Check {
+	return Check{kinds: stringKinds, check: func(v protoreflect.Value, _ time.Time) string {
+		if n := utf8.RuneCountInString(v.String()); n < min || n > max {
+			return fmt.Sprintf("must be %d to %d characters long", min, max)
+		}
+		return ""
+	}}
+}
+
+// Pattern accepts strings matching the regular expression, it panics if
+// expr doesn't compile, like regexp.MustCompile.
+func Pattern(expr string) Check {
+	re := regexp.MustCompile(expr)
+	return Check{kinds: stringKinds, check: func(v protoreflect.Value, _ time.Time) string {
+		if !re.MatchString(v.String()) {
+			return fmt.Sprintf("must match %s", expr)
+		}
+		return ""
+	}}
+}
+
+// NotFuture accepts timestamps up to skew after the time of the check,
+// skew tolerates clocks of clients running ahead.
+func NotFuture(skew time.Duration) Check {
+	return Check{kinds: timestampKinds, check: func(v protoreflect.Value, now time.Time) string {
+		m := v.Message()
+		fields := m.Descriptor().Fields()
+		t := time.Unix(m.Get(fields.ByName("seconds")).Int(), m.Get(fields.ByName("nanos")).Int())
+		if t.After(now.Add(skew)) {
+			return "must not be in the future"
+		}
+		return ""
+	}}
+}
@@ -0,0 +1,45 @@
+package validate
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor rejects requests breaking the rules of v before
+// the handler runs.
+func UnaryServerInterceptor(v *Validator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if m, ok := req.(proto.Message); ok {
+			if err := v.Validate(m); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor validates every message the handler receives,
+// a message breaking the rules fails the stream.
+func StreamServerInterceptor(v *Validator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &recvWrapper{ServerStream: ss, validator: v})
+	}
+}
+
+type recvWrapper struct {
+	grpc.ServerStream
+	validator *Validator
+}
+
+// RecvMsg validates m once it is decoded.
+func (s *recvWrapper) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(proto.Message); ok {
+		return s.validator.Validate(msg)
+	}
+	return nil
+}
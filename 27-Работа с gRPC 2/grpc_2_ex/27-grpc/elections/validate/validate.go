@@ -1,38 +1,200 @@
+// Package validate checks gRPC request messages against rules declared per
+// message and field, instead of a type switch over the messages:
+//
+//	v, err := validate.New(
+//		validate.For(&pb.SubmitVoteRequest{},
+//			validate.Field("vote", validate.Required()),
+//			validate.Field("vote.passport", validate.Required(), validate.Length(1, 64)),
+//		),
+//	)
+//
+// The interceptors validate every request of the declared messages after
+// it is decoded and reject it with InvalidArgument and an
+// errdetails.BadRequest listing every violation.
 package validate
 
 import (
-	"context"
 	"errors"
-	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/pb"
-	"google.golang.org/grpc"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-type Validator func(req interface{}) error
+// ErrRule is returned by New for a rule that doesn't fit its field.
+var ErrRule = errors.New("invalid validation rule")
+
+// Check returns the description of the violation, empty if the value is
+// valid. It is called for set fields only, elements of a repeated field
+// are checked one by one.
+type Check struct {
+	kinds []protoreflect.Kind // empty for any kind
+	check func(v protoreflect.Value, now time.Time) string
+	// required is checked on unset fields, the other checks skip them.
+	required bool
+}
+
+// FieldRules are the checks of a field, see Field.
+type FieldRules struct {
+	path   string
+	checks []Check
+}
+
+// Field declares the checks of the field at the dotted path of proto field
+// names. Checks of a field inside an unset message are skipped, declare
+// the message Required to reject it.
+func Field(path string, checks ...Check) FieldRules {
+	return FieldRules{path: path, checks: checks}
+}
+
+// MessageRules are the field rules of a message, see For.
+type MessageRules struct {
+	desc   protoreflect.MessageDescriptor
+	fields []FieldRules
+}
+
+// For declares the rules of the message type of m.
+func For(m proto.Message, fields ...FieldRules) MessageRules {
+	return MessageRules{desc: m.ProtoReflect().Descriptor(), fields: fields}
+}
 
-func UnaryServerRequestValidatorInterceptor(validator Validator) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		if err := validator(req); err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "%s is rejected by validate middleware. Error: %v", info.FullMethod, err)
+// field is FieldRules resolved against the descriptor.
+type field struct {
+	path   string
+	fields []protoreflect.FieldDescriptor
+	checks []Check
+}
+
+// Validator holds the rules of the messages, messages without rules are
+// valid.
+type Validator struct {
+	messages map[protoreflect.FullName][]field
+	// Now is the time NotFuture compares with.
+	Now func() time.Time
+}
+
+// New resolves the rules, a path to a missing field or a check that
+// doesn't fit the kind of its field is an ErrRule.
+func New(messages ...MessageRules) (*Validator, error) {
+	v := &Validator{messages: make(map[protoreflect.FullName][]field), Now: time.Now}
+	for _, m := range messages {
+		for _, rules := range m.fields {
+			f, err := resolve(m.desc, rules)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s.%s: %w", ErrRule, m.desc.FullName(), rules.path, err)
+			}
+			v.messages[m.desc.FullName()] = append(v.messages[m.desc.FullName()], f)
 		}
+	}
+	return v, nil
+}
 
-		if info.FullMethod == "handler_name" {
-			// nothing
+func resolve(md protoreflect.MessageDescriptor, rules FieldRules) (field, error) {
+	f := field{path: rules.path, checks: rules.checks}
+	for i, name := range strings.Split(rules.path, ".") {
+		if i > 0 {
+			prev := f.fields[i-1]
+			if prev.Message() == nil || prev.IsList() || prev.IsMap() {
+				return field{}, fmt.Errorf("%s is not a message", prev.Name())
+			}
+			md = prev.Message()
+		}
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return field{}, fmt.Errorf("no field %s in %s", name, md.FullName())
 		}
+		f.fields = append(f.fields, fd)
+	}
+	last := f.fields[len(f.fields)-1]
+	for _, c := range rules.checks {
+		if len(c.kinds) > 0 && !hasKind(c.kinds, last) {
+			return field{}, fmt.Errorf("check doesn't apply to %s", last.Kind())
+		}
+	}
+	return f, nil
+}
 
-		return handler(ctx, req)
+func hasKind(kinds []protoreflect.Kind, fd protoreflect.FieldDescriptor) bool {
+	for _, k := range kinds {
+		if k == fd.Kind() {
+			if k == protoreflect.MessageKind {
+				return fd.Message().FullName() == timestampName
+			}
+			return true
+		}
 	}
+	return false
 }
 
-func ValidateReq(req interface{}) error {
-	switch r := req.(type) {
-	case *pb.SubmitVoteRequest:
-		//if r.Vote.Passport == ""  !! panic
+// Violations lists the violations of m in the order of its rules.
+func (v *Validator) Violations(m proto.Message) []*errdetails.BadRequest_FieldViolation {
+	rules, ok := v.messages[m.ProtoReflect().Descriptor().FullName()]
+	if !ok {
+		return nil
+	}
+	now := v.Now()
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, f := range rules {
+		violations = append(violations, f.violations(m.ProtoReflect(), now)...)
+	}
+	return violations
+}
 
-		if r.GetVote().GetPassport() == "" || r.GetVote().GetCandidateId() == 0 {
-			return errors.New("middleware validator: passport or candidate_id wrong")
+// Validate returns an InvalidArgument status error with an
+// errdetails.BadRequest if m breaks its rules.
+func (v *Validator) Validate(m proto.Message) error {
+	violations := v.Violations(m)
+	if len(violations) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(violations))
+	for i, fv := range violations {
+		descriptions[i] = fv.GetField() + " " + fv.GetDescription()
+	}
+	st := status.Newf(codes.InvalidArgument, "invalid %s: %s", m.ProtoReflect().Descriptor().Name(), strings.Join(descriptions, ", "))
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func (f field) violations(m protoreflect.Message, now time.Time) []*errdetails.BadRequest_FieldViolation {
+	for _, fd := range f.fields[:len(f.fields)-1] {
+		if !m.Has(fd) {
+			return nil
+		}
+		m = m.Get(fd).Message()
+	}
+	fd := f.fields[len(f.fields)-1]
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	add := func(path, description string) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: path, Description: description})
+	}
+	for _, c := range f.checks {
+		switch {
+		case c.required:
+			if !m.Has(fd) {
+				add(f.path, "is required")
+			}
+		case !m.Has(fd):
+		case fd.IsList():
+			list := m.Get(fd).List()
+			for i := 0; i < list.Len(); i++ {
+				if d := c.check(list.Get(i), now); d != "" {
+					add(fmt.Sprintf("%s[%d]", f.path, i), d)
+				}
+			}
+		default:
+			if d := c.check(m.Get(fd), now); d != "" {
+				add(f.path, d)
+			}
 		}
 	}
-	return nil
+	return violations
 }
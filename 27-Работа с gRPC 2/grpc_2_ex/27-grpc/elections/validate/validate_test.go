@@ -0,0 +1,173 @@
+package validate_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/validate"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/legacy"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var now = time.Date(2024, 3, 17, 12, 0, 0, 0, time.UTC)
+
+func newValidator(t *testing.T, rules ...validate.MessageRules) *validate.Validator {
+	t.Helper()
+	v, err := validate.New(rules...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Now = func() time.Time { return now }
+	return v
+}
+
+// violations returns the fields of the BadRequest of err.
+func violations(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, fv := range br.GetFieldViolations() {
+				fields = append(fields, fv.GetField())
+			}
+		}
+	}
+	if len(fields) == 0 {
+		t.Fatalf("expected a BadRequest, got %v", err)
+	}
+	return fields
+}
+
+func TestValidator(t *testing.T) {
+	v := newValidator(t, slices.Concat(electionsv1.ValidationRules, []validate.MessageRules{
+		validate.For(&pb.Election{}, validate.Field("candidates", validate.Range(1, 10))),
+	})...)
+	vote := func(edit func(*pb.Vote)) *pb.SubmitVoteRequest {
+		vote := &pb.Vote{ElectionId: 1, Passport: "a", CandidateId: 2, Time: timestamppb.New(now)}
+		edit(vote)
+		return &pb.SubmitVoteRequest{Vote: vote}
+	}
+
+	cases := []struct {
+		name   string
+		msg    proto.Message
+		fields []string
+	}{
+		{"valid", vote(func(*pb.Vote) {}), nil},
+		{"no_vote", &pb.SubmitVoteRequest{}, []string{"vote"}},
+		{"empty_vote", &pb.SubmitVoteRequest{Vote: &pb.Vote{}}, []string{"vote.election_id", "vote.passport", "vote.candidate_id"}},
+		{"passport_space", vote(func(v *pb.Vote) { v.Passport = "a b" }), []string{"vote.passport"}},
+		{"passport_long", vote(func(v *pb.Vote) { v.Passport = strings.Repeat("a", 65) }), []string{"vote.passport"}},
+		{"no_time", vote(func(v *pb.Vote) { v.Time = nil }), nil},
+		{"clock_skew", vote(func(v *pb.Vote) { v.Time = timestamppb.New(now.Add(time.Second)) }), nil},
+		{"future", vote(func(v *pb.Vote) { v.Time = timestamppb.New(now.Add(time.Hour)) }), []string{"vote.time"}},
+		{"stats", &pb.GetStatsRequest{}, []string{"election_id"}},
+		{"list", &pb.Election{Candidates: []uint32{1, 0, 11}}, []string{"candidates[1]", "candidates[2]"}},
+		{"no_rules", &pb.Stats{}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if fields := violations(t, v.Validate(c.msg)); !slices.Equal(fields, c.fields) {
+				t.Fatalf("expected violations of %v, got %v", c.fields, fields)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for name, rules := range map[string]validate.MessageRules{
+		"unknown_field":   validate.For(&pb.Vote{}, validate.Field("voter", validate.Required())),
+		"scalar_parent":   validate.For(&pb.Vote{}, validate.Field("passport.id", validate.Required())),
+		"range_of_string": validate.For(&pb.Vote{}, validate.Field("passport", validate.Range(1, 2))),
+		"time_of_stats":   validate.For(&pb.Stats{}, validate.Field("election_id", validate.NotFuture(0))),
+		"message_length":  validate.For(&pb.SubmitVoteRequest{}, validate.Field("vote", validate.Length(1, 2))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := validate.New(rules); !errors.Is(err, validate.ErrRule) {
+				t.Fatalf("expected ErrRule, got %v", err)
+			}
+		})
+	}
+
+	// the rules of the servers resolve
+	if _, err := validate.New(slices.Concat(electionsv1.ValidationRules, legacy.ValidationRules)...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// stream decodes its messages into the argument of RecvMsg.
+type stream struct {
+	grpc.ServerStream
+	msgs []proto.Message
+}
+
+func (s *stream) Context() context.Context { return context.Background() }
+
+func (s *stream) RecvMsg(m interface{}) error {
+	if len(s.msgs) == 0 {
+		return errors.New("EOF")
+	}
+	proto.Merge(m.(proto.Message), s.msgs[0])
+	s.msgs = s.msgs[1:]
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := validate.StreamServerInterceptor(newValidator(t, electionsv1.ValidationRules...))
+	ss := &stream{msgs: []proto.Message{
+		&pb.WatchStatsRequest{ElectionId: 1},
+		&pb.WatchStatsRequest{},
+	}}
+
+	var errs []error
+	err := interceptor(nil, ss, &grpc.StreamServerInfo{}, func(_ interface{}, ss grpc.ServerStream) error {
+		for i := 0; i < 2; i++ {
+			errs = append(errs, ss.RecvMsg(&pb.WatchStatsRequest{}))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the message is validated once decoded, not before
+	if errs[0] != nil {
+		t.Fatalf("expected the first request to be valid, got %v", errs[0])
+	}
+	if fields := violations(t, errs[1]); !slices.Equal(fields, []string{"election_id"}) {
+		t.Fatalf("expected an election_id violation, got %v", fields)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := validate.UnaryServerInterceptor(newValidator(t, electionsv1.ValidationRules...))
+	called := false
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+	_, err := interceptor(context.Background(), &pb.SubmitVoteRequest{}, &grpc.UnaryServerInfo{}, handler)
+	if fields := violations(t, err); !slices.Equal(fields, []string{"vote"}) || called {
+		t.Fatalf("expected the request to be rejected before the handler, got %v", fields)
+	}
+	if _, err := interceptor(context.Background(), &pb.GetStatsRequest{ElectionId: 1}, &grpc.UnaryServerInfo{}, handler); err != nil || !called {
+		t.Fatalf("expected the handler to be called, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package elections
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CandidateID identifies a candidate of candidates.Registry.
+type CandidateID uint32
+
+// Vote is a vote as every transport submits it.
+type Vote struct {
+	ElectionId  uint32
+	Passport    string
+	CandidateId CandidateID
+	Note        string
+	// Time is set by the voter, Voting uses the receive time if it is zero.
+	Time time.Time
+	// Replaces is the candidate whose earlier vote from the same passport
+	// is withdrawn by this one, it is set by Voting.
+	Replaces CandidateID
+}
+
+// Validate lists the missing fields of the vote in a ValidationError.
+func (v Vote) Validate() error {
+	var violations []Violation
+	if v.ElectionId == 0 {
+		violations = append(violations, Violation{Field: "election_id", Reason: "is required"})
+	}
+	if v.Passport == "" {
+		violations = append(violations, Violation{Field: "passport", Reason: "is required"})
+	}
+	if v.CandidateId == 0 {
+		violations = append(violations, Violation{Field: "candidate_id", Reason: "is required"})
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// Violation is a field of a vote that breaks a rule.
+type Violation struct {
+	Field  string
+	Reason string
+}
+
+// ValidationError lists the violations of a vote, it wraps ErrInvalidVote.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		fields[i] = v.Field + " " + v.Reason
+	}
+	return ErrInvalidVote.Error() + ": " + strings.Join(fields, ", ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidVote
+}
+
+// Stats are the votes of an election per candidate at Time. Records only
+// has the candidates that got votes, the ballot is in Election.
+type Stats struct {
+	Election Election
+	Status   Status
+	Records  map[CandidateID]uint32
+	Time     time.Time
+}
+
+// Final reports whether the election is closed and the stats can't change.
+func (s Stats) Final() bool {
+	return s.Status == StatusClosed
+}
+
+// Tally keeps accepted votes and counts them per election and candidate.
+// Implementations must be safe for concurrent use.
+type Tally interface {
+	// Add keeps either every vote or none of them.
+	Add(ctx context.Context, votes ...Vote) error
+	// Count returns a copy of the votes per candidate of the election.
+	Count(ctx context.Context, electionId uint32) (map[CandidateID]uint32, error)
+}
+
+// MemoryTally only counts votes, the counts are lost on exit.
+type MemoryTally struct {
+	lock  sync.RWMutex
+	stats map[uint32]map[CandidateID]uint32 // election id -> candidate id -> votes
+}
+
+func NewMemoryTally() *MemoryTally {
+	return &MemoryTally{stats: make(map[uint32]map[CandidateID]uint32)}
+}
+
+func (t *MemoryTally) Add(_ context.Context, votes ...Vote) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, v := range votes {
+		stats, ok := t.stats[v.ElectionId]
+		if !ok {
+			stats = make(map[CandidateID]uint32)
+			t.stats[v.ElectionId] = stats
+		}
+		stats[v.CandidateId]++
+		if v.Replaces != 0 {
+			stats[v.Replaces]--
+		}
+	}
+	return nil
+}
+
+func (t *MemoryTally) Count(_ context.Context, electionId uint32) (map[CandidateID]uint32, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	stats := make(map[CandidateID]uint32, len(t.stats[electionId]))
+	for k, v := range t.stats[electionId] {
+		stats[k] = v
+	}
+	return stats, nil
+}
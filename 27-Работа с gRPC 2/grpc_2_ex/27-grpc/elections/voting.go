@@ -0,0 +1,246 @@
+package elections
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/dedup"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/receipts"
+)
+
+// Voting accepts votes: it checks them against the candidates and the
+// elections, lets a passport vote once per election, records them in the
+// audit log and counts them in the tally. Fields are set before the first
+// vote.
+type Voting struct {
+	candidates *candidates.Registry
+	elections  *Registry
+	tally      Tally
+
+	// lock is held for reading while votes are applied, Stats of a closed
+	// election takes it for writing to wait for the votes that were
+	// admitted before the close.
+	lock sync.RWMutex
+
+	Now func() time.Time
+	// Audit records accepted votes as they are counted, nil turns it off.
+	// A vote the tally failed to take is taken back out of the log.
+	Audit *audit.Log
+	// Receipts signs the receipts of accepted votes, nil issues them
+	// unsigned. Without Audit and Receipts votes get no receipt.
+	Receipts *receipts.Keyring
+	// Notify is called after votes of the election are counted, nil turns
+	// it off.
+	Notify func(Election)
+}
+
+func NewVoting(candidateRegistry *candidates.Registry, electionRegistry *Registry, tally Tally) *Voting {
+	return &Voting{
+		candidates: candidateRegistry,
+		elections:  electionRegistry,
+		tally:      tally,
+		Now:        time.Now,
+	}
+}
+
+// Check reports whether the vote would be admitted now without counting
+// it, a passport that has already voted is only found by Submit.
+func (v *Voting) Check(vote Vote) error {
+	_, _, err := v.admit(vote)
+	return err
+}
+
+// admit checks the vote and returns its election and the guard of the
+// election passports.
+func (v *Voting) admit(vote Vote) (Election, *dedup.Guard, error) {
+	// the passport is checked as the guard and the audit log see it
+	vote.Passport = dedup.Normalize(vote.Passport)
+	now := v.Now()
+	if err := vote.Validate(); err != nil {
+		return Election{}, nil, err
+	}
+	if err := v.candidates.CheckVote(uint32(vote.CandidateId)); err != nil {
+		return Election{}, nil, fmt.Errorf("%w: %w", ErrCandidateNotAllowed, err)
+	}
+	return v.elections.Admit(vote.ElectionId, uint32(vote.CandidateId), now)
+}
+
+// Submit counts the vote and returns its receipt, nil if neither Audit nor
+// Receipts is set.
+func (v *Voting) Submit(ctx context.Context, vote Vote) (*receipts.Receipt, error) {
+	election, guard, err := v.admit(vote)
+	if err != nil {
+		return nil, err
+	}
+
+	var receipt *receipts.Receipt
+	err = guard.Submit(vote.Passport, uint32(vote.CandidateId), func(replaces uint32) error {
+		vote.Replaces = CandidateID(replaces)
+		issued, err := v.apply(ctx, election, []Vote{vote})
+		if issued != nil {
+			receipt = issued[0]
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.notify(election)
+	return receipt, nil
+}
+
+// SubmitAll counts either every vote or none of them, errs is nil if they
+// are counted. The votes must be for one election. If a vote is rejected
+// the others are rejected with ErrAborted.
+func (v *Voting) SubmitAll(ctx context.Context, votes []Vote) (issued []*receipts.Receipt, errs []error) {
+	if len(votes) == 0 {
+		return nil, nil
+	}
+	var election Election
+	var guard *dedup.Guard
+	rejected := false
+	errs = make([]error, len(votes))
+	ballots := make([]dedup.Ballot, len(votes))
+	for i, vote := range votes {
+		if vote.ElectionId != votes[0].ElectionId {
+			errs[i] = &ValidationError{Violations: []Violation{{Field: "election_id", Reason: "must be the same in an atomic batch"}}}
+		} else {
+			election, guard, errs[i] = v.admit(vote)
+		}
+		rejected = rejected || errs[i] != nil
+		ballots[i] = dedup.Ballot{Passport: vote.Passport, CandidateId: uint32(vote.CandidateId)}
+	}
+	if rejected {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = ErrAborted
+			}
+		}
+		return nil, errs
+	}
+
+	votes = append([]Vote(nil), votes...)
+	errs = guard.SubmitAll(ballots, func(replaces []uint32) error {
+		for i := range votes {
+			votes[i].Replaces = CandidateID(replaces[i])
+		}
+		var err error
+		issued, err = v.apply(ctx, election, votes)
+		return err
+	})
+	if errs != nil {
+		return nil, errs
+	}
+	v.notify(election)
+	return issued, nil
+}
+
+// apply records and counts admitted votes of the election as one step, it
+// is called with their passports locked. Nothing is kept if it fails.
+func (v *Voting) apply(ctx context.Context, election Election, votes []Vote) ([]*receipts.Receipt, error) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	// the election may have closed while waiting for the passport lock
+	now := v.Now()
+	if election.Status(now) == StatusClosed {
+		return nil, ErrFinished
+	}
+	for i := range votes {
+		if votes[i].Time.IsZero() {
+			votes[i].Time = now.UTC()
+		}
+	}
+
+	var issued []*receipts.Receipt
+	count := func(records []audit.Receipt) error {
+		var err error
+		if issued, err = v.issue(votes, records); err != nil {
+			return err
+		}
+		return v.tally.Add(ctx, votes...)
+	}
+	if v.Audit == nil {
+		if err := count(nil); err != nil {
+			return nil, err
+		}
+		return issued, nil
+	}
+
+	entries := make([]audit.Entry, len(votes))
+	for i, vote := range votes {
+		entries[i] = audit.Entry{
+			ElectionId:  vote.ElectionId,
+			Passport:    vote.Passport,
+			CandidateId: uint32(vote.CandidateId),
+			Replaces:    uint32(vote.Replaces),
+			Time:        vote.Time,
+		}
+	}
+	// the tally runs while the log holds the records, if it fails they are
+	// taken back out and a retry isn't logged as a second vote
+	if _, err := v.Audit.AppendFunc(count, entries...); err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// issue returns the receipts of the votes for their audit records, signed
+// if Receipts is set. The receipts are nil if both are off.
+func (v *Voting) issue(votes []Vote, records []audit.Receipt) ([]*receipts.Receipt, error) {
+	if v.Audit == nil && v.Receipts == nil {
+		return nil, nil
+	}
+	issued := make([]*receipts.Receipt, len(votes))
+	for i, vote := range votes {
+		var record *audit.Receipt
+		if records != nil {
+			record = &records[i]
+		}
+		receipt, err := receipts.New(vote.ElectionId, uint32(vote.CandidateId), vote.Time, record)
+		if err != nil {
+			return nil, err
+		}
+		if v.Receipts != nil {
+			if err := v.Receipts.Sign(receipt); err != nil {
+				return nil, err
+			}
+		}
+		issued[i] = receipt
+	}
+	return issued, nil
+}
+
+func (v *Voting) notify(election Election) {
+	if v.Notify != nil {
+		v.Notify(election)
+	}
+}
+
+// Stats returns the counts of the election. The stats of a closed election
+// are final: they are read once the votes admitted before the close are
+// counted.
+func (v *Voting) Stats(ctx context.Context, electionId uint32) (Stats, error) {
+	election, err := v.elections.Get(electionId)
+	if err != nil {
+		return Stats{}, err
+	}
+	// the status is taken before the counts, so stats marked final are
+	// read after the election closed
+	now := v.Now()
+	status := election.Status(now)
+	if status == StatusClosed {
+		v.lock.Lock()
+		//lint:ignore SA2001 waits for the votes being applied
+		v.lock.Unlock()
+	}
+	records, err := v.tally.Count(ctx, electionId)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{Election: election, Status: status, Records: records, Time: now}, nil
+}
@@ -0,0 +1,136 @@
+package elections_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/electionstest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// votingClient calls Voting directly, the transports must answer as it
+// does.
+type votingClient struct {
+	voting *elections.Voting
+}
+
+func (c votingClient) Submit(t *testing.T, vote elections.Vote) elections.Reason {
+	_, err := c.voting.Submit(context.Background(), vote)
+	return elections.ReasonOf(err)
+}
+
+func (c votingClient) SubmitAll(t *testing.T, votes []elections.Vote) []elections.Reason {
+	_, errs := c.voting.SubmitAll(context.Background(), votes)
+	reasons := make([]elections.Reason, len(votes))
+	for i := range errs {
+		reasons[i] = elections.ReasonOf(errs[i])
+	}
+	return reasons
+}
+
+func (c votingClient) Stats(t *testing.T, electionId uint32) (map[elections.CandidateID]uint32, bool, elections.Reason) {
+	stats, err := c.voting.Stats(context.Background(), electionId)
+	return stats.Records, stats.Final(), elections.ReasonOf(err)
+}
+
+func TestVoting(t *testing.T) {
+	electionstest.Run(t, func(t *testing.T, c *candidates.Registry, r *elections.Registry) electionstest.Client {
+		return votingClient{voting: elections.NewVoting(c, r, elections.NewMemoryTally())}
+	})
+}
+
+// failingTally fails the first Add.
+type failingTally struct {
+	elections.Tally
+	failed bool
+}
+
+func (t *failingTally) Add(ctx context.Context, votes ...elections.Vote) error {
+	if !t.failed {
+		t.failed = true
+		return errors.New("tally is down")
+	}
+	return t.Tally.Add(ctx, votes...)
+}
+
+func TestVoting_TallyFailure(t *testing.T) {
+	candidateRegistry := candidates.NewRegistry()
+	if _, err := candidateRegistry.Create(candidates.Candidate{Name: "Alice", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	electionRegistry := elections.NewRegistry()
+	now := time.Now()
+	if _, err := electionRegistry.Create(elections.Election{Id: 1, Title: "Mayor", Candidates: []uint32{1}, OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	voting := elections.NewVoting(candidateRegistry, electionRegistry, &failingTally{Tally: elections.NewMemoryTally()})
+	voting.Audit = audit.NewMemory([]byte("key"))
+
+	vote := elections.Vote{ElectionId: 1, Passport: "a", CandidateId: 1}
+	if _, err := voting.Submit(context.Background(), vote); err == nil {
+		t.Fatal("expected the tally error")
+	}
+	if _, ok := voting.Audit.Head(); ok {
+		t.Fatal("expected no audit record of the uncounted vote")
+	}
+
+	// the retry is the first vote of the passport, counted and logged once
+	receipt, err := voting.Submit(context.Background(), vote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head, ok := voting.Audit.Head(); !ok || head.Index != 0 || receipt.Audit == nil || receipt.Audit.Index != 0 {
+		t.Fatalf("expected a single audit record, got head %+v", head)
+	}
+	stats, err := voting.Stats(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Records[1] != 1 {
+		t.Fatalf("expected 1 vote, got %v", stats.Records)
+	}
+}
+
+func TestVote_Validate(t *testing.T) {
+	err := elections.Vote{ElectionId: 1}.Validate()
+	if !errors.Is(err, elections.ErrInvalidVote) {
+		t.Fatalf("expected ErrInvalidVote, got %v", err)
+	}
+	var verr *elections.ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 2 || verr.Violations[0].Field != "passport" || verr.Violations[1].Field != "candidate_id" {
+		t.Fatalf("expected passport and candidate_id violations, got %v", err)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	err := elections.StatusError(elections.ErrAlreadyVoted)
+	if reason := elections.ReasonFromStatus(err); reason != elections.ReasonAlreadyVoted {
+		t.Fatalf("expected %q, got %q", elections.ReasonAlreadyVoted, reason)
+	}
+	if elections.StatusError(err) != err {
+		t.Fatal("expected a status error to be returned as is")
+	}
+
+	// the violations of a vote are sent as a BadRequest
+	err = elections.StatusError(elections.Vote{ElectionId: 1, CandidateId: 1}.Validate())
+	var fields []string
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	if len(fields) != 1 || fields[0] != "passport" {
+		t.Fatalf("expected a passport violation, got %v", fields)
+	}
+	if reason := elections.ReasonFromStatus(err); reason != elections.ReasonValidation {
+		t.Fatalf("expected %q, got %q", elections.ReasonValidation, reason)
+	}
+}
@@ -0,0 +1,178 @@
+package electionsv1
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AdminService is the elections.v1 ElectionsAdmin service, it manages the
+// elections of the Service and votes through it.
+type AdminService struct {
+	pb.UnimplementedElectionsAdminServer
+
+	service *Service
+}
+
+func NewAdminService(service *Service) *AdminService {
+	return &AdminService{service: service}
+}
+
+func (a *AdminService) CreateElection(ctx context.Context, req *pb.Election) (*pb.Election, error) {
+	e, err := a.service.elections.Create(electionFromProto(req))
+	if err != nil {
+		return nil, elections.StatusError(err)
+	}
+	return a.electionProto(e), nil
+}
+
+func (a *AdminService) UpdateElection(ctx context.Context, req *pb.Election) (*pb.Election, error) {
+	e, err := a.service.elections.Update(electionFromProto(req))
+	if err != nil {
+		return nil, elections.StatusError(err)
+	}
+	// the watchers of the election follow its new close time
+	a.service.feeds.notify(e.Id)
+	return a.electionProto(e), nil
+}
+
+func (a *AdminService) GetElection(ctx context.Context, req *pb.ElectionId) (*pb.Election, error) {
+	e, err := a.service.elections.Get(req.GetId())
+	if err != nil {
+		return nil, elections.StatusError(err)
+	}
+	return a.electionProto(e), nil
+}
+
+func (a *AdminService) ListElections(ctx context.Context, _ *empty.Empty) (*pb.ElectionList, error) {
+	list := a.service.elections.List()
+	resp := &pb.ElectionList{Elections: make([]*pb.Election, 0, len(list))}
+	for _, e := range list {
+		resp.Elections = append(resp.Elections, a.electionProto(e))
+	}
+	return resp, nil
+}
+
+// Monitor submits the votes it receives and sends back every accepted vote
+// and the stats of all elections every interval. A rejected vote is logged
+// and skipped, the stream goes on.
+func (a *AdminService) Monitor(srv pb.ElectionsAdmin_MonitorServer) error {
+	log.Printf("new monitor listener")
+
+	// recvErr is set before inChan is closed, a vote rejected by the
+	// validation interceptor ends the stream with its status
+	var recvErr error
+	inChan := make(chan *pb.Vote)
+	go func() {
+		defer close(inChan)
+
+		for {
+			req, err := srv.Recv()
+			if err != nil {
+				log.Printf("unable to read message from monitor listener: %v", err)
+				recvErr = err
+				return
+			}
+
+			select {
+			case <-srv.Context().Done():
+			case inChan <- req:
+			}
+		}
+	}()
+
+	s := a.service
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-srv.Context().Done():
+			log.Printf("monitor listener disconnected")
+			return nil
+
+		case req, ok := <-inChan:
+			if !ok {
+				log.Printf("read loop for monitor listener stopped, disconnect it")
+				if st, isStatus := status.FromError(recvErr); isStatus && st.Code() == codes.InvalidArgument {
+					return recvErr
+				}
+				return nil
+			}
+
+			_, err := s.submit(srv.Context(), req)
+			if s.Metrics != nil {
+				s.Metrics.VoteStatus(req.GetCandidateId(), err)
+			}
+			if err != nil {
+				log.Printf("unable to submit vote, skip it, error: %v", err)
+				continue
+			}
+
+			msg := &pb.MonitorResponse{
+				Body: &pb.MonitorResponse_Vote{
+					Vote: req,
+				},
+			}
+			if err := srv.Send(msg); err != nil {
+				log.Printf("unable to send vote to monitor listener, disconnect it, error: %v", err)
+				return err
+			}
+
+		case <-ticker.C:
+			for _, election := range s.elections.List() {
+				stats, err := s.getStats(srv.Context(), election.Id)
+				if err != nil {
+					log.Printf("unable to get stats of election %d: %v", election.Id, err)
+					continue
+				}
+				msg := &pb.MonitorResponse{
+					Body: &pb.MonitorResponse_Stats{
+						Stats: stats,
+					},
+				}
+				if err := srv.Send(msg); err != nil {
+					log.Printf("unable to send stats to monitor listener, disconnect it, error: %v", err)
+					return err
+				}
+			}
+		}
+	}
+}
+
+func (a *AdminService) electionProto(e elections.Election) *pb.Election {
+	return &pb.Election{
+		Id:          e.Id,
+		Title:       e.Title,
+		Candidates:  e.Candidates,
+		OpensAt:     timestamppb.New(e.OpensAt),
+		ClosesAt:    timestamppb.New(e.ClosesAt),
+		AllowRevote: e.AllowRevote,
+		Status:      string(e.Status(a.service.voting.Now())),
+	}
+}
+
+// electionFromProto leaves a missing voting window zero, the registry
+// rejects it.
+func electionFromProto(e *pb.Election) elections.Election {
+	election := elections.Election{
+		Id:          e.GetId(),
+		Title:       e.GetTitle(),
+		Candidates:  e.GetCandidates(),
+		AllowRevote: e.GetAllowRevote(),
+	}
+	if e.GetOpensAt() != nil {
+		election.OpensAt = e.GetOpensAt().AsTime()
+	}
+	if e.GetClosesAt() != nil {
+		election.ClosesAt = e.GetClosesAt().AsTime()
+	}
+	return election
+}
@@ -0,0 +1,161 @@
+package electionsv1
+
+import (
+	"context"
+	"io"
+	"log"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/ratelimit"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxAtomicBatch bounds the votes an atomic batch keeps in memory until the
+// stream ends, a batch without atomic is applied vote by vote.
+const maxAtomicBatch = 10000
+
+// SubmitVotes applies a stream of votes and answers with the result of every
+// vote, a rejected vote doesn't stop the stream. With atomic set in the
+// first message the votes are collected and applied together once the
+// stream ends, or none of them is: a vote breaking VoteFields or over
+// VoteLimit aborts them.
+func (s *Service) SubmitVotes(srv pb.Elections_SubmitVotesServer) error {
+	var atomic bool
+	var batch []*pb.Vote
+	var rejected map[int]error // of the atomic batch, by index
+	resp := &pb.SubmitVotesResponse{}
+	for index := 0; ; index++ {
+		req, err := srv.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if index == 0 {
+			atomic = req.GetAtomic()
+		}
+
+		vote := req.GetVote()
+		if vote == nil {
+			vote = &pb.Vote{}
+		}
+		reject := checkVote(vote)
+		if reject == nil && s.VoteLimit != nil {
+			reject = s.VoteLimit(srv.Context(), vote)
+		}
+		if atomic {
+			if len(batch) == maxAtomicBatch {
+				return status.Errorf(codes.ResourceExhausted, "atomic batch is limited to %d votes", maxAtomicBatch)
+			}
+			if reject != nil {
+				if rejected == nil {
+					rejected = make(map[int]error)
+				}
+				rejected[len(batch)] = reject
+			}
+			batch = append(batch, vote)
+			continue
+		}
+		if reject != nil {
+			s.result(resp, index, vote, nil, reject)
+			continue
+		}
+		receipt, err := s.submit(srv.Context(), vote)
+		s.result(resp, index, vote, receipt, err)
+	}
+
+	if atomic {
+		var receipts []*pb.VoteReceipt
+		var errs []error
+		if rejected != nil {
+			errs = make([]error, len(batch))
+			for i := range errs {
+				if errs[i] = rejected[i]; errs[i] == nil {
+					errs[i] = elections.StatusError(elections.ErrAborted)
+				}
+			}
+		} else {
+			receipts, errs = s.submitAll(srv.Context(), batch)
+		}
+		for i, vote := range batch {
+			var err error
+			var receipt *pb.VoteReceipt
+			if errs != nil {
+				err = errs[i]
+			} else if receipts != nil {
+				receipt = receipts[i]
+			}
+			s.result(resp, i, vote, receipt, err)
+		}
+	}
+	log.Printf("vote batch done (atomic=%t, accepted=%d, rejected=%d)", atomic, resp.Accepted, resp.Rejected)
+	return srv.SendAndClose(resp)
+}
+
+// LimitVotes returns a Service.VoteLimit taking a request from the quota
+// of every key for each vote, keys of the vote like its passport.
+func LimitVotes(limiter ratelimit.Limiter, keys ...ratelimit.KeyFunc) func(context.Context, *pb.Vote) error {
+	return func(ctx context.Context, vote *pb.Vote) error {
+		return ratelimit.Check(ctx, limiter, vote, keys...)
+	}
+}
+
+// submitAll applies all votes or none, errs is nil if they are applied.
+// The receipts are nil if neither the audit log nor signing is on.
+func (s *Service) submitAll(ctx context.Context, votes []*pb.Vote) (receipts []*pb.VoteReceipt, errs []error) {
+	batch := make([]elections.Vote, len(votes))
+	for i, vote := range votes {
+		batch[i] = voteFromProto(vote)
+	}
+	issued, errs := s.voting.SubmitAll(ctx, batch)
+	if errs != nil {
+		for i := range errs {
+			errs[i] = elections.StatusError(errs[i])
+		}
+		return nil, errs
+	}
+	if issued == nil {
+		return nil, nil
+	}
+	receipts = make([]*pb.VoteReceipt, len(issued))
+	for i, r := range issued {
+		receipts[i] = voteReceiptProto(r)
+	}
+	return receipts, nil
+}
+
+// result adds the result of the vote to resp and counts it in Metrics.
+func (s *Service) result(resp *pb.SubmitVotesResponse, index int, vote *pb.Vote, receipt *pb.VoteReceipt, err error) {
+	st := status.Convert(err)
+	if err == nil {
+		resp.Accepted++
+	} else {
+		resp.Rejected++
+	}
+	resp.Results = append(resp.Results, &pb.SubmitVotesResponse_Result{
+		Index:   uint32(index),
+		Code:    int32(st.Code()),
+		Message: st.Message(),
+		Receipt: receipt,
+		Reason:  errorReason(st),
+	})
+	if s.Metrics != nil {
+		s.Metrics.VoteStatus(vote.CandidateId, err)
+	}
+}
+
+// errorReason returns the ErrorInfo reason of the status, empty if it has
+// none.
+func errorReason(st *status.Status) string {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,217 @@
+package electionsv1
+
+import (
+	"context"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/electionstest"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcClient votes over elections.v1 served on bufconn.
+type grpcClient struct {
+	client pb.ElectionsClient
+}
+
+func dial(t *testing.T, candidateRegistry *candidates.Registry, electionRegistry *elections.Registry) electionstest.Client {
+	voting := elections.NewVoting(candidateRegistry, electionRegistry, elections.NewMemoryTally())
+	server := grpc.NewServer()
+	pb.RegisterElectionsServer(server, NewService(electionRegistry, voting))
+
+	lsn := bufconn.Listen(1 << 20)
+	go server.Serve(lsn)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lsn.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpcClient{client: pb.NewElectionsClient(conn)}
+}
+
+func voteProto(v elections.Vote) *pb.Vote {
+	return &pb.Vote{ElectionId: v.ElectionId, Passport: v.Passport, CandidateId: uint32(v.CandidateId), Note: v.Note}
+}
+
+// reason fails t if the status has no reason, only elections errors are
+// expected.
+func reason(t *testing.T, err error) elections.Reason {
+	t.Helper()
+	reason := elections.ReasonFromStatus(err)
+	if err != nil && reason == elections.ReasonNone {
+		t.Fatalf("status without reason: %v", err)
+	}
+	return reason
+}
+
+func (c grpcClient) Submit(t *testing.T, vote elections.Vote) elections.Reason {
+	_, err := c.client.SubmitVote(context.Background(), &pb.SubmitVoteRequest{Vote: voteProto(vote)})
+	return reason(t, err)
+}
+
+func (c grpcClient) SubmitAll(t *testing.T, votes []elections.Vote) []elections.Reason {
+	stream, err := c.client.SubmitVotes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, vote := range votes {
+		if err := stream.Send(&pb.SubmitVotesRequest{Vote: voteProto(vote), Atomic: true}); err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := make([]elections.Reason, len(resp.GetResults()))
+	for i, res := range resp.GetResults() {
+		reasons[i] = elections.Reason(strings.ToLower(res.GetReason()))
+	}
+	return reasons
+}
+
+func (c grpcClient) Stats(t *testing.T, electionId uint32) (map[elections.CandidateID]uint32, bool, elections.Reason) {
+	stats, err := c.client.GetStats(context.Background(), &pb.GetStatsRequest{ElectionId: electionId})
+	if err != nil {
+		return nil, false, reason(t, err)
+	}
+	records := make(map[elections.CandidateID]uint32, len(stats.GetRecords()))
+	for k, v := range stats.GetRecords() {
+		records[elections.CandidateID(k)] = v
+	}
+	return records, stats.GetFinal(), elections.ReasonNone
+}
+
+func TestService(t *testing.T) {
+	electionstest.Run(t, dial)
+}
+
+// votesStream is a SubmitVotes stream served in process.
+type votesStream struct {
+	grpc.ServerStream
+	reqs []*pb.SubmitVotesRequest
+	resp *pb.SubmitVotesResponse
+}
+
+func (s *votesStream) Context() context.Context { return context.Background() }
+
+func (s *votesStream) Recv() (*pb.SubmitVotesRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func (s *votesStream) SendAndClose(resp *pb.SubmitVotesResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestSubmitVotes_VoteLimit(t *testing.T) {
+	limiter, err := ratelimit.NewMemory(ratelimit.TokenBucket, ratelimit.PerMinute(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newWatchService(t, time.Hour)
+	s.VoteLimit = LimitVotes(limiter, ratelimit.MessageField("passport"))
+	submit := func(atomic bool, passports ...string) []codes.Code {
+		stream := &votesStream{}
+		for _, passport := range passports {
+			stream.reqs = append(stream.reqs, &pb.SubmitVotesRequest{
+				Vote:   &pb.Vote{ElectionId: 1, Passport: passport, CandidateId: 1},
+				Atomic: atomic,
+			})
+		}
+		if err := s.SubmitVotes(stream); err != nil {
+			t.Fatal(err)
+		}
+		var results []codes.Code
+		for _, res := range stream.resp.GetResults() {
+			results = append(results, codes.Code(res.GetCode()))
+			if res.GetCode() == int32(codes.ResourceExhausted) && res.GetReason() != "RATE_LIMITED" {
+				t.Fatalf("expected a RATE_LIMITED reason, got %q", res.GetReason())
+			}
+		}
+		return results
+	}
+
+	// the vote over the limit is rejected, the rest of the batch goes on
+	results := submit(false, "a", "a", "b")
+	if want := []codes.Code{codes.OK, codes.ResourceExhausted, codes.OK}; !slices.Equal(results, want) {
+		t.Fatalf("expected %v, got %v", want, results)
+	}
+	// in an atomic batch it aborts the other votes
+	results = submit(true, "c", "b")
+	if want := []codes.Code{codes.Aborted, codes.ResourceExhausted}; !slices.Equal(results, want) {
+		t.Fatalf("expected %v, got %v", want, results)
+	}
+	stats, err := s.GetStats(context.Background(), &pb.GetStatsRequest{ElectionId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := stats.GetRecords()[1]; count != 2 {
+		t.Fatalf("expected the 2 votes under the limit, got %d", count)
+	}
+}
+
+func TestSubmitVotes_Validation(t *testing.T) {
+	s := newWatchService(t, time.Hour)
+	submit := func(atomic bool, votes ...*pb.Vote) []codes.Code {
+		stream := &votesStream{}
+		for _, vote := range votes {
+			stream.reqs = append(stream.reqs, &pb.SubmitVotesRequest{Vote: vote, Atomic: atomic})
+		}
+		if err := s.SubmitVotes(stream); err != nil {
+			t.Fatal(err)
+		}
+		var results []codes.Code
+		for _, res := range stream.resp.GetResults() {
+			results = append(results, codes.Code(res.GetCode()))
+			if res.GetCode() == int32(codes.InvalidArgument) && elections.Reason(strings.ToLower(res.GetReason())) != elections.ReasonValidation {
+				t.Fatalf("expected a %s reason, got %q", elections.ReasonValidation, res.GetReason())
+			}
+		}
+		return results
+	}
+	vote := func(passport, note string) *pb.Vote {
+		return &pb.Vote{ElectionId: 1, Passport: passport, CandidateId: 1, Note: note}
+	}
+
+	// the votes of a batch follow the rules of SubmitVote one by one
+	results := submit(false, vote("a", ""), vote("b c", ""), vote("d", strings.Repeat("n", 1025)), vote("e", ""))
+	if want := []codes.Code{codes.OK, codes.InvalidArgument, codes.InvalidArgument, codes.OK}; !slices.Equal(results, want) {
+		t.Fatalf("expected %v, got %v", want, results)
+	}
+	// in an atomic batch an invalid vote aborts the others
+	results = submit(true, vote("f", ""), vote("g h", ""))
+	if want := []codes.Code{codes.Aborted, codes.InvalidArgument}; !slices.Equal(results, want) {
+		t.Fatalf("expected %v, got %v", want, results)
+	}
+	stats, err := s.GetStats(context.Background(), &pb.GetStatsRequest{ElectionId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := stats.GetRecords()[1]; count != 2 {
+		t.Fatalf("expected the 2 valid votes, got %d", count)
+	}
+}
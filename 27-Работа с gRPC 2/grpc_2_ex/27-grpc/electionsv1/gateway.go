@@ -0,0 +1,20 @@
+package electionsv1
+
+import (
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/gateway"
+)
+
+// GatewayRules serve the Elections service over HTTP/JSON, see gateway.
+//
+//	curl -X POST localhost:8080/v1/votes -d '{"election_id": 1, "passport": "a", "candidate_id": 1}'
+//	curl localhost:8080/v1/stats?election_id=1
+//	curl -N -H 'Accept: text/event-stream' localhost:8080/v1/stats:watch?election_id=1
+var GatewayRules = []gateway.Rule{
+	{Pattern: "POST /v1/votes", Method: pb.Elections_SubmitVote_FullMethodName, Body: "vote"},
+	{Pattern: "GET /v1/stats", Method: pb.Elections_GetStats_FullMethodName},
+	{Pattern: "GET /v1/stats:watch", Method: pb.Elections_WatchStats_FullMethodName},
+	{Pattern: "GET /v1/audit/head", Method: pb.Elections_GetAuditHead_FullMethodName},
+	{Pattern: "GET /v1/audit/proof", Method: pb.Elections_ProveVote_FullMethodName},
+	{Pattern: "GET /v1/receipts/keys", Method: pb.Elections_GetReceiptKeys_FullMethodName},
+}
@@ -0,0 +1,62 @@
+package legacy
+
+import (
+	"context"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-admin/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1"
+	v1 "github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+
+	"google.golang.org/grpc"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// AdminService is the elections_with_admin.Elections service, Internal is
+// Monitor of elections.v1.
+type AdminService struct {
+	pb.UnimplementedElectionsServer
+
+	service *electionsv1.Service
+	admin   *electionsv1.AdminService
+}
+
+func NewAdminService(service *electionsv1.Service) *AdminService {
+	return &AdminService{service: service, admin: electionsv1.NewAdminService(service)}
+}
+
+func (s *AdminService) SubmitVote(ctx context.Context, req *pb.Vote) (*empty.Empty, error) {
+	vote, err := convert[v1.Vote](req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.service.SubmitVote(ctx, &v1.SubmitVoteRequest{Vote: vote}); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *AdminService) Internal(srv pb.Elections_InternalServer) error {
+	return s.admin.Monitor(&internalStream{ServerStream: srv, srv: srv})
+}
+
+// internalStream is the Monitor stream over an Internal one.
+type internalStream struct {
+	grpc.ServerStream
+	srv pb.Elections_InternalServer
+}
+
+func (s *internalStream) Recv() (*v1.Vote, error) {
+	req, err := s.srv.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return convert[v1.Vote](req)
+}
+
+func (s *internalStream) Send(m *v1.MonitorResponse) error {
+	msg, err := convert[pb.StatsVote](m)
+	if err != nil {
+		return err
+	}
+	return s.srv.Send(msg)
+}
@@ -0,0 +1,33 @@
+package legacy
+
+import (
+	"context"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1"
+	v1 "github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+)
+
+// ElectionsService is the elections.Elections service.
+type ElectionsService struct {
+	pb.UnimplementedElectionsServer
+
+	service *electionsv1.Service
+}
+
+func NewElectionsService(service *electionsv1.Service) *ElectionsService {
+	return &ElectionsService{service: service}
+}
+
+// SubmitVote answers with the id of the candidate voted for, the receipt
+// is dropped.
+func (s *ElectionsService) SubmitVote(ctx context.Context, req *pb.SubmitVoteRequest) (*pb.SubmitVoteResponse, error) {
+	vote, err := convert[v1.SubmitVoteRequest](req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.service.SubmitVote(ctx, vote); err != nil {
+		return nil, err
+	}
+	return &pb.SubmitVoteResponse{Ids: []int32{int32(req.GetVote().GetCandidateId())}}, nil
+}
@@ -0,0 +1,30 @@
+// Package legacy serves the elections, elections_with_stat and
+// elections_with_admin APIs over electionsv1 until their clients move to
+// elections.v1. The messages of those APIs share the field numbers of
+// elections.v1, requests and responses are converted through the wire
+// format.
+package legacy
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// convert returns src decoded as the message T, fields unknown to T are
+// kept as unknown fields.
+func convert[T any, P interface {
+	*T
+	proto.Message
+}](src proto.Message) (P, error) {
+	dst := P(new(T))
+	data, err := proto.Marshal(src)
+	if err == nil {
+		err = proto.Unmarshal(data, dst)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot convert %s to %s: %v",
+			src.ProtoReflect().Descriptor().FullName(), dst.ProtoReflect().Descriptor().FullName(), err)
+	}
+	return dst, nil
+}
@@ -0,0 +1,172 @@
+package legacy
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	adminpb "github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-admin/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-stats/pb"
+	electionspb "github.com/OtusGolang/webinars_practical_part/27-grpc/elections/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1"
+	v1 "github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// dial serves elections.v1 and the legacy services of one electionsv1
+// service.
+func dial(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	registry := candidates.NewRegistry()
+	for _, name := range []string{"Alice", "Bob"} {
+		if _, err := registry.Create(candidates.Candidate{Name: name, Active: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	electionRegistry := elections.NewRegistry()
+	_, err := electionRegistry.Create(elections.Election{
+		Id:         1,
+		Title:      "Mayor",
+		Candidates: []uint32{1, 2},
+		OpensAt:    time.Now().Add(-time.Hour),
+		ClosesAt:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	voting := elections.NewVoting(registry, electionRegistry, elections.NewMemoryTally())
+	voting.Audit = audit.NewMemory([]byte("test-key"))
+	service := electionsv1.NewService(electionRegistry, voting)
+	server := grpc.NewServer()
+	v1.RegisterElectionsServer(server, service)
+	pb.RegisterElectionsServer(server, NewStatsService(service))
+	electionspb.RegisterElectionsServer(server, NewElectionsService(service))
+	adminpb.RegisterElectionsServer(server, NewAdminService(service))
+
+	lsn := bufconn.Listen(1 << 20)
+	go server.Serve(lsn)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lsn.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestAdapters_ShareService(t *testing.T) {
+	conn := dial(t)
+	ctx := context.Background()
+	stats := pb.NewElectionsClient(conn)
+	basic := electionspb.NewElectionsClient(conn)
+
+	receipt, err := stats.SubmitVote(ctx, &pb.Vote{ElectionId: 1, CandidateId: 1, Passport: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.GetElectionId() != 1 || receipt.GetCandidateId() != 1 || receipt.GetNonce() == "" || receipt.GetAudit() == nil {
+		t.Fatalf("unexpected receipt %v", receipt)
+	}
+
+	// the passport has voted through another API of the same service
+	_, err = basic.SubmitVote(ctx, &electionspb.SubmitVoteRequest{
+		Vote: &electionspb.SubmitVoteRequest_Vote{ElectionId: 1, CandidateId: 2, Passport: "a"},
+	})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("expected AlreadyExists, got %v", err)
+	}
+	resp, err := basic.SubmitVote(ctx, &electionspb.SubmitVoteRequest{
+		Vote: &electionspb.SubmitVoteRequest_Vote{ElectionId: 1, CandidateId: 2, Passport: "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetIds()) != 1 || resp.GetIds()[0] != 2 {
+		t.Fatalf("unexpected ids %v", resp.GetIds())
+	}
+
+	head, err := v1.NewElectionsClient(conn).GetAuditHead(ctx, &empty.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.GetIndex() != 1 {
+		t.Fatalf("expected 2 audit records, got head %v", head)
+	}
+	proof, err := stats.ProveVote(ctx, receipt.GetAudit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.GetRecord().GetCandidateId() != 1 || len(proof.GetDigests()) != 1 || proof.GetHead().GetHash() != head.GetHash() {
+		t.Fatalf("unexpected proof %v", proof)
+	}
+}
+
+func TestStatsService_SubmitVotes(t *testing.T) {
+	conn := dial(t)
+	stream, err := pb.NewElectionsClient(conn).SubmitVotes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, vote := range []*pb.Vote{
+		{ElectionId: 1, CandidateId: 1, Passport: "a"},
+		{ElectionId: 1, CandidateId: 3, Passport: "b"},
+		{ElectionId: 1, CandidateId: 2, Passport: "c"},
+	} {
+		if err := stream.Send(&pb.SubmitVotesRequest{Vote: vote, Atomic: i == 0}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetAccepted() != 0 || resp.GetRejected() != 3 || len(resp.GetResults()) != 3 {
+		t.Fatalf("expected the atomic batch to be rejected, got %v", resp)
+	}
+	if code := codes.Code(resp.GetResults()[1].GetCode()); code != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for the unknown candidate, got %v", code)
+	}
+	if code := codes.Code(resp.GetResults()[0].GetCode()); code != codes.Aborted {
+		t.Fatalf("expected Aborted for the valid vote, got %v", code)
+	}
+}
+
+func TestAdminService_Internal(t *testing.T) {
+	conn := dial(t)
+	stream, err := adminpb.NewElectionsClient(conn).Internal(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&adminpb.Vote{ElectionId: 1, CandidateId: 3, Passport: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&adminpb.Vote{ElectionId: 1, CandidateId: 2, Passport: "a", Note: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rejected vote is skipped, the accepted one comes back
+	msg, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vote := msg.GetVote(); vote.GetCandidateId() != 2 || vote.GetNote() != "hi" {
+		t.Fatalf("expected the accepted vote, got %v", msg)
+	}
+	stream.CloseSend()
+}
@@ -0,0 +1,112 @@
+package legacy
+
+import (
+	"context"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-stats/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1"
+	v1 "github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+
+	"google.golang.org/grpc"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// StatsService is the elections_with_stat.Elections service, GetStats is
+// WatchStats of elections.v1.
+type StatsService struct {
+	pb.UnimplementedElectionsServer
+
+	service *electionsv1.Service
+}
+
+func NewStatsService(service *electionsv1.Service) *StatsService {
+	return &StatsService{service: service}
+}
+
+func (s *StatsService) SubmitVote(ctx context.Context, req *pb.Vote) (*pb.VoteReceipt, error) {
+	vote, err := convert[v1.Vote](req)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := s.service.SubmitVote(ctx, &v1.SubmitVoteRequest{Vote: vote})
+	if err != nil {
+		return nil, err
+	}
+	return convert[pb.VoteReceipt](receipt)
+}
+
+func (s *StatsService) GetStats(req *pb.StatsRequest, srv pb.Elections_GetStatsServer) error {
+	watch, err := convert[v1.WatchStatsRequest](req)
+	if err != nil {
+		return err
+	}
+	return s.service.WatchStats(watch, &statsStream{ServerStream: srv, srv: srv})
+}
+
+func (s *StatsService) SubmitVotes(srv pb.Elections_SubmitVotesServer) error {
+	return s.service.SubmitVotes(&batchStream{ServerStream: srv, srv: srv})
+}
+
+func (s *StatsService) GetAuditHead(ctx context.Context, req *empty.Empty) (*pb.Receipt, error) {
+	head, err := s.service.GetAuditHead(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return convert[pb.Receipt](head)
+}
+
+func (s *StatsService) ProveVote(ctx context.Context, req *pb.Receipt) (*pb.AuditProof, error) {
+	receipt, err := convert[v1.Receipt](req)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := s.service.ProveVote(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+	return convert[pb.AuditProof](proof)
+}
+
+func (s *StatsService) GetReceiptKeys(ctx context.Context, req *empty.Empty) (*pb.ReceiptKeys, error) {
+	keys, err := s.service.GetReceiptKeys(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return convert[pb.ReceiptKeys](keys)
+}
+
+// statsStream is the WatchStats stream over a GetStats one.
+type statsStream struct {
+	grpc.ServerStream
+	srv pb.Elections_GetStatsServer
+}
+
+func (s *statsStream) Send(m *v1.Stats) error {
+	msg, err := convert[pb.Stats](m)
+	if err != nil {
+		return err
+	}
+	return s.srv.Send(msg)
+}
+
+// batchStream is the elections.v1 SubmitVotes stream over the legacy one.
+type batchStream struct {
+	grpc.ServerStream
+	srv pb.Elections_SubmitVotesServer
+}
+
+func (s *batchStream) Recv() (*v1.SubmitVotesRequest, error) {
+	req, err := s.srv.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return convert[v1.SubmitVotesRequest](req)
+}
+
+func (s *batchStream) SendAndClose(m *v1.SubmitVotesResponse) error {
+	msg, err := convert[pb.SubmitVotesResponse](m)
+	if err != nil {
+		return err
+	}
+	return s.srv.SendAndClose(msg)
+}
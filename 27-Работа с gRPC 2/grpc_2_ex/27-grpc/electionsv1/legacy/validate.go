@@ -0,0 +1,21 @@
+package legacy
+
+import (
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-admin/pb"
+	statpb "github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-stats/pb"
+	electionspb "github.com/OtusGolang/webinars_practical_part/27-grpc/elections/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/validate"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1"
+)
+
+// ValidationRules are electionsv1.ValidationRules for the legacy APIs. The
+// votes sent to Internal are checked too, the stream fails on the first
+// invalid one.
+var ValidationRules = []validate.MessageRules{
+	validate.For(&electionspb.SubmitVoteRequest{}, append([]validate.FieldRules{
+		validate.Field("vote", validate.Required()),
+	}, electionsv1.VoteFields("vote.")...)...),
+	validate.For(&pb.Vote{}, electionsv1.VoteFields("")...),
+	validate.For(&statpb.Vote{}, electionsv1.VoteFields("")...),
+	validate.For(&statpb.StatsRequest{}, validate.Field("election_id", validate.Required())),
+}
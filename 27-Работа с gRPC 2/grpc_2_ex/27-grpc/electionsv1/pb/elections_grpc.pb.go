@@ -0,0 +1,639 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.3
+// source: api/elections/v1/elections.proto
+
+// elections.v1 replaces the elections, elections_with_stat and
+// elections_with_admin packages. Vote and Stats keep the field numbers they
+// have there, so the adapters of 27-grpc/electionsv1/legacy convert the old
+// messages through the wire format.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Elections_SubmitVote_FullMethodName     = "/elections.v1.Elections/SubmitVote"
+	Elections_SubmitVotes_FullMethodName    = "/elections.v1.Elections/SubmitVotes"
+	Elections_WatchStats_FullMethodName     = "/elections.v1.Elections/WatchStats"
+	Elections_GetStats_FullMethodName       = "/elections.v1.Elections/GetStats"
+	Elections_GetAuditHead_FullMethodName   = "/elections.v1.Elections/GetAuditHead"
+	Elections_ProveVote_FullMethodName      = "/elections.v1.Elections/ProveVote"
+	Elections_GetReceiptKeys_FullMethodName = "/elections.v1.Elections/GetReceiptKeys"
+)
+
+// ElectionsClient is the client API for Elections service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ElectionsClient interface {
+	// SubmitVote answers with the receipt of the vote, signed if the server
+	// has receipt keys, empty if neither they nor the audit log are on.
+	SubmitVote(ctx context.Context, in *SubmitVoteRequest, opts ...grpc.CallOption) (*VoteReceipt, error)
+	// SubmitVotes takes a batch of votes, like the upload of a polling
+	// station, and answers with the result of every vote once the stream ends.
+	SubmitVotes(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SubmitVotesRequest, SubmitVotesResponse], error)
+	// WatchStats streams the stats of the election until it is closed, the
+	// last message is final. What is sent and how often is chosen per
+	// subscriber, see WatchStatsRequest.
+	WatchStats(ctx context.Context, in *WatchStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Stats], error)
+	// GetStats returns the current stats of the election, final if it is
+	// closed.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error)
+	// GetAuditHead returns the receipt of the last audit record, observers
+	// publish it so proofs can be checked against it.
+	GetAuditHead(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Receipt, error)
+	// ProveVote returns the proof that the record of the receipt is in the
+	// audit log, see 27-grpc/audit.
+	ProveVote(ctx context.Context, in *Receipt, opts ...grpc.CallOption) (*AuditProof, error)
+	// GetReceiptKeys returns the public keys receipts are signed with,
+	// including retired ones, see 27-grpc/receipts.
+	GetReceiptKeys(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReceiptKeys, error)
+}
+
+type electionsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewElectionsClient(cc grpc.ClientConnInterface) ElectionsClient {
+	return &electionsClient{cc}
+}
+
+func (c *electionsClient) SubmitVote(ctx context.Context, in *SubmitVoteRequest, opts ...grpc.CallOption) (*VoteReceipt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VoteReceipt)
+	err := c.cc.Invoke(ctx, Elections_SubmitVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *electionsClient) SubmitVotes(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SubmitVotesRequest, SubmitVotesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Elections_ServiceDesc.Streams[0], Elections_SubmitVotes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubmitVotesRequest, SubmitVotesResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_SubmitVotesClient = grpc.ClientStreamingClient[SubmitVotesRequest, SubmitVotesResponse]
+
+func (c *electionsClient) WatchStats(ctx context.Context, in *WatchStatsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Stats], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Elections_ServiceDesc.Streams[1], Elections_WatchStats_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchStatsRequest, Stats]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_WatchStatsClient = grpc.ServerStreamingClient[Stats]
+
+func (c *electionsClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, Elections_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *electionsClient) GetAuditHead(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Receipt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Receipt)
+	err := c.cc.Invoke(ctx, Elections_GetAuditHead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *electionsClient) ProveVote(ctx context.Context, in *Receipt, opts ...grpc.CallOption) (*AuditProof, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditProof)
+	err := c.cc.Invoke(ctx, Elections_ProveVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *electionsClient) GetReceiptKeys(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReceiptKeys, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReceiptKeys)
+	err := c.cc.Invoke(ctx, Elections_GetReceiptKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ElectionsServer is the server API for Elections service.
+// All implementations must embed UnimplementedElectionsServer
+// for forward compatibility.
+type ElectionsServer interface {
+	// SubmitVote answers with the receipt of the vote, signed if the server
+	// has receipt keys, empty if neither they nor the audit log are on.
+	SubmitVote(context.Context, *SubmitVoteRequest) (*VoteReceipt, error)
+	// SubmitVotes takes a batch of votes, like the upload of a polling
+	// station, and answers with the result of every vote once the stream ends.
+	SubmitVotes(grpc.ClientStreamingServer[SubmitVotesRequest, SubmitVotesResponse]) error
+	// WatchStats streams the stats of the election until it is closed, the
+	// last message is final. What is sent and how often is chosen per
+	// subscriber, see WatchStatsRequest.
+	WatchStats(*WatchStatsRequest, grpc.ServerStreamingServer[Stats]) error
+	// GetStats returns the current stats of the election, final if it is
+	// closed.
+	GetStats(context.Context, *GetStatsRequest) (*Stats, error)
+	// GetAuditHead returns the receipt of the last audit record, observers
+	// publish it so proofs can be checked against it.
+	GetAuditHead(context.Context, *emptypb.Empty) (*Receipt, error)
+	// ProveVote returns the proof that the record of the receipt is in the
+	// audit log, see 27-grpc/audit.
+	ProveVote(context.Context, *Receipt) (*AuditProof, error)
+	// GetReceiptKeys returns the public keys receipts are signed with,
+	// including retired ones, see 27-grpc/receipts.
+	GetReceiptKeys(context.Context, *emptypb.Empty) (*ReceiptKeys, error)
+	mustEmbedUnimplementedElectionsServer()
+}
+
+// UnimplementedElectionsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedElectionsServer struct{}
+
+func (UnimplementedElectionsServer) SubmitVote(context.Context, *SubmitVoteRequest) (*VoteReceipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitVote not implemented")
+}
+func (UnimplementedElectionsServer) SubmitVotes(grpc.ClientStreamingServer[SubmitVotesRequest, SubmitVotesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method SubmitVotes not implemented")
+}
+func (UnimplementedElectionsServer) WatchStats(*WatchStatsRequest, grpc.ServerStreamingServer[Stats]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStats not implemented")
+}
+func (UnimplementedElectionsServer) GetStats(context.Context, *GetStatsRequest) (*Stats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedElectionsServer) GetAuditHead(context.Context, *emptypb.Empty) (*Receipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditHead not implemented")
+}
+func (UnimplementedElectionsServer) ProveVote(context.Context, *Receipt) (*AuditProof, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProveVote not implemented")
+}
+func (UnimplementedElectionsServer) GetReceiptKeys(context.Context, *emptypb.Empty) (*ReceiptKeys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReceiptKeys not implemented")
+}
+func (UnimplementedElectionsServer) mustEmbedUnimplementedElectionsServer() {}
+func (UnimplementedElectionsServer) testEmbeddedByValue()                   {}
+
+// UnsafeElectionsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ElectionsServer will
+// result in compilation errors.
+type UnsafeElectionsServer interface {
+	mustEmbedUnimplementedElectionsServer()
+}
+
+func RegisterElectionsServer(s grpc.ServiceRegistrar, srv ElectionsServer) {
+	// If the following call pancis, it indicates UnimplementedElectionsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Elections_ServiceDesc, srv)
+}
+
+func _Elections_SubmitVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsServer).SubmitVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Elections_SubmitVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsServer).SubmitVote(ctx, req.(*SubmitVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Elections_SubmitVotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ElectionsServer).SubmitVotes(&grpc.GenericServerStream[SubmitVotesRequest, SubmitVotesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_SubmitVotesServer = grpc.ClientStreamingServer[SubmitVotesRequest, SubmitVotesResponse]
+
+func _Elections_WatchStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ElectionsServer).WatchStats(m, &grpc.GenericServerStream[WatchStatsRequest, Stats]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Elections_WatchStatsServer = grpc.ServerStreamingServer[Stats]
+
+func _Elections_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Elections_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Elections_GetAuditHead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsServer).GetAuditHead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Elections_GetAuditHead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsServer).GetAuditHead(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Elections_ProveVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Receipt)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsServer).ProveVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Elections_ProveVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsServer).ProveVote(ctx, req.(*Receipt))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Elections_GetReceiptKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsServer).GetReceiptKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Elections_GetReceiptKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsServer).GetReceiptKeys(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Elections_ServiceDesc is the grpc.ServiceDesc for Elections service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Elections_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "elections.v1.Elections",
+	HandlerType: (*ElectionsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitVote",
+			Handler:    _Elections_SubmitVote_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _Elections_GetStats_Handler,
+		},
+		{
+			MethodName: "GetAuditHead",
+			Handler:    _Elections_GetAuditHead_Handler,
+		},
+		{
+			MethodName: "ProveVote",
+			Handler:    _Elections_ProveVote_Handler,
+		},
+		{
+			MethodName: "GetReceiptKeys",
+			Handler:    _Elections_GetReceiptKeys_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubmitVotes",
+			Handler:       _Elections_SubmitVotes_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchStats",
+			Handler:       _Elections_WatchStats_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/elections/v1/elections.proto",
+}
+
+const (
+	ElectionsAdmin_CreateElection_FullMethodName = "/elections.v1.ElectionsAdmin/CreateElection"
+	ElectionsAdmin_UpdateElection_FullMethodName = "/elections.v1.ElectionsAdmin/UpdateElection"
+	ElectionsAdmin_GetElection_FullMethodName    = "/elections.v1.ElectionsAdmin/GetElection"
+	ElectionsAdmin_ListElections_FullMethodName  = "/elections.v1.ElectionsAdmin/ListElections"
+	ElectionsAdmin_Monitor_FullMethodName        = "/elections.v1.ElectionsAdmin/Monitor"
+)
+
+// ElectionsAdminClient is the client API for ElectionsAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ElectionsAdminClient interface {
+	CreateElection(ctx context.Context, in *Election, opts ...grpc.CallOption) (*Election, error)
+	// UpdateElection replaces a scheduled election, an opened one can't be
+	// changed.
+	UpdateElection(ctx context.Context, in *Election, opts ...grpc.CallOption) (*Election, error)
+	GetElection(ctx context.Context, in *ElectionId, opts ...grpc.CallOption) (*Election, error)
+	ListElections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ElectionList, error)
+	// Monitor submits the votes of the stream like SubmitVote, echoes every
+	// accepted one and sends the stats of all elections in between.
+	Monitor(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Vote, MonitorResponse], error)
+}
+
+type electionsAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewElectionsAdminClient(cc grpc.ClientConnInterface) ElectionsAdminClient {
+	return &electionsAdminClient{cc}
+}
+
+func (c *electionsAdminClient) CreateElection(ctx context.Context, in *Election, opts ...grpc.CallOption) (*Election, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Election)
+	err := c.cc.Invoke(ctx, ElectionsAdmin_CreateElection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *electionsAdminClient) UpdateElection(ctx context.Context, in *Election, opts ...grpc.CallOption) (*Election, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Election)
+	err := c.cc.Invoke(ctx, ElectionsAdmin_UpdateElection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *electionsAdminClient) GetElection(ctx context.Context, in *ElectionId, opts ...grpc.CallOption) (*Election, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Election)
+	err := c.cc.Invoke(ctx, ElectionsAdmin_GetElection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *electionsAdminClient) ListElections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ElectionList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ElectionList)
+	err := c.cc.Invoke(ctx, ElectionsAdmin_ListElections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *electionsAdminClient) Monitor(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Vote, MonitorResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ElectionsAdmin_ServiceDesc.Streams[0], ElectionsAdmin_Monitor_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Vote, MonitorResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ElectionsAdmin_MonitorClient = grpc.BidiStreamingClient[Vote, MonitorResponse]
+
+// ElectionsAdminServer is the server API for ElectionsAdmin service.
+// All implementations must embed UnimplementedElectionsAdminServer
+// for forward compatibility.
+type ElectionsAdminServer interface {
+	CreateElection(context.Context, *Election) (*Election, error)
+	// UpdateElection replaces a scheduled election, an opened one can't be
+	// changed.
+	UpdateElection(context.Context, *Election) (*Election, error)
+	GetElection(context.Context, *ElectionId) (*Election, error)
+	ListElections(context.Context, *emptypb.Empty) (*ElectionList, error)
+	// Monitor submits the votes of the stream like SubmitVote, echoes every
+	// accepted one and sends the stats of all elections in between.
+	Monitor(grpc.BidiStreamingServer[Vote, MonitorResponse]) error
+	mustEmbedUnimplementedElectionsAdminServer()
+}
+
+// UnimplementedElectionsAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedElectionsAdminServer struct{}
+
+func (UnimplementedElectionsAdminServer) CreateElection(context.Context, *Election) (*Election, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateElection not implemented")
+}
+func (UnimplementedElectionsAdminServer) UpdateElection(context.Context, *Election) (*Election, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateElection not implemented")
+}
+func (UnimplementedElectionsAdminServer) GetElection(context.Context, *ElectionId) (*Election, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetElection not implemented")
+}
+func (UnimplementedElectionsAdminServer) ListElections(context.Context, *emptypb.Empty) (*ElectionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListElections not implemented")
+}
+func (UnimplementedElectionsAdminServer) Monitor(grpc.BidiStreamingServer[Vote, MonitorResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Monitor not implemented")
+}
+func (UnimplementedElectionsAdminServer) mustEmbedUnimplementedElectionsAdminServer() {}
+func (UnimplementedElectionsAdminServer) testEmbeddedByValue()                        {}
+
+// UnsafeElectionsAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ElectionsAdminServer will
+// result in compilation errors.
+type UnsafeElectionsAdminServer interface {
+	mustEmbedUnimplementedElectionsAdminServer()
+}
+
+func RegisterElectionsAdminServer(s grpc.ServiceRegistrar, srv ElectionsAdminServer) {
+	// If the following call pancis, it indicates UnimplementedElectionsAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ElectionsAdmin_ServiceDesc, srv)
+}
+
+func _ElectionsAdmin_CreateElection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Election)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsAdminServer).CreateElection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ElectionsAdmin_CreateElection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsAdminServer).CreateElection(ctx, req.(*Election))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ElectionsAdmin_UpdateElection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Election)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsAdminServer).UpdateElection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ElectionsAdmin_UpdateElection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsAdminServer).UpdateElection(ctx, req.(*Election))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ElectionsAdmin_GetElection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ElectionId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsAdminServer).GetElection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ElectionsAdmin_GetElection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsAdminServer).GetElection(ctx, req.(*ElectionId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ElectionsAdmin_ListElections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElectionsAdminServer).ListElections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ElectionsAdmin_ListElections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElectionsAdminServer).ListElections(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ElectionsAdmin_Monitor_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ElectionsAdminServer).Monitor(&grpc.GenericServerStream[Vote, MonitorResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ElectionsAdmin_MonitorServer = grpc.BidiStreamingServer[Vote, MonitorResponse]
+
+// ElectionsAdmin_ServiceDesc is the grpc.ServiceDesc for ElectionsAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ElectionsAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "elections.v1.ElectionsAdmin",
+	HandlerType: (*ElectionsAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateElection",
+			Handler:    _ElectionsAdmin_CreateElection_Handler,
+		},
+		{
+			MethodName: "UpdateElection",
+			Handler:    _ElectionsAdmin_UpdateElection_Handler,
+		},
+		{
+			MethodName: "GetElection",
+			Handler:    _ElectionsAdmin_GetElection_Handler,
+		},
+		{
+			MethodName: "ListElections",
+			Handler:    _ElectionsAdmin_ListElections_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Monitor",
+			Handler:       _ElectionsAdmin_Monitor_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/elections/v1/elections.proto",
+}
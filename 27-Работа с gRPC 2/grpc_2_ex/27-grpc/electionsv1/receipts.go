@@ -0,0 +1,67 @@
+package electionsv1
+
+import (
+	"context"
+	"log"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Service) GetAuditHead(ctx context.Context, _ *empty.Empty) (*pb.Receipt, error) {
+	if s.voting.Audit == nil {
+		return nil, status.Error(codes.NotFound, "audit log is off")
+	}
+	head, ok := s.voting.Audit.Head()
+	if !ok {
+		return nil, status.Error(codes.NotFound, "audit log is empty")
+	}
+	return receiptProto(head), nil
+}
+
+func (s *Service) ProveVote(ctx context.Context, req *pb.Receipt) (*pb.AuditProof, error) {
+	if s.voting.Audit == nil {
+		return nil, status.Error(codes.NotFound, "audit log is off")
+	}
+	proof, err := s.voting.Audit.Prove(audit.Receipt{Index: req.GetIndex(), Hash: req.GetHash()})
+	if err != nil {
+		log.Printf("unable to prove vote %d: %v", req.GetIndex(), err)
+		return nil, audit.StatusError(err)
+	}
+
+	rec := proof.Record
+	return &pb.AuditProof{
+		Record: &pb.AuditRecord{
+			Index:       rec.Index,
+			ElectionId:  rec.ElectionId,
+			CandidateId: rec.CandidateId,
+			Replaces:    rec.Replaces,
+			Voter:       rec.Voter,
+			Time:        timestamppb.New(rec.Time),
+			Prev:        rec.Prev,
+			Hash:        rec.Hash,
+		},
+		Digests: proof.Digests,
+		Head:    receiptProto(proof.Head),
+	}, nil
+}
+
+func (s *Service) GetReceiptKeys(ctx context.Context, _ *empty.Empty) (*pb.ReceiptKeys, error) {
+	if s.voting.Receipts == nil {
+		return nil, status.Error(codes.NotFound, "receipts are not signed")
+	}
+	resp := &pb.ReceiptKeys{}
+	for _, key := range s.voting.Receipts.PublicKeys() {
+		resp.Keys = append(resp.Keys, &pb.ReceiptKeys_Key{Id: key.Id, PublicKey: key.PublicKey, Signing: key.Signing})
+	}
+	return resp, nil
+}
+
+func receiptProto(r audit.Receipt) *pb.Receipt {
+	return &pb.Receipt{Index: r.Index, Hash: r.Hash}
+}
@@ -0,0 +1,176 @@
+// Package electionsv1 implements the elections.v1 gRPC API: votes, batches
+// and stats for voters and observers, elections for admins. The older
+// elections APIs are adapters over it, see electionsv1/legacy. The rules
+// are applied by elections.Voting, the service only converts messages and
+// errors.
+package electionsv1
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/auth"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/metrics"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/receipts"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultInterval    = 2 * time.Second
+	defaultMinInterval = 100 * time.Millisecond
+)
+
+// AuthRules lets voters vote, observers watch the stats and admins manage
+// elections. Receipt keys are public, receipts are checked offline.
+var AuthRules = auth.Rules{
+	pb.Elections_SubmitVote_FullMethodName:  {auth.RoleVoter},
+	pb.Elections_SubmitVotes_FullMethodName: {auth.RoleVoter},
+	pb.Elections_WatchStats_FullMethodName:  {auth.RoleObserver},
+	pb.Elections_GetStats_FullMethodName:    {auth.RoleObserver},
+	// voters check their receipts, observers the whole log
+	pb.Elections_GetAuditHead_FullMethodName:   {auth.RoleVoter, auth.RoleObserver},
+	pb.Elections_ProveVote_FullMethodName:      {auth.RoleVoter, auth.RoleObserver},
+	pb.Elections_GetReceiptKeys_FullMethodName: nil,
+
+	pb.ElectionsAdmin_CreateElection_FullMethodName: {auth.RoleAdmin},
+	pb.ElectionsAdmin_UpdateElection_FullMethodName: {auth.RoleAdmin},
+	pb.ElectionsAdmin_GetElection_FullMethodName:    {auth.RoleAdmin},
+	pb.ElectionsAdmin_ListElections_FullMethodName:  {auth.RoleAdmin},
+	pb.ElectionsAdmin_Monitor_FullMethodName:        {auth.RoleAdmin},
+}
+
+// Service is the elections.v1 Elections service, AdminService shares its
+// voting.
+type Service struct {
+	pb.UnimplementedElectionsServer
+
+	elections *elections.Registry
+	voting    *elections.Voting
+	feeds     *feeds
+
+	// Interval is how often WatchStats sends the stats to subscribers that
+	// don't set min_interval and Monitor the stats of all elections.
+	Interval time.Duration
+	// MinInterval is the shortest min_interval of WatchStats, shorter ones
+	// are raised to it so a subscriber can't make the stats sent on every
+	// vote. 100ms by default.
+	MinInterval time.Duration
+
+	// Metrics counts the votes sent over SubmitVotes and Monitor, nil turns
+	// it off. Unary votes are counted by metrics.VoteInterceptor.
+	Metrics *metrics.Metrics
+	// VoteLimit limits every vote of SubmitVotes like the passport
+	// interceptor limits SubmitVote, see ratelimit.Check. The quota of the
+	// client is left to ratelimit.StreamCallServerInterceptor, taken once
+	// per batch. A vote over the limit gets a RESOURCE_EXHAUSTED result and
+	// the batch goes on, nil turns it off.
+	VoteLimit func(ctx context.Context, vote *pb.Vote) error
+}
+
+// NewService serves the votes of voting, the registry must be the one of
+// voting. The WatchStats subscribers are notified through voting.Notify,
+// a Notify set before is still called.
+func NewService(electionRegistry *elections.Registry, voting *elections.Voting) *Service {
+	s := &Service{
+		elections:   electionRegistry,
+		voting:      voting,
+		Interval:    defaultInterval,
+		MinInterval: defaultMinInterval,
+	}
+	s.feeds = &feeds{stats: s.getStats, until: s.untilClose, feeds: make(map[uint32]*feed)}
+	notify := voting.Notify
+	voting.Notify = func(e elections.Election) {
+		if notify != nil {
+			notify(e)
+		}
+		s.feeds.notify(e.Id)
+	}
+	return s
+}
+
+func (s *Service) SubmitVote(ctx context.Context, req *pb.SubmitVoteRequest) (*pb.VoteReceipt, error) {
+	return s.submit(ctx, req.GetVote())
+}
+
+// submit applies a single vote, a nil vote is rejected as empty.
+func (s *Service) submit(ctx context.Context, req *pb.Vote) (*pb.VoteReceipt, error) {
+	log.Printf("new vote receive (passport=%s, election_id=%d, candidate_id=%d, time=%v)",
+		req.GetPassport(), req.GetElectionId(), req.GetCandidateId(), req.GetTime().AsTime())
+
+	receipt, err := s.voting.Submit(ctx, voteFromProto(req))
+	if err != nil {
+		log.Printf("%v, skip vote", err)
+		return nil, elections.StatusError(err)
+	}
+
+	log.Printf("vote accepted")
+	return voteReceiptProto(receipt), nil
+}
+
+func (s *Service) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.Stats, error) {
+	return s.getStats(ctx, req.GetElectionId())
+}
+
+// untilClose returns how long until the election closes, zero if it is
+// closed or unknown.
+func (s *Service) untilClose(electionId uint32) time.Duration {
+	election, err := s.elections.Get(electionId)
+	if err != nil {
+		return 0
+	}
+	return max(election.ClosesAt.Sub(s.voting.Now()), 0)
+}
+
+func (s *Service) getStats(ctx context.Context, electionId uint32) (*pb.Stats, error) {
+	stats, err := s.voting.Stats(ctx, electionId)
+	if err != nil {
+		return nil, elections.StatusError(err)
+	}
+	records := make(map[uint32]uint32, len(stats.Records))
+	for k, v := range stats.Records {
+		records[uint32(k)] = v
+	}
+	return &pb.Stats{
+		Records:    records,
+		Time:       timestamppb.New(stats.Time),
+		ElectionId: electionId,
+		Final:      stats.Final(),
+	}, nil
+}
+
+func voteFromProto(v *pb.Vote) elections.Vote {
+	vote := elections.Vote{
+		ElectionId:  v.GetElectionId(),
+		Passport:    v.GetPassport(),
+		CandidateId: elections.CandidateID(v.GetCandidateId()),
+		Note:        v.GetNote(),
+	}
+	if v.GetTime() != nil {
+		vote.Time = v.GetTime().AsTime()
+	}
+	return vote
+}
+
+// voteReceiptProto returns an empty receipt for nil, the vote was accepted
+// without one.
+func voteReceiptProto(r *receipts.Receipt) *pb.VoteReceipt {
+	if r == nil {
+		return &pb.VoteReceipt{}
+	}
+	receipt := &pb.VoteReceipt{
+		ElectionId:  r.ElectionId,
+		CandidateId: r.CandidateId,
+		Time:        timestamppb.New(r.Time),
+		Nonce:       r.Nonce,
+		KeyId:       r.KeyId,
+		Signature:   r.Signature,
+	}
+	if r.Audit != nil {
+		receipt.Audit = receiptProto(*r.Audit)
+	}
+	return receipt
+}
@@ -0,0 +1,61 @@
+package electionsv1
+
+import (
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/validate"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+)
+
+// clockSkew is how far ahead of the server the clock of a voter may run.
+const clockSkew = time.Minute
+
+// VoteFields are the rules of a Vote at prefix, "" for a Vote request and
+// "vote." for a request carrying it in the vote field. The legacy APIs
+// share them, their votes have the same fields.
+func VoteFields(prefix string) []validate.FieldRules {
+	return []validate.FieldRules{
+		validate.Field(prefix+"election_id", validate.Required()),
+		validate.Field(prefix+"passport", validate.Required(), validate.Length(1, 64), validate.Pattern(`^\S+$`)),
+		validate.Field(prefix+"candidate_id", validate.Required()),
+		validate.Field(prefix+"note", validate.Length(0, 1024)),
+		validate.Field(prefix+"time", validate.NotFuture(clockSkew)),
+	}
+}
+
+// ValidationRules reject malformed requests before they reach the
+// service. SubmitVotes has no rules, a stream breaking them would fail as
+// a whole: its votes are checked one by one with checkVote and an invalid
+// vote fails its own result.
+var ValidationRules = []validate.MessageRules{
+	validate.For(&pb.SubmitVoteRequest{}, append([]validate.FieldRules{
+		validate.Field("vote", validate.Required()),
+	}, VoteFields("vote.")...)...),
+	validate.For(&pb.GetStatsRequest{}, validate.Field("election_id", validate.Required())),
+	validate.For(&pb.WatchStatsRequest{}, validate.Field("election_id", validate.Required())),
+	validate.For(&pb.ElectionId{}, validate.Field("id", validate.Required())),
+}
+
+// votes checks the votes of SubmitVotes with the rules of SubmitVote.
+var votes = func() *validate.Validator {
+	v, err := validate.New(validate.For(&pb.Vote{}, VoteFields("")...))
+	if err != nil {
+		panic(err)
+	}
+	return v
+}()
+
+// checkVote returns the violations of VoteFields by the vote as the status
+// of an elections.ValidationError, nil if there are none.
+func checkVote(vote *pb.Vote) error {
+	violations := votes.Violations(vote)
+	if len(violations) == 0 {
+		return nil
+	}
+	verr := &elections.ValidationError{}
+	for _, fv := range violations {
+		verr.Violations = append(verr.Violations, elections.Violation{Field: fv.GetField(), Reason: fv.GetDescription()})
+	}
+	return elections.StatusError(verr)
+}
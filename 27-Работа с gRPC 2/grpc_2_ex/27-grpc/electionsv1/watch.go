@@ -0,0 +1,295 @@
+package electionsv1
+
+import (
+	"context"
+	"log"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+)
+
+// feeds fan the stats of elections out to their WatchStats subscribers.
+// The stats of an election are read once per change for all of its
+// subscribers, not once per subscriber.
+type feeds struct {
+	stats func(ctx context.Context, electionId uint32) (*pb.Stats, error)
+	// until returns how long until the election closes, zero if it is
+	// closed or unknown.
+	until func(electionId uint32) time.Duration
+
+	lock  sync.Mutex
+	feeds map[uint32]*feed
+}
+
+// feed runs while its election has subscribers.
+type feed struct {
+	id     uint32
+	notify chan struct{}
+	stop   chan struct{}
+
+	lock sync.Mutex
+	subs map[*subscription]struct{}
+	last *pb.Stats
+}
+
+// subscription receives the latest stats of its feed, a subscriber that is
+// behind only misses the stats in between.
+type subscription struct {
+	C <-chan *pb.Stats
+
+	c     chan *pb.Stats
+	feed  *feed
+	feeds *feeds
+}
+
+// notify tells the feed of the election that its stats changed. It never
+// blocks and is a no-op without subscribers.
+func (f *feeds) notify(electionId uint32) {
+	f.lock.Lock()
+	feed := f.feeds[electionId]
+	f.lock.Unlock()
+	if feed == nil {
+		return
+	}
+	select {
+	case feed.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (f *feeds) subscribe(electionId uint32) *subscription {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	fd := f.feeds[electionId]
+	if fd == nil {
+		fd = &feed{
+			id:     electionId,
+			notify: make(chan struct{}, 1),
+			stop:   make(chan struct{}),
+			subs:   make(map[*subscription]struct{}),
+		}
+		f.feeds[electionId] = fd
+		go f.loop(fd)
+	}
+	c := make(chan *pb.Stats, 1)
+	sub := &subscription{C: c, c: c, feed: fd, feeds: f}
+	fd.lock.Lock()
+	fd.subs[sub] = struct{}{}
+	fd.lock.Unlock()
+	return sub
+}
+
+// Close unsubscribes, the feed stops with its last subscriber.
+func (s *subscription) Close() {
+	s.feeds.lock.Lock()
+	defer s.feeds.lock.Unlock()
+
+	fd := s.feed
+	fd.lock.Lock()
+	delete(fd.subs, s)
+	empty := len(fd.subs) == 0
+	fd.lock.Unlock()
+	if empty && s.feeds.feeds[fd.id] == fd {
+		delete(s.feeds.feeds, fd.id)
+		close(fd.stop)
+	}
+}
+
+// current returns the last stats of the feed, read now if there are none.
+func (s *subscription) current(ctx context.Context) (*pb.Stats, error) {
+	s.feed.lock.Lock()
+	last := s.feed.last
+	s.feed.lock.Unlock()
+	if last != nil {
+		return last, nil
+	}
+	return s.feeds.stats(ctx, s.feed.id)
+}
+
+// loop reads the stats on every change and once the election closes, so
+// the final stats are sent even if nobody votes.
+func (f *feeds) loop(fd *feed) {
+	closes := time.NewTimer(f.until(fd.id))
+	defer closes.Stop()
+	for {
+		select {
+		case <-fd.stop:
+			return
+		case <-fd.notify:
+		case <-closes.C:
+		}
+
+		stats, err := f.stats(context.Background(), fd.id)
+		if err != nil {
+			log.Printf("unable to get stats of election %d: %v", fd.id, err)
+			continue
+		}
+		fd.broadcast(stats)
+
+		// the close time may have changed with the election
+		if !stats.Final {
+			if !closes.Stop() {
+				select {
+				case <-closes.C:
+				default:
+				}
+			}
+			closes.Reset(f.until(fd.id))
+		}
+	}
+}
+
+func (fd *feed) broadcast(stats *pb.Stats) {
+	fd.lock.Lock()
+	defer fd.lock.Unlock()
+
+	fd.last = stats
+	for sub := range fd.subs {
+		// replace the stats the subscriber hasn't read yet, only the loop
+		// sends so the second send can't block
+		select {
+		case <-sub.c:
+		default:
+		}
+		sub.c <- stats
+	}
+}
+
+// statsFilter is what a subscriber asked for in its WatchStatsRequest.
+type statsFilter struct {
+	candidates  map[uint32]bool // nil for all
+	interval    time.Duration
+	changesOnly bool
+}
+
+// newStatsFilter takes min_interval if it is set, raised to floor, and
+// interval otherwise.
+func newStatsFilter(req *pb.WatchStatsRequest, interval, floor time.Duration) statsFilter {
+	filter := statsFilter{interval: interval, changesOnly: req.GetChangesOnly()}
+	if req.GetMinInterval() != nil {
+		filter.interval = max(req.GetMinInterval().AsDuration(), floor)
+	}
+	if ids := req.GetCandidateIds(); len(ids) > 0 {
+		filter.candidates = make(map[uint32]bool, len(ids))
+		for _, id := range ids {
+			filter.candidates[id] = true
+		}
+	}
+	return filter
+}
+
+// apply returns the stats with the records of the candidates of the filter,
+// stats are shared between subscribers and are not modified.
+func (f statsFilter) apply(stats *pb.Stats) *pb.Stats {
+	if f.candidates == nil {
+		return stats
+	}
+	records := make(map[uint32]uint32, len(f.candidates))
+	for id, count := range stats.GetRecords() {
+		if f.candidates[id] {
+			records[id] = count
+		}
+	}
+	return &pb.Stats{Records: records, Time: stats.GetTime(), ElectionId: stats.GetElectionId(), Final: stats.GetFinal()}
+}
+
+// WatchStats streams the stats of the election as the request asks: on
+// change or every interval, for some candidates or all, at most once per
+// min_interval. Final stats are sent right away and end the stream.
+func (s *Service) WatchStats(req *pb.WatchStatsRequest, srv pb.Elections_WatchStatsServer) error {
+	election, err := s.elections.Get(req.GetElectionId())
+	if err != nil {
+		return elections.StatusError(err)
+	}
+	filter := newStatsFilter(req, s.Interval, s.MinInterval)
+	if !filter.changesOnly && filter.interval <= 0 {
+		filter.interval = s.Interval
+	}
+	log.Printf("new stats listener (election_id=%d)", election.Id)
+
+	sub := s.feeds.subscribe(election.Id)
+	defer sub.Close()
+
+	latest, err := sub.current(srv.Context())
+	if err != nil {
+		return elections.StatusError(err)
+	}
+	// records are the ones the subscriber has, changes are compared to them
+	records := filter.apply(latest).GetRecords()
+	var sentAt time.Time
+	send := func(stats *pb.Stats) error {
+		msg := filter.apply(stats)
+		if err := srv.Send(msg); err != nil {
+			log.Printf("unable to send message to stats listener: %v", err)
+			return err
+		}
+		records, sentAt = msg.GetRecords(), time.Now()
+		return nil
+	}
+	changed := func() bool {
+		return !maps.Equal(filter.apply(latest).GetRecords(), records)
+	}
+	if req.GetInitialSnapshot() || latest.Final {
+		if err := send(latest); err != nil || latest.Final {
+			return err
+		}
+	}
+
+	// tick sends the latest stats every interval, due sends the changes
+	// held back by min_interval
+	var tick <-chan time.Time
+	if !filter.changesOnly {
+		ticker := time.NewTicker(filter.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	due := time.NewTimer(0)
+	if !due.Stop() {
+		<-due.C
+	}
+	defer due.Stop()
+	pending := false
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			log.Printf("stats listener disconnected")
+			return nil
+
+		case latest = <-sub.C:
+			if latest.Final {
+				log.Printf("election %d is closed, final stats sent", election.Id)
+				return send(latest)
+			}
+			if !filter.changesOnly || pending || !changed() {
+				continue
+			}
+			if wait := time.Until(sentAt.Add(filter.interval)); wait > 0 {
+				pending = true
+				due.Reset(wait)
+				continue
+			}
+			if err := send(latest); err != nil {
+				return err
+			}
+
+		case <-due.C:
+			pending = false
+			if !changed() {
+				continue
+			}
+			if err := send(latest); err != nil {
+				return err
+			}
+
+		case <-tick:
+			if err := send(latest); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package electionsv1
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/candidates"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// watchStream is a WatchStats stream served in process, Send hands the
+// stats to the test.
+type watchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*pb.Stats)
+}
+
+func (s *watchStream) Context() context.Context { return s.ctx }
+
+func (s *watchStream) Send(m *pb.Stats) error {
+	s.send(m)
+	return nil
+}
+
+// newWatchService serves election 1 with candidates 1 and 2, closing after
+// closesIn.
+func newWatchService(tb testing.TB, closesIn time.Duration) *Service {
+	tb.Helper()
+	candidateRegistry := candidates.NewRegistry()
+	for _, name := range []string{"Alice", "Bob"} {
+		if _, err := candidateRegistry.Create(candidates.Candidate{Name: name, Active: true}); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	electionRegistry := elections.NewRegistry()
+	now := time.Now()
+	if _, err := electionRegistry.Create(elections.Election{
+		Id: 1, Title: "Mayor", Candidates: []uint32{1, 2},
+		OpensAt: now.Add(-time.Hour), ClosesAt: now.Add(closesIn),
+	}); err != nil {
+		tb.Fatal(err)
+	}
+	voting := elections.NewVoting(candidateRegistry, electionRegistry, elections.NewMemoryTally())
+	return NewService(electionRegistry, voting)
+}
+
+// watch runs WatchStats until the test ends, the stats it sends are
+// received from the channel.
+func watch(t *testing.T, s *Service, req *pb.WatchStatsRequest) <-chan *pb.Stats {
+	c := make(chan *pb.Stats, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := s.WatchStats(req, &watchStream{ctx: ctx, send: func(m *pb.Stats) { c <- m }}); err != nil {
+			t.Error(err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return c
+}
+
+func vote(t testing.TB, s *Service, passport string, candidateId uint32) {
+	if _, err := s.SubmitVote(context.Background(), &pb.SubmitVoteRequest{Vote: &pb.Vote{ElectionId: 1, Passport: passport, CandidateId: candidateId}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectStats(t *testing.T, c <-chan *pb.Stats, records map[uint32]uint32, final bool) {
+	t.Helper()
+	select {
+	case m := <-c:
+		if !maps.Equal(m.GetRecords(), records) || m.GetFinal() != final {
+			t.Fatalf("expected %v (final %v), got %v (final %v)", records, final, m.GetRecords(), m.GetFinal())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected %v, got nothing", records)
+	}
+}
+
+func expectNoStats(t *testing.T, c <-chan *pb.Stats, wait time.Duration) {
+	t.Helper()
+	select {
+	case m := <-c:
+		t.Fatalf("expected no stats, got %v", m)
+	case <-time.After(wait):
+	}
+}
+
+func TestWatchStats_ChangesOnly(t *testing.T) {
+	s := newWatchService(t, 300*time.Millisecond)
+	c := watch(t, s, &pb.WatchStatsRequest{
+		ElectionId:      1,
+		CandidateIds:    []uint32{1},
+		MinInterval:     durationpb.New(100 * time.Millisecond),
+		ChangesOnly:     true,
+		InitialSnapshot: true,
+	})
+	expectStats(t, c, map[uint32]uint32{}, false)
+
+	// votes for other candidates are filtered out and change nothing
+	vote(t, s, "a", 2)
+	expectNoStats(t, c, 50*time.Millisecond)
+
+	// the first change is sent right away, the next waits for min_interval
+	// and carries both votes
+	vote(t, s, "b", 1)
+	expectStats(t, c, map[uint32]uint32{1: 1}, false)
+	start := time.Now()
+	vote(t, s, "c", 1)
+	vote(t, s, "d", 1)
+	expectStats(t, c, map[uint32]uint32{1: 3}, false)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected the change to be held back by min_interval, sent after %v", elapsed)
+	}
+
+	// the final stats are sent once the election closes, without votes
+	expectStats(t, c, map[uint32]uint32{1: 3}, true)
+}
+
+func TestWatchStats_Interval(t *testing.T) {
+	s := newWatchService(t, time.Hour)
+	vote(t, s, "a", 1)
+	c := watch(t, s, &pb.WatchStatsRequest{ElectionId: 1, MinInterval: durationpb.New(defaultMinInterval)})
+
+	// unchanged stats are sent every interval
+	expectStats(t, c, map[uint32]uint32{1: 1}, false)
+	expectStats(t, c, map[uint32]uint32{1: 1}, false)
+	vote(t, s, "b", 2)
+	for {
+		select {
+		case m := <-c:
+			if m.GetRecords()[2] == 1 {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the new vote to be sent")
+		}
+	}
+}
+
+func TestNewStatsFilter(t *testing.T) {
+	for name, c := range map[string]struct {
+		min  *durationpb.Duration
+		want time.Duration
+	}{
+		"unset":    {nil, time.Second},
+		"zero":     {durationpb.New(0), defaultMinInterval},
+		"negative": {durationpb.New(-time.Second), defaultMinInterval},
+		"short":    {durationpb.New(time.Nanosecond), defaultMinInterval},
+		"long":     {durationpb.New(time.Minute), time.Minute},
+	} {
+		t.Run(name, func(t *testing.T) {
+			filter := newStatsFilter(&pb.WatchStatsRequest{ElectionId: 1, MinInterval: c.min}, time.Second, defaultMinInterval)
+			if filter.interval != c.want {
+				t.Fatalf("expected an interval of %v, got %v", c.want, filter.interval)
+			}
+		})
+	}
+}
+
+func TestWatchStats_Closed(t *testing.T) {
+	s := newWatchService(t, 0)
+	c := watch(t, s, &pb.WatchStatsRequest{ElectionId: 1, ChangesOnly: true})
+	expectStats(t, c, map[uint32]uint32{}, true)
+}
+
+func TestWatchStats_Feeds(t *testing.T) {
+	s := newWatchService(t, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.WatchStats(&pb.WatchStatsRequest{ElectionId: 1, ChangesOnly: true}, &watchStream{ctx: ctx, send: func(*pb.Stats) {}})
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	s.feeds.lock.Lock()
+	if len(s.feeds.feeds) != 1 {
+		t.Errorf("expected a single feed for the subscribers, got %d", len(s.feeds.feeds))
+	}
+	s.feeds.lock.Unlock()
+
+	// the feed stops with its last subscriber
+	cancel()
+	wg.Wait()
+	s.feeds.lock.Lock()
+	defer s.feeds.lock.Unlock()
+	if len(s.feeds.feeds) != 0 {
+		t.Fatalf("expected the feed to stop, got %d", len(s.feeds.feeds))
+	}
+}
+
+// BenchmarkWatchStats measures a vote reaching every subscriber, the stats
+// are read once per change whatever the number of subscribers.
+func BenchmarkWatchStats(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		b.Run(fmt.Sprintf("subscribers=%d", n), func(b *testing.B) {
+			s := newWatchService(b, time.Hour)
+			// every change is sent, the fan-out is measured and not the floor
+			s.MinInterval = 0
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var ready sync.WaitGroup
+			var sends atomic.Int64
+			seen := make([]atomic.Uint32, n)
+			for i := range seen {
+				ready.Add(1)
+				first := true
+				stream := &watchStream{ctx: ctx, send: func(m *pb.Stats) {
+					sends.Add(1)
+					seen[i].Store(m.GetRecords()[1])
+					if first {
+						first = false
+						ready.Done()
+					}
+				}}
+				go s.WatchStats(&pb.WatchStatsRequest{ElectionId: 1, ChangesOnly: true, InitialSnapshot: true, MinInterval: durationpb.New(0)}, stream)
+			}
+			ready.Wait()
+			sends.Store(0)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				vote(b, s, fmt.Sprint(i), 1)
+			}
+			// every subscriber ends with the last count
+			for i := range seen {
+				for seen[i].Load() != uint32(b.N) {
+					time.Sleep(100 * time.Microsecond)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(sends.Load())/float64(b.N), "sends/op")
+		})
+	}
+}
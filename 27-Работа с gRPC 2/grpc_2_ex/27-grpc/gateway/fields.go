@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fieldPath resolves a dotted path of proto or JSON field names, every
+// field but the last must be a singular message.
+func fieldPath(md protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	var fields []protoreflect.FieldDescriptor
+	for i, name := range strings.Split(path, ".") {
+		if i > 0 {
+			prev := fields[i-1]
+			if prev.Message() == nil || prev.IsList() || prev.IsMap() {
+				return nil, fmt.Errorf("field %s is not a message", prev.Name())
+			}
+			md = prev.Message()
+		}
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("%s has no field %s", md.FullName(), name)
+		}
+		fields = append(fields, fd)
+	}
+	return fields, nil
+}
+
+// mutable returns the message of the path, creating the messages on it.
+// The path must be valid.
+func mutable(m protoreflect.Message, path string) protoreflect.Message {
+	fields, _ := fieldPath(m.Descriptor(), path)
+	for _, fd := range fields {
+		m = m.Mutable(fd).Message()
+	}
+	return m
+}
+
+// setField sets the field of the path from query or path values, a
+// repeated field takes all of them, others only one.
+func setField(m protoreflect.Message, path string, values []string) error {
+	fields, err := fieldPath(m.Descriptor(), path)
+	if err != nil {
+		return err
+	}
+	for _, fd := range fields[:len(fields)-1] {
+		m = m.Mutable(fd).Message()
+	}
+	fd := fields[len(fields)-1]
+	switch {
+	case fd.IsMap():
+		return fmt.Errorf("map field %s can't be set from a parameter", path)
+	case fd.IsList():
+		list := m.Mutable(fd).List()
+		for _, raw := range values {
+			v, err := parseValue(fd, list.NewElement(), raw)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			list.Append(v)
+		}
+		return nil
+	case len(values) != 1:
+		return fmt.Errorf("%s: expect a single value, got %d", path, len(values))
+	}
+	v, err := parseValue(fd, m.NewField(fd), values[0])
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	m.Set(fd, v)
+	return nil
+}
+
+// parseValue reads a scalar as in Go syntax and a message, like a
+// timestamp, as its JSON string form. zero is a new value of the field.
+func parseValue(fd protoreflect.FieldDescriptor, zero protoreflect.Value, raw string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(raw), nil
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(raw)
+		return protoreflect.ValueOfBytes(b), err
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(raw)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(raw, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(raw, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		return protoreflect.ValueOfUint64(n), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(raw, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(raw, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.EnumKind:
+		if v := fd.Enum().Values().ByName(protoreflect.Name(raw)); v != nil {
+			return protoreflect.ValueOfEnum(v.Number()), nil
+		}
+		n, err := strconv.ParseInt(raw, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), err
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		err := protojson.Unmarshal([]byte(strconv.Quote(raw)), zero.Message().Interface())
+		return zero, err
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %s", fd.Kind())
+}
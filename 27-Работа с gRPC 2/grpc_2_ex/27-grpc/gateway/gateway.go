@@ -0,0 +1,297 @@
+// Package gateway serves gRPC methods to HTTP/JSON clients. Every method is
+// declared by a Rule; requests are read from the path, the query and the
+// body, messages are sent as protojson and the gateway calls the method
+// over a gRPC connection, so the interceptors of the server apply to them
+// as to any other call.
+//
+// Unary methods answer with the response message. Server streaming methods
+// answer with server-sent events if the client accepts text/event-stream,
+// otherwise with chunked NDJSON, a message per line. Errors are sent as the
+// google.rpc.Status in JSON with the HTTP status of its code, see
+// HTTPStatus.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/correlation"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// maxBody bounds the request body.
+const maxBody = 1 << 20
+
+var (
+	marshal   = protojson.MarshalOptions{UseProtoNames: true}
+	unmarshal = protojson.UnmarshalOptions{}
+)
+
+// Rule maps an HTTP route to a gRPC method.
+type Rule struct {
+	// Pattern is a http.ServeMux pattern with the method, like
+	// "GET /v1/elections/{id}". Path wildcards set the request fields of
+	// their names.
+	Pattern string
+	// Method is the full gRPC method name, like the FullMethodName
+	// constants of the generated code.
+	Method string
+	// Body is the request field the body is read into, "*" reads it into
+	// the whole request, empty means the request has no body. Query
+	// parameters set the other fields, nested ones by a dotted path.
+	Body string
+}
+
+// Gateway is the http.Handler serving the rules. The server sees the
+// gateway as the peer of every call, the IP address of the HTTP client is
+// sent as ForwardedFor metadata instead, see ratelimit.ForwardedIP.
+type Gateway struct {
+	conn grpc.ClientConnInterface
+	mux  *http.ServeMux
+	// Headers are sent to the method as metadata with lower-case names and
+	// copied back from the response header metadata.
+	Headers []string
+}
+
+// New checks the rules against the registered protobuf files, the packages
+// with the generated code of the methods must be imported.
+func New(conn grpc.ClientConnInterface, rules ...Rule) (*Gateway, error) {
+	g := &Gateway{
+		conn:    conn,
+		mux:     http.NewServeMux(),
+		Headers: []string{"Authorization", correlation.Header},
+	}
+	for _, rule := range rules {
+		h, err := g.handler(rule)
+		if err != nil {
+			return nil, fmt.Errorf("gateway rule %q: %w", rule.Pattern, err)
+		}
+		g.mux.Handle(rule.Pattern, h)
+	}
+	return g, nil
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := g.mux.Handler(r); pattern == "" {
+		writeError(w, status.Newf(codes.NotFound, "no method for %s %s", r.Method, r.URL.Path))
+		return
+	}
+	g.mux.ServeHTTP(w, r)
+}
+
+// route is a Rule resolved to the descriptors of its method.
+type route struct {
+	Rule
+	method   protoreflect.MethodDescriptor
+	input    protoreflect.MessageType
+	output   protoreflect.MessageType
+	wildcard []string
+}
+
+func (g *Gateway) handler(rule Rule) (http.Handler, error) {
+	service, name, ok := strings.Cut(strings.TrimPrefix(rule.Method, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("method %q is not a full method name", rule.Method)
+	}
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, err
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(name))
+	if md == nil {
+		return nil, fmt.Errorf("service %s has no method %s", service, name)
+	}
+	if md.IsStreamingClient() {
+		return nil, errors.New("client streaming methods are not supported")
+	}
+
+	rt := &route{Rule: rule, method: md}
+	if rt.input, err = protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName()); err != nil {
+		return nil, err
+	}
+	if rt.output, err = protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName()); err != nil {
+		return nil, err
+	}
+	if rule.Body != "" && rule.Body != "*" {
+		fd, err := fieldPath(md.Input(), rule.Body)
+		if err != nil {
+			return nil, err
+		}
+		if fd[len(fd)-1].Message() == nil || fd[len(fd)-1].IsList() || fd[len(fd)-1].IsMap() {
+			return nil, fmt.Errorf("body field %s is not a message", rule.Body)
+		}
+	}
+	for _, segment := range strings.Split(rule.Pattern, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			wildcard := strings.TrimSuffix(strings.Trim(segment, "{}"), "...")
+			if _, err := fieldPath(md.Input(), wildcard); err != nil {
+				return nil, err
+			}
+			rt.wildcard = append(rt.wildcard, wildcard)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := rt.request(w, r)
+		if err != nil {
+			writeError(w, status.New(codes.InvalidArgument, err.Error()))
+			return
+		}
+		ctx := g.outgoing(r)
+		if md.IsStreamingServer() {
+			g.stream(ctx, w, r, rt, req)
+			return
+		}
+		g.unary(ctx, w, rt, req)
+	}), nil
+}
+
+// request reads the request message of the route from r.
+func (rt *route) request(w http.ResponseWriter, r *http.Request) (proto.Message, error) {
+	req := rt.input.New().Interface()
+	if rt.Body != "" {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > 0 {
+			target := req
+			if rt.Body != "*" {
+				target = mutable(req.ProtoReflect(), rt.Body).Interface()
+			}
+			if err := unmarshal.Unmarshal(body, target); err != nil {
+				return nil, fmt.Errorf("invalid body: %w", err)
+			}
+		}
+	}
+	for _, name := range rt.wildcard {
+		if err := setField(req.ProtoReflect(), name, []string{r.PathValue(name)}); err != nil {
+			return nil, err
+		}
+	}
+	for name, values := range r.URL.Query() {
+		if err := setField(req.ProtoReflect(), name, values); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// outgoing puts the Headers of r and the address of the client into the
+// outgoing metadata. The address replaces a forwarded one the client sent.
+func (g *Gateway) outgoing(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for _, name := range g.Headers {
+		if values := r.Header.Values(name); len(values) > 0 {
+			md.Append(strings.ToLower(name), values...)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	md.Set(ratelimit.ForwardedFor, host)
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+// header copies the Headers from the response metadata.
+func (g *Gateway) header(w http.ResponseWriter, md metadata.MD) {
+	for _, name := range g.Headers {
+		for _, v := range md.Get(name) {
+			w.Header().Add(name, v)
+		}
+	}
+}
+
+func (g *Gateway) unary(ctx context.Context, w http.ResponseWriter, rt *route, req proto.Message) {
+	resp := rt.output.New().Interface()
+	var md metadata.MD
+	err := g.conn.Invoke(ctx, rt.Method, req, resp, grpc.Header(&md))
+	g.header(w, md)
+	if err != nil {
+		writeError(w, status.Convert(err))
+		return
+	}
+	data, err := marshal.Marshal(resp)
+	if err != nil {
+		writeError(w, status.New(codes.Internal, err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		log.Printf("gateway: unable to write response of %s: %v", rt.Method, err)
+	}
+}
+
+// stream sends the messages as they arrive. The status is only sent as the
+// HTTP status if the stream fails before the first message, later errors
+// are sent as an error event or line.
+func (g *Gateway) stream(ctx context.Context, w http.ResponseWriter, r *http.Request, rt *route, req proto.Message) {
+	desc := &grpc.StreamDesc{StreamName: string(rt.method.Name()), ServerStreams: true}
+	st, err := g.conn.NewStream(ctx, desc, rt.Method)
+	if err == nil {
+		err = st.SendMsg(req)
+	}
+	if err == nil {
+		err = st.CloseSend()
+	}
+	if err != nil {
+		writeError(w, status.Convert(err))
+		return
+	}
+
+	out := newStreamWriter(w, r)
+	for first := true; ; first = false {
+		msg := rt.output.New().Interface()
+		err := st.RecvMsg(msg)
+		if first {
+			md, _ := st.Header()
+			g.header(w, md)
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			if first {
+				writeError(w, status.Convert(err))
+				return
+			}
+			out.error(status.Convert(err))
+			return
+		}
+		if err := out.message(msg); err != nil {
+			log.Printf("gateway: unable to stream %s: %v", rt.Method, err)
+			return
+		}
+	}
+}
+
+// writeError sends the status in JSON, the details included.
+func writeError(w http.ResponseWriter, st *status.Status) {
+	data, err := marshal.Marshal(st.Proto())
+	if err != nil {
+		data = []byte(fmt.Sprintf(`{"code":%d,"message":%q}`, codes.Internal, err.Error()))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(HTTPStatus(st.Code()))
+	w.Write(data)
+}
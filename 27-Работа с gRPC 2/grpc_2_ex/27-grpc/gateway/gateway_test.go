@@ -0,0 +1,221 @@
+package gateway_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/electionsv1/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/gateway"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// server answers with what it was sent: the receipt of a vote has the
+// authorization metadata as nonce and the forwarded client address as key
+// id, stats have the records of their election id.
+type server struct {
+	pb.UnimplementedElectionsServer
+}
+
+func (server) SubmitVote(ctx context.Context, req *pb.SubmitVoteRequest) (*pb.VoteReceipt, error) {
+	if req.GetVote().GetPassport() == "twice" {
+		return nil, elections.StatusError(elections.ErrAlreadyVoted)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", "req-1"))
+	return &pb.VoteReceipt{
+		ElectionId:  req.GetVote().GetElectionId(),
+		CandidateId: req.GetVote().GetCandidateId(),
+		Nonce:       strings.Join(md.Get("authorization"), ","),
+		KeyId:       strings.Join(md.Get(ratelimit.ForwardedFor), ","),
+	}, nil
+}
+
+func (server) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.Stats, error) {
+	return &pb.Stats{ElectionId: req.GetElectionId(), Records: map[uint32]uint32{1: req.GetElectionId()}}, nil
+}
+
+func (server) WatchStats(req *pb.WatchStatsRequest, srv pb.Elections_WatchStatsServer) error {
+	if req.GetElectionId() == 0 {
+		return status.Error(codes.NotFound, "election not found")
+	}
+	for i := uint32(1); i <= 2; i++ {
+		if err := srv.Send(&pb.Stats{ElectionId: req.GetElectionId(), Records: map[uint32]uint32{1: i}, Final: i == 2}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newGateway(t *testing.T) http.Handler {
+	t.Helper()
+	s := grpc.NewServer()
+	pb.RegisterElectionsServer(s, server{})
+	lsn := bufconn.Listen(1 << 20)
+	go s.Serve(lsn)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lsn.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	gw, err := gateway.New(conn, electionsv1.GatewayRules...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gw
+}
+
+func do(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestGateway_Unary(t *testing.T) {
+	gw := newGateway(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/votes", strings.NewReader(`{"election_id": 1, "passport": "a", "candidate_id": 2}`))
+	r.Header.Set("Authorization", "Bearer token")
+	r.Header.Set("X-Forwarded-For", "10.0.0.9")
+	r.RemoteAddr = "10.0.0.1:5000"
+	w := do(gw, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var receipt struct {
+		ElectionId  uint32 `json:"election_id"`
+		CandidateId uint32 `json:"candidate_id"`
+		Nonce       string `json:"nonce"`
+		KeyId       string `json:"key_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &receipt); err != nil {
+		t.Fatal(err)
+	}
+	// the client address is the one of the connection, not the header
+	if receipt.ElectionId != 1 || receipt.CandidateId != 2 || receipt.Nonce != "Bearer token" || receipt.KeyId != "10.0.0.1" {
+		t.Fatalf("unexpected receipt %s", w.Body)
+	}
+	if id := w.Header().Get("X-Request-ID"); id != "req-1" {
+		t.Fatalf("expected the request id of the header metadata, got %q", id)
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		code   int
+	}{
+		{"already_voted", http.MethodPost, "/v1/votes", `{"election_id": 1, "passport": "twice", "candidate_id": 2}`, http.StatusConflict},
+		{"invalid_body", http.MethodPost, "/v1/votes", `{"election_id": "one"}`, http.StatusBadRequest},
+		{"unknown_field", http.MethodGet, "/v1/stats?election=1", "", http.StatusBadRequest},
+		{"invalid_query", http.MethodGet, "/v1/stats?election_id=-1", "", http.StatusBadRequest},
+		{"unknown_path", http.MethodGet, "/v1/votes", "", http.StatusNotFound},
+		{"unimplemented", http.MethodGet, "/v1/audit/head", "", http.StatusNotImplemented},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := do(gw, httptest.NewRequest(c.method, c.target, strings.NewReader(c.body)))
+			if w.Code != c.code {
+				t.Fatalf("expected %d, got %d: %s", c.code, w.Code, w.Body)
+			}
+			var st struct {
+				Code    int32  `json:"code"`
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil || st.Message == "" {
+				t.Fatalf("expected a status, got %s", w.Body)
+			}
+		})
+	}
+
+	// the details of the status are kept
+	w = do(gw, httptest.NewRequest(http.MethodPost, "/v1/votes", strings.NewReader(`{"election_id": 1, "passport": "twice", "candidate_id": 2}`)))
+	if !strings.Contains(w.Body.String(), `"reason":"ALREADY_VOTED"`) {
+		t.Fatalf("expected the ErrorInfo of the status, got %s", w.Body)
+	}
+}
+
+func TestGateway_Query(t *testing.T) {
+	w := do(newGateway(t), httptest.NewRequest(http.MethodGet, "/v1/stats?election_id=7", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var stats struct {
+		ElectionId uint32            `json:"election_id"`
+		Records    map[string]uint32 `json:"records"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.ElectionId != 7 || stats.Records["1"] != 7 {
+		t.Fatalf("unexpected stats %s", w.Body)
+	}
+}
+
+func TestGateway_Stream(t *testing.T) {
+	gw := newGateway(t)
+
+	w := do(gw, httptest.NewRequest(http.MethodGet, "/v1/stats:watch?election_id=1", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("expected NDJSON, got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 || !strings.Contains(lines[1], `"final":true`) {
+		t.Fatalf("expected 2 stats, the last final, got %q", lines)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/stats:watch?election_id=1", nil)
+	r.Header.Set("Accept", "text/event-stream")
+	w = do(gw, r)
+	if w.Header().Get("Content-Type") != "text/event-stream" || strings.Count(w.Body.String(), "data: ") != 2 {
+		t.Fatalf("expected 2 events, got %q: %s", w.Header().Get("Content-Type"), w.Body)
+	}
+
+	// a stream failing before the first message gets the HTTP status
+	w = do(gw, httptest.NewRequest(http.MethodGet, "/v1/stats:watch?election_id=0", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, rule := range []gateway.Rule{
+		{Pattern: "POST /v1/votes", Method: "/elections.v1.Elections/Unknown"},
+		{Pattern: "POST /v1/votes", Method: pb.Elections_SubmitVote_FullMethodName, Body: "passport"},
+		{Pattern: "POST /v1/votes", Method: pb.Elections_SubmitVotes_FullMethodName, Body: "*"},
+		{Pattern: "GET /v1/stats/{id}", Method: pb.Elections_GetStats_FullMethodName},
+	} {
+		if _, err := gateway.New(nil, rule); err == nil {
+			t.Fatalf("expected %v to be rejected", rule)
+		}
+	}
+	if _, err := gateway.New(nil, gateway.Rule{Pattern: "GET /v1/stats/{election_id}", Method: pb.Elections_GetStats_FullMethodName}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// streamWriter sends the messages of a server stream as server-sent events
+// or NDJSON, the header is written with the first message.
+type streamWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	sse     bool
+	started bool
+}
+
+func newStreamWriter(w http.ResponseWriter, r *http.Request) *streamWriter {
+	return &streamWriter{
+		w:   w,
+		rc:  http.NewResponseController(w),
+		sse: strings.Contains(r.Header.Get("Accept"), "text/event-stream"),
+	}
+}
+
+func (s *streamWriter) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	if s.sse {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// message sends msg and flushes it.
+func (s *streamWriter) message(msg proto.Message) error {
+	data, err := marshal.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.write("", data)
+}
+
+// error ends the stream with the status: an error event, or a line with
+// the status in the error field.
+func (s *streamWriter) error(st *status.Status) error {
+	data, err := marshal.Marshal(st.Proto())
+	if err != nil {
+		return err
+	}
+	if s.sse {
+		return s.write("error", data)
+	}
+	return s.write("", append(append([]byte(`{"error":`), data...), '}'))
+}
+
+func (s *streamWriter) write(event string, data []byte) error {
+	s.start()
+	var buf bytes.Buffer
+	if s.sse {
+		if event != "" {
+			buf.WriteString("event: " + event + "\n")
+		}
+		buf.WriteString("data: ")
+		buf.Write(data)
+		buf.WriteString("\n\n")
+	} else {
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Package lifecycle runs HTTP and gRPC servers and stops them gracefully.
+//
+// On shutdown the Runner goes through the steps in this order:
+//
+//  1. readiness flips to false, load balancers stop sending new requests;
+//  2. DrainDelay passes, requests already routed here still arrive;
+//  3. closers run in the order they were added, e.g. stream hubs send a
+//     close frame to their sockets;
+//  4. servers stop accepting connections and wait for running calls, all
+//     at once, until ShutdownTimeout is over, then they are closed forcibly.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Server is a server the Runner starts and stops.
+type Server interface {
+	// Serve blocks until the server fails or is shut down, in the latter
+	// case it returns nil.
+	Serve() error
+	// Shutdown stops the server gracefully, when ctx is done it stops it
+	// forcibly.
+	Shutdown(ctx context.Context) error
+}
+
+type named[T any] struct {
+	name string
+	v    T
+}
+
+type Runner struct {
+	// DrainDelay is the time between readiness turning false and the servers
+	// stopping.
+	DrainDelay time.Duration
+	// ShutdownTimeout limits closers and servers together.
+	ShutdownTimeout time.Duration
+
+	servers []named[Server]
+	closers []named[func(ctx context.Context) error]
+	onReady []func(ready bool)
+	ready   atomic.Bool
+}
+
+func New() *Runner {
+	return &Runner{
+		DrainDelay:      5 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+	}
+}
+
+func (r *Runner) AddServer(name string, s Server) {
+	r.servers = append(r.servers, named[Server]{name, s})
+}
+
+// AddCloser adds a function called on shutdown before the servers stop,
+// for connections the servers don't track, like hijacked websockets, or
+// long streams the servers would wait for.
+func (r *Runner) AddCloser(name string, close func(ctx context.Context) error) {
+	r.closers = append(r.closers, named[func(ctx context.Context) error]{name, close})
+}
+
+// OnReady adds a function called when readiness changes, e.g. to update
+// the gRPC health service.
+func (r *Runner) OnReady(f func(ready bool)) {
+	r.onReady = append(r.onReady, f)
+}
+
+// Ready reports whether the servers run and no shutdown has started.
+func (r *Runner) Ready() bool {
+	return r.ready.Load()
+}
+
+// ReadyHandler answers 200 while the Runner is ready and 503 otherwise.
+func (r *Runner) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !r.Ready() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+func (r *Runner) setReady(ready bool) {
+	r.ready.Store(ready)
+	for _, f := range r.onReady {
+		f(ready)
+	}
+}
+
+// Run starts the servers and shuts them down when ctx is done or any of
+// them fails. It returns the errors of serving and of the shutdown.
+func (r *Runner) Run(ctx context.Context) error {
+	failed := make(chan error, len(r.servers))
+	var wg sync.WaitGroup
+	for _, s := range r.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.v.Serve(); err != nil {
+				failed <- fmt.Errorf("%s: %w", s.name, err)
+			}
+		}()
+	}
+	r.setReady(true)
+
+	var errs []error
+	select {
+	case <-ctx.Done():
+		log.Printf("shutting down")
+	case err := <-failed:
+		log.Printf("shutting down, %v", err)
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, r.shutdown())
+	wg.Wait()
+	close(failed)
+	for err := range failed {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Runner) shutdown() error {
+	r.setReady(false)
+	time.Sleep(r.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, c := range r.closers {
+		if err := c.v(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		}
+	}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for _, s := range r.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.v.Shutdown(ctx); err != nil {
+				lock.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,185 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// trace records the shutdown steps, servers stop concurrently.
+type trace struct {
+	lock  sync.Mutex
+	steps []string
+}
+
+func (t *trace) add(step string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.steps = append(t.steps, step)
+}
+
+func (t *trace) get() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]string(nil), t.steps...)
+}
+
+type fakeServer struct {
+	trace *trace
+	name  string
+	err   error
+	stop  chan struct{}
+}
+
+func newFakeServer(t *trace, name string, err error) *fakeServer {
+	return &fakeServer{trace: t, name: name, err: err, stop: make(chan struct{})}
+}
+
+func (s *fakeServer) Serve() error {
+	if s.err != nil {
+		return s.err
+	}
+	<-s.stop
+	return nil
+}
+
+func (s *fakeServer) Shutdown(ctx context.Context) error {
+	s.trace.add("shutdown " + s.name)
+	if s.err == nil {
+		close(s.stop)
+	}
+	return nil
+}
+
+func TestRunner_Order(t *testing.T) {
+	tr := &trace{}
+	r := New()
+	r.DrainDelay = 50 * time.Millisecond
+	r.ShutdownTimeout = time.Second
+	r.AddServer("http", newFakeServer(tr, "http", nil))
+	r.OnReady(func(ready bool) {
+		if ready {
+			tr.add("ready")
+		} else {
+			tr.add("not ready")
+		}
+	})
+
+	var notReadyAt time.Time
+	r.OnReady(func(ready bool) {
+		if !ready {
+			notReadyAt = time.Now()
+		}
+	})
+	r.AddCloser("ws", func(ctx context.Context) error {
+		if r.Ready() {
+			t.Error("closer runs while ready")
+		}
+		if d := time.Since(notReadyAt); d < r.DrainDelay {
+			t.Errorf("closer runs %v after readiness is off, before the drain delay", d)
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("closer has no deadline")
+		}
+		tr.add("close ws")
+		return nil
+	})
+	r.AddCloser("sse", func(ctx context.Context) error {
+		tr.add("close sse")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Run(ctx) }()
+	for !r.Ready() {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"ready", "not ready", "close ws", "close sse", "shutdown http"}
+	if got := tr.get(); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestRunner_ServerFails(t *testing.T) {
+	tr := &trace{}
+	r := New()
+	r.DrainDelay = 0
+	failure := errors.New("address already in use")
+	r.AddServer("grpc", newFakeServer(tr, "grpc", failure))
+	r.AddServer("http", newFakeServer(tr, "http", nil))
+
+	err := r.Run(context.Background())
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected serve error, got %v", err)
+	}
+	if steps := tr.get(); len(steps) != 2 {
+		t.Fatalf("expected both servers to be shut down, got %v", steps)
+	}
+	if r.Ready() {
+		t.Fatal("expected not ready")
+	}
+}
+
+func TestReadyHandler(t *testing.T) {
+	r := New()
+	check := func(exp int) {
+		w := httptest.NewRecorder()
+		r.ReadyHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if w.Code != exp {
+			t.Fatalf("expected %d, got %d", exp, w.Code)
+		}
+	}
+	check(http.StatusServiceUnavailable)
+	r.setReady(true)
+	check(http.StatusOK)
+	r.setReady(false)
+	check(http.StatusServiceUnavailable)
+}
+
+func TestGRPC_Shutdown(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := GRPC(grpc.NewServer(), lis)
+	served := make(chan error)
+	go func() { served <- s.Serve() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("expected nil after shutdown, got %v", err)
+	}
+}
+
+func TestHTTP_Shutdown(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0"}
+	s := HTTP(srv)
+	served := make(chan error)
+	go func() { served <- s.Serve() }()
+	time.Sleep(10 * time.Millisecond)
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("expected nil after shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+)
+
+type httpServer struct {
+	srv *http.Server
+}
+
+// HTTP runs srv with ListenAndServe, on shutdown the connections still
+// busy at the deadline are closed.
+func HTTP(srv *http.Server) Server {
+	return httpServer{srv}
+}
+
+func (s httpServer) Serve() error {
+	if err := s.srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s httpServer) Shutdown(ctx context.Context) error {
+	err := s.srv.Shutdown(ctx)
+	if err != nil {
+		s.srv.Close()
+	}
+	return err
+}
+
+type grpcServer struct {
+	srv *grpc.Server
+	lis net.Listener
+}
+
+// GRPC serves srv on lis, on shutdown the calls still running at the
+// deadline are cancelled.
+func GRPC(srv *grpc.Server, lis net.Listener) Server {
+	return grpcServer{srv, lis}
+}
+
+func (s grpcServer) Serve() error {
+	// stopped before it started serving
+	if err := s.srv.Serve(s.lis); !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+func (s grpcServer) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// UnaryServerInterceptor records every call, see ObserveGRPC.
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.ObserveGRPC(info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor records every stream once it ends.
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.ObserveGRPC(info.FullMethod, status.Code(err), time.Since(start))
+		return err
+	}
+}
+
+// ObserveGRPC records a call of the full method name, like
+// "/elections.Elections/SubmitVote".
+func (m *Metrics) ObserveGRPC(fullMethod string, code codes.Code, d time.Duration) {
+	service, method := splitMethod(fullMethod)
+	m.grpcHandled.WithLabelValues(service, method, code.String()).Inc()
+	m.grpcDuration.WithLabelValues(service, method).Observe(d.Seconds())
+}
+
+func splitMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "unknown", fullMethod
+	}
+	return service, method
+}
+
+// VoteInterceptor records the votes of the methods, the reason of a
+// rejection is the status code, like "already_exists". candidateField is
+// the path to the candidate id in the request, like "vote.candidate_id".
+// The candidate of a vote rejected as InvalidArgument or NotFound is not
+// trusted and recorded as unknown, put the interceptor after auth and rate
+// limits, votes they reject are not counted.
+func (m *Metrics) VoteInterceptor(candidateField string, methods ...string) grpc.UnaryServerInterceptor {
+	names := strings.Split(candidateField, ".")
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !contains(methods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+		resp, err := handler(ctx, req)
+		m.VoteStatus(uintField(req, names), err)
+		return resp, err
+	}
+}
+
+// VoteStatus records a vote handled with the status error err, for votes
+// that don't go through VoteInterceptor, like the ones sent over a stream.
+func (m *Metrics) VoteStatus(candidateId uint32, err error) {
+	reason := ""
+	if code := status.Code(err); code != codes.OK {
+		reason = codeReason(code)
+		if code == codes.InvalidArgument || code == codes.NotFound {
+			candidateId = 0
+		}
+	}
+	m.Vote(candidateId, reason)
+}
+
+// StatsStreamInterceptor follows the number of open streams of the stats
+// methods.
+func (m *Metrics) StatsStreamInterceptor(methods ...string) grpc.StreamServerInterceptor {
+	gauge := m.StatsStreams(TransportGRPC)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !contains(methods, info.FullMethod) {
+			return handler(srv, ss)
+		}
+		gauge.Inc()
+		defer gauge.Dec()
+		return handler(srv, ss)
+	}
+}
+
+func contains(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// codeReason turns InvalidArgument into "invalid_argument".
+func codeReason(code codes.Code) string {
+	var b strings.Builder
+	for i, c := range code.String() {
+		if c >= 'A' && c <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			c += 'a' - 'A'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// uintField returns the unsigned integer at the path of field names, 0 if
+// there is none.
+func uintField(msg any, names []string) uint32 {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return 0
+	}
+	value := m.ProtoReflect()
+	for i, name := range names {
+		field := value.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if field == nil || field.IsList() || field.IsMap() {
+			return 0
+		}
+		if i == len(names)-1 {
+			switch field.Kind() {
+			case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+				return uint32(value.Get(field).Uint())
+			case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+				if v := value.Get(field).Int(); v > 0 {
+					return uint32(v)
+				}
+			}
+			return 0
+		}
+		if field.Message() == nil || !value.Has(field) {
+			return 0
+		}
+		value = value.Get(field).Message()
+	}
+	return 0
+}
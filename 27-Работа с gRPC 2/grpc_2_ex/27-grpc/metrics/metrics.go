@@ -0,0 +1,122 @@
+// Package metrics exports RED metrics of HTTP routes and gRPC methods,
+// request rate, errors and duration, and business metrics of the elections
+// in the Prometheus text format.
+//
+// Errors are the requests with a 5xx status or a gRPC code other than OK
+// in the code label of the request counters.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Vote results.
+const (
+	Accepted = "accepted"
+	Rejected = "rejected"
+)
+
+// Transports of stats streams.
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// unknownCandidate labels votes whose candidate id can't be trusted, ids
+// sent by callers would blow up the number of series.
+const unknownCandidate = "unknown"
+
+// Metrics holds the collectors in its own registry, so tests and several
+// servers in one process don't clash over the default one.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	grpcHandled  *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	votes        *prometheus.CounterVec
+	streams      *prometheus.GaugeVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests by method and route pattern.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		grpcHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "gRPC calls by service, method and status code.",
+		}, []string{"service", "method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Duration of gRPC calls by service and method, streams included.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"service", "method"}),
+		votes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "elections_votes_total",
+			Help: "Votes by candidate, result and the reason of rejection.",
+		}, []string{"candidate_id", "result", "reason"}),
+		streams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "elections_stats_streams_active",
+			Help: "Stats streams open now by transport.",
+		}, []string{"transport"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration,
+		m.grpcHandled, m.grpcDuration,
+		m.votes, m.streams,
+	)
+	return m
+}
+
+// Registry returns the registry of the collectors, to add more of them.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the metrics, usually on /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTP records a request, route is the pattern of the route, not the
+// path, to keep the number of series bounded.
+func (m *Metrics) ObserveHTTP(method, route string, code int, d time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(d.Seconds())
+}
+
+// Vote records an accepted vote if reason is empty and a rejected one
+// otherwise. Candidate 0 means the candidate is not known to be registered.
+func (m *Metrics) Vote(candidateId uint32, reason string) {
+	candidate := unknownCandidate
+	if candidateId != 0 {
+		candidate = strconv.FormatUint(uint64(candidateId), 10)
+	}
+	result := Accepted
+	if reason != "" {
+		result = Rejected
+	}
+	m.votes.WithLabelValues(candidate, result, reason).Inc()
+}
+
+// StatsStreams returns the gauge of stats streams open over the transport.
+func (m *Metrics) StatsStreams(transport string) prometheus.Gauge {
+	return m.streams.WithLabelValues(transport)
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/pb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.ObserveHTTP(http.MethodPost, "/vote", http.StatusOK, 10*time.Millisecond)
+	m.Vote(1, "")
+	m.StatsStreams(TransportHTTP).Inc()
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	for _, exp := range []string{
+		`http_requests_total{code="200",method="POST",route="/vote"} 1`,
+		`http_request_duration_seconds_count{method="POST",route="/vote"} 1`,
+		`elections_votes_total{candidate_id="1",reason="",result="accepted"} 1`,
+		`elections_stats_streams_active{transport="http"} 1`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), exp) {
+			t.Fatalf("expected %q in\n%s", exp, body)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	m := New()
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Elections_SubmitVote_FullMethodName}
+	interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unavailable, "down")
+	})
+
+	if v := testutil.ToFloat64(m.grpcHandled.WithLabelValues("elections.Elections", "SubmitVote", "OK")); v != 1 {
+		t.Fatalf("expected 1 OK call, got %v", v)
+	}
+	if v := testutil.ToFloat64(m.grpcHandled.WithLabelValues("elections.Elections", "SubmitVote", "Unavailable")); v != 1 {
+		t.Fatalf("expected 1 failed call, got %v", v)
+	}
+	if n := testutil.CollectAndCount(m.grpcDuration); n != 1 {
+		t.Fatalf("expected 1 histogram, got %d", n)
+	}
+}
+
+func TestVoteInterceptor(t *testing.T) {
+	m := New()
+	interceptor := m.VoteInterceptor("vote.candidate_id", pb.Elections_SubmitVote_FullMethodName)
+	vote := func(method string, candidateId uint32, err error) {
+		req := &pb.SubmitVoteRequest{Vote: &pb.SubmitVoteRequest_Vote{CandidateId: candidateId}}
+		interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		})
+	}
+	vote(pb.Elections_SubmitVote_FullMethodName, 1, nil)
+	vote(pb.Elections_SubmitVote_FullMethodName, 1, status.Error(codes.AlreadyExists, "voted"))
+	vote(pb.Elections_SubmitVote_FullMethodName, 99999, status.Error(codes.InvalidArgument, "no such candidate"))
+	vote("/elections.Elections/Other", 1, nil)
+
+	cases := []struct {
+		candidate, result, reason string
+	}{
+		{"1", Accepted, ""},
+		{"1", Rejected, "already_exists"},
+		{unknownCandidate, Rejected, "invalid_argument"},
+	}
+	for _, c := range cases {
+		if v := testutil.ToFloat64(m.votes.WithLabelValues(c.candidate, c.result, c.reason)); v != 1 {
+			t.Fatalf("expected 1 vote %v, got %v", c, v)
+		}
+	}
+	if n := testutil.CollectAndCount(m.votes); n != len(cases) {
+		t.Fatalf("expected %d series, got %d", len(cases), n)
+	}
+}
+
+func TestStatsStreamInterceptor(t *testing.T) {
+	m := New()
+	interceptor := m.StatsStreamInterceptor("/elections.Elections/GetStats")
+	gauge := m.StatsStreams(TransportGRPC)
+
+	var during float64
+	err := interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/elections.Elections/GetStats"}, func(srv interface{}, stream grpc.ServerStream) error {
+		during = testutil.ToFloat64(gauge)
+		return nil
+	})
+	if err != nil || during != 1 {
+		t.Fatalf("expected 1 stream while open, got %v, %v", during, err)
+	}
+	if v := testutil.ToFloat64(gauge); v != 0 {
+		t.Fatalf("expected 0 streams after close, got %v", v)
+	}
+}
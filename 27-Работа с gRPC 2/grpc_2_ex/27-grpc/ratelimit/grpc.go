@@ -0,0 +1,220 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// KeyFunc returns the key a request is limited by, msg is the request
+// message. An empty key lets the request through.
+type KeyFunc func(ctx context.Context, msg any) string
+
+// PeerIP limits by the IP address of the client.
+func PeerIP(ctx context.Context, _ any) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return "ip:" + host
+}
+
+// ForwardedFor is the metadata key a proxy forwards the address of its
+// client in.
+const ForwardedFor = "x-forwarded-for"
+
+// ForwardedIP limits calls of the trusted proxies by the client address
+// they forward in ForwardedFor, the last one of the list, and other calls
+// like PeerIP. Only the proxies may be trusted, a client can send any
+// address.
+func ForwardedIP(trusted ...string) KeyFunc {
+	return func(ctx context.Context, msg any) string {
+		key := PeerIP(ctx, msg)
+		if !slices.Contains(trusted, strings.TrimPrefix(key, "ip:")) {
+			return key
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(ForwardedFor)
+		if len(values) == 0 {
+			return key
+		}
+		hops := strings.Split(values[len(values)-1], ",")
+		if client := strings.TrimSpace(hops[len(hops)-1]); client != "" {
+			return "ip:" + client
+		}
+		return key
+	}
+}
+
+// Metadata limits by the value of the metadata key, like an API key.
+func Metadata(name string) KeyFunc {
+	return func(ctx context.Context, _ any) string {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(name); len(values) > 0 && values[0] != "" {
+			return name + ":" + values[0]
+		}
+		return ""
+	}
+}
+
+// MessageField limits by a string field of the request, path is a dot
+// separated list of proto field names like "vote.passport". Messages
+// without the field are not limited.
+func MessageField(path string) KeyFunc {
+	names := strings.Split(path, ".")
+	return func(_ context.Context, msg any) string {
+		m, ok := msg.(proto.Message)
+		if !ok {
+			return ""
+		}
+		value := m.ProtoReflect()
+		for i, name := range names {
+			field := value.Descriptor().Fields().ByName(protoreflect.Name(name))
+			if field == nil || field.IsList() || field.IsMap() {
+				return ""
+			}
+			if i == len(names)-1 {
+				if field.Kind() != protoreflect.StringKind || value.Get(field).String() == "" {
+					return ""
+				}
+				return path + ":" + value.Get(field).String()
+			}
+			if field.Message() == nil || !value.Has(field) {
+				return ""
+			}
+			value = value.Get(field).Message()
+		}
+		return ""
+	}
+}
+
+// ForMethods applies key only to the listed full method names, like
+// "/elections.Elections/SubmitVote".
+func ForMethods(key KeyFunc, methods ...string) KeyFunc {
+	return func(ctx context.Context, msg any) string {
+		method, _ := grpc.Method(ctx)
+		for _, m := range methods {
+			if m == method {
+				return key(ctx, msg)
+			}
+		}
+		return ""
+	}
+}
+
+// UnaryServerInterceptor rejects calls over the limit with
+// codes.ResourceExhausted and a RetryInfo detail.
+func UnaryServerInterceptor(limiter Limiter, key KeyFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := check(ctx, limiter, key, req, grpc.SetHeader); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor takes one request from the quota for every
+// received message.
+func StreamServerInterceptor(limiter Limiter, key KeyFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &limitedStream{ServerStream: ss, limiter: limiter, key: key})
+	}
+}
+
+// StreamCallServerInterceptor takes one request from the quota for every
+// call, before the first message is received, however many messages the
+// stream carries. key gets a nil message.
+func StreamCallServerInterceptor(limiter Limiter, key KeyFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := check(ss.Context(), limiter, key, nil, func(_ context.Context, md metadata.MD) error {
+			return ss.SetHeader(md)
+		})
+		if err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+type limitedStream struct {
+	grpc.ServerStream
+	limiter Limiter
+	key     KeyFunc
+}
+
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	ctx := s.Context()
+	return check(ctx, s.limiter, s.key, m, func(_ context.Context, md metadata.MD) error {
+		return s.SetHeader(md)
+	})
+}
+
+// Check takes one request from the quota of every key for msg, like a vote
+// of a batch limited on its own, and returns the error of the first key
+// over the limit. Nothing is sent in the headers.
+func Check(ctx context.Context, limiter Limiter, msg any, keys ...KeyFunc) error {
+	for _, key := range keys {
+		if err := check(ctx, limiter, key, msg, func(context.Context, metadata.MD) error { return nil }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func check(ctx context.Context, limiter Limiter, key KeyFunc, msg any, setHeader func(context.Context, metadata.MD) error) error {
+	k := key(ctx, msg)
+	if k == "" {
+		return nil
+	}
+	res, err := limiter.Allow(ctx, k)
+	if err != nil {
+		// voting matters more than limiting, let the request through
+		log.Printf("rate limiter failed, request is not limited: %v", err)
+		return nil
+	}
+	// headers can't be set once sent, that is fine for a stream
+	_ = setHeader(ctx, metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(res.Limit),
+		"ratelimit-remaining", strconv.Itoa(res.Remaining),
+		"ratelimit-reset", strconv.Itoa(seconds(res.Reset)),
+	))
+	if res.Allowed {
+		return nil
+	}
+
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded, retry in "+res.RetryAfter.String())
+	if detailed, err := st.WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(res.RetryAfter)},
+		&errdetails.ErrorInfo{Reason: "RATE_LIMITED", Domain: "ratelimit"},
+	); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// seconds rounds d up to whole seconds, as the RateLimit headers use them.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	limiter, err := NewMemory(TokenBucket, PerMinute(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptor := UnaryServerInterceptor(limiter, MessageField("vote.passport"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/elections.Elections/SubmitVote"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.SubmitVoteResponse{}, nil
+	}
+	vote := func(passport string) error {
+		req := &pb.SubmitVoteRequest{Vote: &pb.SubmitVoteRequest_Vote{Passport: passport, ElectionId: 1, CandidateId: 1}}
+		_, err := interceptor(context.Background(), req, info, handler)
+		return err
+	}
+
+	if err := vote("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vote("b"); err != nil {
+		t.Fatal(err)
+	}
+
+	st := status.Convert(vote("a"))
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", st)
+	}
+	if len(st.Details()) != 2 {
+		t.Fatalf("expected RetryInfo and ErrorInfo, got %v", st.Details())
+	}
+	retry, ok := st.Details()[0].(*errdetails.RetryInfo)
+	if delay := retry.GetRetryDelay().AsDuration(); !ok || delay <= 59*time.Second || delay > time.Minute {
+		t.Fatalf("expected retry in about 1m, got %v", st.Details()[0])
+	}
+	if info, ok := st.Details()[1].(*errdetails.ErrorInfo); !ok || info.GetReason() != "RATE_LIMITED" {
+		t.Fatalf("expected a RATE_LIMITED reason, got %v", st.Details()[1])
+	}
+
+	// messages without a passport are not limited
+	for i := 0; i < 3; i++ {
+		if _, err := interceptor(context.Background(), &pb.SubmitVoteRequest{}, info, handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *testStream) Context() context.Context { return s.ctx }
+
+func (s *testStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestStreamCallServerInterceptor(t *testing.T) {
+	limiter, err := NewMemory(TokenBucket, PerMinute(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptor := StreamCallServerInterceptor(limiter, Metadata("x-api-key"))
+	calls := 0
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		calls++
+		return nil
+	}
+	call := func(key string) (*testStream, error) {
+		ss := &testStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key))}
+		return ss, interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/elections.v1.Elections/SubmitVotes"}, handler)
+	}
+
+	ss, err := call("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ss.header.Get("ratelimit-remaining"); len(got) != 1 || got[0] != "0" {
+		t.Fatalf("expected the quota in the headers, got %v", ss.header)
+	}
+	if _, err := call("k2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call("k1"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the limited call not to be handled, got %d calls", calls)
+	}
+}
+
+func TestKeyFuncs(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "secret"))
+	if key := Metadata("x-api-key")(ctx, nil); key != "x-api-key:secret" {
+		t.Fatalf("unexpected key %q", key)
+	}
+	if key := Metadata("x-api-key")(context.Background(), nil); key != "" {
+		t.Fatalf("expected no key, got %q", key)
+	}
+
+	req := &pb.SubmitVoteRequest{Vote: &pb.SubmitVoteRequest_Vote{Passport: "p1"}}
+	if key := MessageField("vote.passport")(ctx, req); key != "vote.passport:p1" {
+		t.Fatalf("unexpected key %q", key)
+	}
+	if key := MessageField("vote.unknown")(ctx, req); key != "" {
+		t.Fatalf("expected no key, got %q", key)
+	}
+	if key := ForMethods(Metadata("x-api-key"), "/elections.Elections/SubmitVote")(ctx, req); key != "" {
+		t.Fatalf("expected no key outside of a call, got %q", key)
+	}
+}
+
+func TestForwardedIP(t *testing.T) {
+	call := func(addr string, md metadata.MD) context.Context {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 5000}})
+		return metadata.NewIncomingContext(ctx, md)
+	}
+	key := ForwardedIP("127.0.0.1")
+
+	if k := key(call("127.0.0.1", metadata.Pairs(ForwardedFor, "10.0.0.1, 10.0.0.2")), nil); k != "ip:10.0.0.2" {
+		t.Fatalf("expected the address forwarded by the proxy, got %q", k)
+	}
+	if k := key(call("127.0.0.1", nil), nil); k != "ip:127.0.0.1" {
+		t.Fatalf("expected the proxy address without a forwarded one, got %q", k)
+	}
+	// other peers can't pick the key they are limited by
+	if k := key(call("10.0.0.3", metadata.Pairs(ForwardedFor, "10.0.0.1")), nil); k != "ip:10.0.0.3" {
+		t.Fatalf("expected the peer address, got %q", k)
+	}
+}
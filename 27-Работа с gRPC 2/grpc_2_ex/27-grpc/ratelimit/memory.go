@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory keeps the state of every key in the process, so each instance of a
+// service has its own quota.
+type Memory struct {
+	alg  Algorithm
+	rate Rate
+	now  func() time.Time
+
+	lock      sync.Mutex
+	keys      map[string]*memoryState
+	lastSweep time.Time
+}
+
+type memoryState struct {
+	// token bucket
+	tokens   float64
+	refilled time.Time
+	// sliding window, window is the start of the current fixed window
+	window   time.Time
+	current  int64
+	previous int64
+}
+
+func NewMemory(alg Algorithm, rate Rate) (*Memory, error) {
+	if err := checkConfig(alg, rate); err != nil {
+		return nil, err
+	}
+	return &Memory{
+		alg:  alg,
+		rate: rate,
+		now:  time.Now,
+		keys: make(map[string]*memoryState),
+	}, nil
+}
+
+func (m *Memory) Allow(_ context.Context, key string) (Result, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	st, ok := m.keys[key]
+	if !ok {
+		st = &memoryState{tokens: float64(m.rate.Limit), refilled: now}
+		m.keys[key] = st
+	}
+	if m.alg == TokenBucket {
+		return m.takeToken(st, now), nil
+	}
+	return m.countInWindow(st, now), nil
+}
+
+func (m *Memory) takeToken(st *memoryState, now time.Time) Result {
+	if elapsed := now.Sub(st.refilled); elapsed > 0 {
+		st.tokens += float64(m.rate.Limit) * float64(elapsed) / float64(m.rate.Period)
+		st.tokens = min(st.tokens, float64(m.rate.Limit))
+		st.refilled = now
+	}
+	allowed := st.tokens >= 1
+	if allowed {
+		st.tokens--
+	}
+	return bucketResult(m.rate, allowed, st.tokens)
+}
+
+func (m *Memory) countInWindow(st *memoryState, now time.Time) Result {
+	window := now.Truncate(m.rate.Period)
+	switch {
+	case window.Equal(st.window):
+	case window.Sub(st.window) == m.rate.Period:
+		st.previous, st.current = st.current, 0
+	default:
+		st.previous, st.current = 0, 0
+	}
+	st.window = window
+
+	elapsed := now.Sub(window)
+	allowed := windowAllows(m.rate, st.current, st.previous, elapsed)
+	if allowed {
+		st.current++
+	}
+	return windowResult(m.rate, allowed, st.current, st.previous, elapsed)
+}
+
+// sweep drops keys whose quota is fully restored, at most once a period.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < m.rate.Period {
+		return
+	}
+	m.lastSweep = now
+	for key, st := range m.keys {
+		idle := now.Sub(st.refilled)
+		if m.alg == SlidingWindow {
+			idle = now.Sub(st.window)
+		}
+		if idle >= 2*m.rate.Period {
+			delete(m.keys, key)
+		}
+	}
+}
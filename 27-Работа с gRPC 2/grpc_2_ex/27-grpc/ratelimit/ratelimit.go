@@ -0,0 +1,133 @@
+// Package ratelimit limits how often a key, like a client IP, an API key or
+// a passport, may do something. Limiters keep their state in memory or in
+// Redis, the latter is shared by every instance of a service.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var ErrInvalid = errors.New("invalid rate limit")
+
+type Algorithm string
+
+const (
+	// TokenBucket allows bursts of up to Limit requests, the bucket refills
+	// evenly over Period.
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow allows Limit requests in any Period, the window is
+	// estimated from the counts of the current and previous fixed windows.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// Rate is Limit requests per Period.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+func PerSecond(n int) Rate { return Rate{Limit: n, Period: time.Second} }
+func PerMinute(n int) Rate { return Rate{Limit: n, Period: time.Minute} }
+
+func (r Rate) validate() error {
+	if r.Limit <= 0 || r.Period <= 0 {
+		return fmt.Errorf("%w: %d per %v", ErrInvalid, r.Limit, r.Period)
+	}
+	return nil
+}
+
+// Result describes the decision, it maps to the RateLimit-* headers.
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining is the number of requests left right now.
+	Remaining int
+	// Reset is the time until the quota is fully restored.
+	Reset time.Duration
+	// RetryAfter is the time until the next request may pass, zero when
+	// allowed.
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	// Allow takes one request from the quota of the key.
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// Config selects the algorithm and the backend of a limiter.
+type Config struct {
+	Algorithm Algorithm
+	Rate      Rate
+	// RedisAddr selects the Redis backend, memory is used if empty.
+	RedisAddr string
+}
+
+// New returns the limiter described by cfg.
+func New(cfg Config) (Limiter, error) {
+	if cfg.RedisAddr == "" {
+		return NewMemory(cfg.Algorithm, cfg.Rate)
+	}
+	client := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr})
+	return NewRedis(client, cfg.Algorithm, cfg.Rate)
+}
+
+func checkConfig(alg Algorithm, rate Rate) error {
+	if alg != TokenBucket && alg != SlidingWindow {
+		return fmt.Errorf("%w: unknown algorithm %q", ErrInvalid, alg)
+	}
+	return rate.validate()
+}
+
+// bucketResult builds the result from the tokens left in the bucket.
+func bucketResult(rate Rate, allowed bool, tokens float64) Result {
+	perToken := float64(rate.Period) / float64(rate.Limit)
+	res := Result{
+		Allowed:   allowed,
+		Limit:     rate.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration(math.Ceil((float64(rate.Limit) - tokens) * perToken)),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration(math.Ceil((1 - tokens) * perToken))
+	}
+	return res
+}
+
+// windowResult builds the result from the counts of the current and the
+// previous window, elapsed is the time since the current window started.
+// The counts include the request if it was allowed.
+func windowResult(rate Rate, allowed bool, current, previous int64, elapsed time.Duration) Result {
+	weight := 1 - float64(elapsed)/float64(rate.Period)
+	used := float64(previous)*weight + float64(current)
+	res := Result{
+		Allowed:   allowed,
+		Limit:     rate.Limit,
+		Remaining: max(rate.Limit-int(math.Ceil(used)), 0),
+		// both windows are gone by then
+		Reset: 2*rate.Period - elapsed,
+	}
+	if previous == 0 {
+		res.Reset = rate.Period - elapsed
+	}
+	if !allowed {
+		res.RetryAfter = rate.Period - elapsed
+		if previous > 0 && float64(current)+1 <= float64(rate.Limit) {
+			// wait until enough of the previous window slides out
+			need := used + 1 - float64(rate.Limit)
+			res.RetryAfter = time.Duration(math.Ceil(need / float64(previous) * float64(rate.Period)))
+		}
+	}
+	return res
+}
+
+// windowAllows tells whether one more request fits into the sliding window.
+func windowAllows(rate Rate, current, previous int64, elapsed time.Duration) bool {
+	weight := 1 - float64(elapsed)/float64(rate.Period)
+	return float64(previous)*weight+float64(current)+1 <= float64(rate.Limit)
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// backends returns a limiter of every backend, all driven by the clock.
+func backends(t *testing.T, alg Algorithm, rate Rate, clock *time.Time) map[string]Limiter {
+	now := func() time.Time { return *clock }
+
+	memory, err := NewMemory(alg, rate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory.now = now
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	r, err := NewRedis(client, alg, rate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.now = now
+
+	return map[string]Limiter{"memory": memory, "redis": r}
+}
+
+// allow takes n requests and returns how many of them passed.
+func allow(t *testing.T, l Limiter, key string, n int) (int, Result) {
+	t.Helper()
+	passed := 0
+	var res Result
+	for i := 0; i < n; i++ {
+		var err error
+		res, err = l.Allow(context.Background(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Allowed {
+			passed++
+		}
+	}
+	return passed, res
+}
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Date(2024, 9, 8, 12, 0, 0, 0, time.UTC)
+	clock := start
+	for name, l := range backends(t, TokenBucket, PerMinute(6), &clock) {
+		clock = start
+		t.Run(name, func(t *testing.T) {
+			passed, res := allow(t, l, "a", 8)
+			if passed != 6 {
+				t.Fatalf("expected a burst of 6, got %d", passed)
+			}
+			if res.Allowed || res.Remaining != 0 || res.RetryAfter != 10*time.Second || res.Reset != time.Minute {
+				t.Fatalf("unexpected result %+v", res)
+			}
+
+			// other keys have their own bucket
+			if passed, _ := allow(t, l, "b", 1); passed != 1 {
+				t.Fatal("expected b to pass")
+			}
+
+			// one token every 10s
+			clock = clock.Add(25 * time.Second)
+			passed, res = allow(t, l, "a", 3)
+			if passed != 2 {
+				t.Fatalf("expected 2 refilled tokens, got %d", passed)
+			}
+			if res.RetryAfter != 5*time.Second {
+				t.Fatalf("expected retry in 5s, got %v", res.RetryAfter)
+			}
+
+			clock = clock.Add(time.Hour)
+			passed, res = allow(t, l, "a", 1)
+			if passed != 1 || res.Remaining != 5 {
+				t.Fatalf("expected a full bucket, got %+v", res)
+			}
+		})
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	start := time.Date(2024, 9, 8, 12, 0, 0, 0, time.UTC)
+	clock := start
+	for name, l := range backends(t, SlidingWindow, PerMinute(10), &clock) {
+		clock = start
+		t.Run(name, func(t *testing.T) {
+			clock = clock.Add(30 * time.Second)
+			passed, res := allow(t, l, "a", 12)
+			if passed != 10 {
+				t.Fatalf("expected 10 in a window, got %d", passed)
+			}
+			if res.Remaining != 0 || res.RetryAfter != 30*time.Second {
+				t.Fatalf("unexpected result %+v", res)
+			}
+
+			// a quarter into the next window 3/4 of the previous one counts
+			clock = clock.Add(45 * time.Second)
+			passed, res = allow(t, l, "a", 5)
+			if passed != 2 {
+				t.Fatalf("expected 2 to pass, got %d", passed)
+			}
+			if res.Allowed || res.RetryAfter != 3*time.Second {
+				t.Fatalf("unexpected result %+v", res)
+			}
+
+			clock = clock.Add(2 * time.Minute)
+			if passed, _ := allow(t, l, "a", 10); passed != 10 {
+				t.Fatalf("expected a fresh window, got %d", passed)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, cfg := range []Config{
+		{Algorithm: "leaky", Rate: PerSecond(1)},
+		{Algorithm: TokenBucket, Rate: Rate{Limit: 0, Period: time.Second}},
+		{Algorithm: SlidingWindow, Rate: Rate{Limit: 1}},
+	} {
+		if _, err := New(cfg); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("expected ErrInvalid for %+v, got %v", cfg, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const defaultRedisPrefix = "ratelimit:"
+
+// tokenBucketScript refills and takes a token in one step, so concurrent
+// instances can't spend the same token. Time comes from the caller, the
+// tokens are returned as a string as Lua numbers are truncated to integers.
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or limit
+local ts = tonumber(state[2]) or now
+if now > ts then
+	tokens = math.min(limit, tokens + (now - ts) * limit / period)
+	ts = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', ts)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript counts the request in the current window if it fits,
+// KEYS are the counters of the current and the previous window.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local weight = tonumber(ARGV[3])
+
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+if previous * weight + current + 1 > limit then
+	return {0, current, previous}
+end
+current = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], 2 * period)
+return {1, current, previous}
+`)
+
+// Redis keeps the state in Redis, so the quota is shared by every instance
+// using the same server and prefix.
+type Redis struct {
+	client redis.Scripter
+	alg    Algorithm
+	rate   Rate
+	now    func() time.Time
+	// Prefix is prepended to every key, "ratelimit:" by default. Use
+	// different prefixes for limiters sharing a server.
+	Prefix string
+}
+
+func NewRedis(client redis.Scripter, alg Algorithm, rate Rate) (*Redis, error) {
+	if err := checkConfig(alg, rate); err != nil {
+		return nil, err
+	}
+	return &Redis{
+		client: client,
+		alg:    alg,
+		rate:   rate,
+		now:    time.Now,
+		Prefix: defaultRedisPrefix,
+	}, nil
+}
+
+func (r *Redis) Allow(ctx context.Context, key string) (Result, error) {
+	now := r.now()
+	// braces keep the keys of one limiter key in one cluster slot
+	key = r.Prefix + "{" + key + "}"
+	period := r.rate.Period.Milliseconds()
+
+	if r.alg == TokenBucket {
+		reply, err := tokenBucketScript.Run(ctx, r.client, []string{key},
+			r.rate.Limit, period, now.UnixMilli()).Slice()
+		if err != nil {
+			return Result{}, fmt.Errorf("ratelimit: %w", err)
+		}
+		allowed, _ := reply[0].(int64)
+		left, _ := reply[1].(string)
+		tokens, err := strconv.ParseFloat(left, 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("ratelimit: bad reply: %w", err)
+		}
+		return bucketResult(r.rate, allowed == 1, tokens), nil
+	}
+
+	window := now.Truncate(r.rate.Period)
+	elapsed := now.Sub(window)
+	weight := 1 - float64(elapsed)/float64(r.rate.Period)
+	keys := []string{
+		key + ":" + strconv.FormatInt(window.UnixMilli(), 10),
+		key + ":" + strconv.FormatInt(window.Add(-r.rate.Period).UnixMilli(), 10),
+	}
+	reply, err := slidingWindowScript.Run(ctx, r.client, keys,
+		r.rate.Limit, period, strconv.FormatFloat(weight, 'f', -1, 64)).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: %w", err)
+	}
+	return windowResult(r.rate, reply[0] == 1, reply[1], reply[2], elapsed), nil
+}
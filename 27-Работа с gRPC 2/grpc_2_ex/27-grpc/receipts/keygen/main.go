@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/receipts"
+)
+
+// go run ./receipts/keygen -keys receipt-keys.json
+// go run ./receipts/keygen -keys receipt-keys.json -id 2024-10 -retire
+//
+// Adds a signing key to the keys file, servers sign with it after a
+// restart. Older keys stay in the file so their receipts still verify,
+// -retire drops their private keys.
+
+var (
+	keysFile = flag.String("keys", "receipt-keys.json", "keys file to add the key to, created if missing")
+	id       = flag.String("id", time.Now().UTC().Format("2006-01-02"), "id of the new key")
+	retire   = flag.Bool("retire", false, "drop the private keys of the older keys")
+)
+
+func main() {
+	flag.Parse()
+
+	keys, err := receipts.ReadKeys(*keysFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatal(err)
+	}
+	if *retire {
+		for i := range keys {
+			if keys[i].PrivateKey == "" {
+				continue
+			}
+			// keep the public key, it may not be in the file yet
+			public, err := receipts.NewKeyring(keys[i : i+1])
+			if err != nil {
+				log.Fatal(err)
+			}
+			keys[i] = public.PublicKeys()[0]
+			keys[i].Signing = false
+		}
+	}
+
+	key, err := receipts.GenerateKey(*id)
+	if err != nil {
+		log.Fatal(err)
+	}
+	keys = append(keys, key)
+	if _, err := receipts.NewKeyring(keys); err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*keysFile, append(data, '\n'), 0o600); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("key %s added, public key %s\n", key.Id, key.PublicKey)
+}
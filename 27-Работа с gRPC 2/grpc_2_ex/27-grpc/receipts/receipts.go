@@ -0,0 +1,237 @@
+// Package receipts signs the receipts of accepted votes with Ed25519 for
+// every entry point (HTTP and gRPC). A voter keeps the receipt and checks it
+// offline with the public keys of the server. Keys are rotated by adding a
+// new signing key to the keyring, the older ones keep verifying the
+// receipts they signed.
+package receipts
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+)
+
+const nonceSize = 16
+
+var (
+	ErrInvalidKey   = errors.New("invalid receipt key")
+	ErrNoSigningKey = errors.New("no receipt signing key")
+	ErrUnknownKey   = errors.New("receipt signed with an unknown key")
+	ErrBadSignature = errors.New("receipt signature is invalid")
+)
+
+// Receipt is given to the voter for an accepted vote. The signature covers
+// every other field, the nonce makes receipts of equal votes differ.
+type Receipt struct {
+	ElectionId  uint32    `json:"election_id" xml:"election_id"`
+	CandidateId uint32    `json:"candidate_id" xml:"candidate_id"`
+	Time        time.Time `json:"time" xml:"time"`
+	Nonce       string    `json:"nonce" xml:"nonce"`
+	// Audit is the record of the vote in the audit log, if it is on.
+	Audit     *audit.Receipt `json:"audit,omitempty" xml:"audit,omitempty"`
+	KeyId     string         `json:"key_id,omitempty" xml:"key_id,omitempty"`
+	Signature string         `json:"signature,omitempty" xml:"signature,omitempty"`
+}
+
+// New returns an unsigned receipt with a random nonce.
+func New(electionId, candidateId uint32, t time.Time, auditReceipt *audit.Receipt) (*Receipt, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("cannot generate receipt nonce: %w", err)
+	}
+	return &Receipt{
+		ElectionId:  electionId,
+		CandidateId: candidateId,
+		Time:        t.UTC(),
+		Nonce:       base64.RawURLEncoding.EncodeToString(nonce),
+		Audit:       auditReceipt,
+	}, nil
+}
+
+// payload returns the signed bytes: the fields one per line, the audit
+// receipt as two empty lines if there is none.
+func (r *Receipt) payload() []byte {
+	var auditIndex, auditHash string
+	if r.Audit != nil {
+		auditIndex, auditHash = strconv.FormatUint(r.Audit.Index, 10), r.Audit.Hash
+	}
+	var buf bytes.Buffer
+	buf.WriteString("vote-receipt/v1")
+	for _, field := range []string{
+		r.KeyId,
+		strconv.FormatUint(uint64(r.ElectionId), 10),
+		strconv.FormatUint(uint64(r.CandidateId), 10),
+		r.Time.UTC().Format(time.RFC3339Nano),
+		r.Nonce,
+		auditIndex,
+		auditHash,
+	} {
+		buf.WriteByte('\n')
+		buf.WriteString(field)
+	}
+	return buf.Bytes()
+}
+
+// Key is a keyring entry as stored in a keys file. The public key is
+// derived from the private one if it is missing, a retired key keeps only
+// the public key.
+type Key struct {
+	Id         string `json:"id" xml:"id"`
+	PublicKey  string `json:"public_key,omitempty" xml:"public_key,omitempty"`
+	PrivateKey string `json:"private_key,omitempty" xml:"-"`
+	// Signing marks the key new receipts are signed with, it is set by
+	// Keyring.PublicKeys and ignored when loading.
+	Signing bool `json:"signing,omitempty" xml:"signing,omitempty"`
+}
+
+// GenerateKey returns a new key with the private key, keys are base64
+// encoded: the private key is the 32 byte seed.
+func GenerateKey(id string) (Key, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Key{}, fmt.Errorf("cannot generate receipt key: %w", err)
+	}
+	return Key{
+		Id:         id,
+		PublicKey:  base64.StdEncoding.EncodeToString(public),
+		PrivateKey: base64.StdEncoding.EncodeToString(private.Seed()),
+	}, nil
+}
+
+type keyPair struct {
+	public  ed25519.PublicKey
+	private ed25519.PrivateKey
+}
+
+// Keyring signs receipts with its signing key and verifies receipts of all
+// its keys. It is read-only once created.
+type Keyring struct {
+	keys    map[string]keyPair
+	order   []string
+	signing string
+}
+
+// NewKeyring checks the keys, the last key with a private key signs. A
+// keyring without private keys only verifies.
+func NewKeyring(keys []Key) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]keyPair, len(keys))}
+	for _, key := range keys {
+		if key.Id == "" {
+			return nil, fmt.Errorf("%w: key without id", ErrInvalidKey)
+		}
+		if _, ok := k.keys[key.Id]; ok {
+			return nil, fmt.Errorf("%w: duplicate id %q", ErrInvalidKey, key.Id)
+		}
+		pair, err := parseKey(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[key.Id] = pair
+		k.order = append(k.order, key.Id)
+		if pair.private != nil {
+			k.signing = key.Id
+		}
+	}
+	return k, nil
+}
+
+func parseKey(key Key) (keyPair, error) {
+	var pair keyPair
+	if key.PrivateKey != "" {
+		seed, err := base64.StdEncoding.DecodeString(key.PrivateKey)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return pair, fmt.Errorf("%w: private key of %q must be a base64 %d byte seed", ErrInvalidKey, key.Id, ed25519.SeedSize)
+		}
+		pair.private = ed25519.NewKeyFromSeed(seed)
+		pair.public = pair.private.Public().(ed25519.PublicKey)
+	}
+	if key.PublicKey != "" {
+		public, err := base64.StdEncoding.DecodeString(key.PublicKey)
+		if err != nil || len(public) != ed25519.PublicKeySize {
+			return pair, fmt.Errorf("%w: public key of %q must be base64 %d bytes", ErrInvalidKey, key.Id, ed25519.PublicKeySize)
+		}
+		if pair.public != nil && !pair.public.Equal(ed25519.PublicKey(public)) {
+			return pair, fmt.Errorf("%w: public key of %q doesn't match the private key", ErrInvalidKey, key.Id)
+		}
+		pair.public = public
+	}
+	if pair.public == nil {
+		return pair, fmt.Errorf("%w: %q has no key", ErrInvalidKey, key.Id)
+	}
+	return pair, nil
+}
+
+// ReadKeys reads keys from a JSON array like
+// [{"id": "2024-09", "private_key": "..."}].
+func ReadKeys(path string) ([]Key, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read receipt keys: %w", err)
+	}
+	var keys []Key
+	if err := json.Unmarshal(buf, &keys); err != nil {
+		return nil, fmt.Errorf("cannot parse receipt keys: %w", err)
+	}
+	return keys, nil
+}
+
+// LoadFile returns the keyring of the keys file, see ReadKeys.
+func LoadFile(path string) (*Keyring, error) {
+	keys, err := ReadKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(keys)
+}
+
+// SigningKey returns the id of the key receipts are signed with, empty if
+// the keyring only verifies.
+func (k *Keyring) SigningKey() string {
+	return k.signing
+}
+
+// Sign sets the key id and the signature of the receipt.
+func (k *Keyring) Sign(r *Receipt) error {
+	if k.signing == "" {
+		return ErrNoSigningKey
+	}
+	r.KeyId = k.signing
+	r.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(k.keys[k.signing].private, r.payload()))
+	return nil
+}
+
+// Verify checks the signature of the receipt with the key it names.
+func (k *Keyring) Verify(r *Receipt) error {
+	pair, ok := k.keys[r.KeyId]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKey, r.KeyId)
+	}
+	sig, err := base64.StdEncoding.DecodeString(r.Signature)
+	if err != nil || !ed25519.Verify(pair.public, r.payload(), sig) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// PublicKeys returns the keys without their private parts in the order
+// they were given, to be published for offline checks.
+func (k *Keyring) PublicKeys() []Key {
+	keys := make([]Key, 0, len(k.order))
+	for _, id := range k.order {
+		keys = append(keys, Key{
+			Id:        id,
+			PublicKey: base64.StdEncoding.EncodeToString(k.keys[id].public),
+			Signing:   id == k.signing,
+		})
+	}
+	return keys
+}
@@ -0,0 +1,133 @@
+package receipts
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+)
+
+func generate(t *testing.T, id string) Key {
+	t.Helper()
+	key, err := GenerateKey(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func signed(t *testing.T, k *Keyring) *Receipt {
+	t.Helper()
+	r, err := New(1, 2, time.Date(2024, 9, 8, 8, 0, 0, 0, time.UTC), &audit.Receipt{Index: 7, Hash: "ab"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Sign(r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestKeyring_SignVerify(t *testing.T) {
+	k, err := NewKeyring([]Key{generate(t, "k1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := signed(t, k)
+	if r.KeyId != "k1" || r.Signature == "" {
+		t.Fatalf("expected a signature of k1, got %+v", r)
+	}
+	if err := k.Verify(r); err != nil {
+		t.Fatal(err)
+	}
+
+	// the receipt travels as JSON
+	data, _ := json.Marshal(r)
+	decoded := &Receipt{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Verify(decoded); err != nil {
+		t.Fatalf("verify decoded: %v", err)
+	}
+
+	for name, change := range map[string]func(r *Receipt){
+		"candidate": func(r *Receipt) { r.CandidateId = 3 },
+		"election":  func(r *Receipt) { r.ElectionId = 2 },
+		"time":      func(r *Receipt) { r.Time = r.Time.Add(time.Second) },
+		"nonce":     func(r *Receipt) { r.Nonce = "other" },
+		"audit":     func(r *Receipt) { r.Audit = nil },
+		"signature": func(r *Receipt) { r.Signature = "bad" },
+	} {
+		changed := *r
+		change(&changed)
+		if err := k.Verify(&changed); !errors.Is(err, ErrBadSignature) {
+			t.Errorf("%s: expected ErrBadSignature, got %v", name, err)
+		}
+	}
+
+	other := *r
+	other.KeyId = "k2"
+	if err := k.Verify(&other); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+	if again := signed(t, k); again.Nonce == r.Nonce {
+		t.Fatal("expected a new nonce for every receipt")
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old := generate(t, "k1")
+	k1, _ := NewKeyring([]Key{old})
+	before := signed(t, k1)
+
+	// the old key is retired to its public part, the new one signs
+	retired := Key{Id: old.Id, PublicKey: old.PublicKey}
+	k2, err := NewKeyring([]Key{retired, generate(t, "k2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := signed(t, k2)
+	if after.KeyId != "k2" {
+		t.Fatalf("expected k2 to sign, got %s", after.KeyId)
+	}
+
+	// a voter only has the published keys
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data, _ := json.Marshal(k2.PublicKeys())
+	os.WriteFile(path, data, 0o644)
+	public, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*Receipt{before, after} {
+		if err := public.Verify(r); err != nil {
+			t.Fatalf("verify receipt of %s: %v", r.KeyId, err)
+		}
+	}
+	if err := public.Sign(&Receipt{}); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("expected ErrNoSigningKey, got %v", err)
+	}
+	if keys := k2.PublicKeys(); keys[0].Signing || !keys[1].Signing || keys[1].PrivateKey != "" {
+		t.Fatalf("unexpected public keys %+v", keys)
+	}
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	a, b := generate(t, "a"), generate(t, "b")
+	for name, keys := range map[string][]Key{
+		"no_id":     {{PrivateKey: a.PrivateKey}},
+		"duplicate": {a, {Id: "a", PublicKey: b.PublicKey}},
+		"mismatch":  {{Id: "a", PrivateKey: a.PrivateKey, PublicKey: b.PublicKey}},
+		"bad_seed":  {{Id: "a", PrivateKey: "c2hvcnQ="}},
+		"empty":     {{Id: "a"}},
+	} {
+		if _, err := NewKeyring(keys); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("%s: expected ErrInvalidKey, got %v", name, err)
+		}
+	}
+}
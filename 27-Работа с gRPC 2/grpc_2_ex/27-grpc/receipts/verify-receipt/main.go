@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/audit"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/elections-with-stats/pb"
+	"github.com/OtusGolang/webinars_practical_part/27-grpc/receipts"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Checks a vote receipt without contacting the server, with the public keys
+// published by it.
+//
+// curl 0.0.0.0:8080/receipts/keys | jq .data > keys.json
+// curl -H 'Content-Type: application/json' -d '{"election_id": 1, "candidate_id": 1, "passport": "test"}' -X POST 0.0.0.0:8080/vote > receipt.json
+// go run ./receipts/verify-receipt -keys keys.json receipt.json
+//
+// grpcurl -plaintext -d '{"election_id": 1, "candidate_id": 1, "passport": "test"}' localhost:50051 elections_with_stat.Elections/SubmitVote > receipt.json
+// grpcurl -plaintext localhost:50051 elections_with_stat.Elections/GetReceiptKeys | jq '[.keys[] | {id, public_key: .publicKey}]' > keys.json
+
+var keysFile = flag.String("keys", "keys.json", "JSON array of the public keys of the server")
+
+func main() {
+	flag.Parse()
+
+	keyring, err := receipts.LoadFile(*keysFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	in := io.Reader(os.Stdin)
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		in = f
+	}
+	r, err := readReceipt(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := keyring.Verify(r); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("ok: vote for candidate %d in election %d at %s, signed with key %s\n",
+		r.CandidateId, r.ElectionId, r.Time.Format("2006-01-02 15:04:05 MST"), r.KeyId)
+	if r.Audit != nil {
+		fmt.Printf("audit record %d:%s\n", r.Audit.Index, r.Audit.Hash)
+	}
+}
+
+// readReceipt takes the receipt as is, wrapped like in the HTTP response,
+// {"data": receipt}, or the JSON form of the gRPC VoteReceipt.
+func readReceipt(in io.Reader) (*receipts.Receipt, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("cannot parse receipt: %w", err)
+	}
+	if raw, ok := fields["data"]; ok {
+		data = raw
+	} else if _, ok := fields["keyId"]; ok {
+		return grpcReceipt(data)
+	}
+	r := &receipts.Receipt{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("cannot parse receipt: %w", err)
+	}
+	return r, nil
+}
+
+func grpcReceipt(data []byte) (*receipts.Receipt, error) {
+	m := &pb.VoteReceipt{}
+	if err := protojson.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("cannot parse receipt: %w", err)
+	}
+	r := &receipts.Receipt{
+		ElectionId:  m.GetElectionId(),
+		CandidateId: m.GetCandidateId(),
+		Time:        m.GetTime().AsTime(),
+		Nonce:       m.GetNonce(),
+		KeyId:       m.GetKeyId(),
+		Signature:   m.GetSignature(),
+	}
+	if a := m.GetAudit(); a != nil {
+		r.Audit = &audit.Receipt{Index: a.GetIndex(), Hash: a.GetHash()}
+	}
+	return r, nil
+}